@@ -2,113 +2,390 @@ package main
 
 import (
 	"context"
+	"crypto"
 	"database/sql"
 	"errors"
+	"expvar"
+	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"time"
 
+	"com.user.com/user/internal/apikey"
+	apikeystore "com.user.com/user/internal/apikey/store"
+	"com.user.com/user/internal/apikeyview"
+	"com.user.com/user/internal/auth"
+	authstore "com.user.com/user/internal/auth/store"
+	"com.user.com/user/internal/authview"
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/cursor"
+	"com.user.com/user/internal/mailer"
 	"com.user.com/user/internal/notifier"
+	"com.user.com/user/internal/password"
 	"com.user.com/user/internal/user"
 	"com.user.com/user/internal/user/store"
 	"com.user.com/user/internal/userview"
+	"com.user.com/user/internal/webhook"
+	webhookstore "com.user.com/user/internal/webhook/store"
+	"com.user.com/user/internal/webhookview"
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
-	"gocloud.dev/gcp"
-	"gocloud.dev/pubsub/gcppubsub"
+	"golang.org/x/crypto/bcrypt"
 
 	_ "github.com/lib/pq"
 )
 
 func main() {
-	// Create subscription to Pub/Sub topic in order to send notifications
-	enableSubscription := os.Getenv("ENABLE_GCP_SUBSCRIPTION")
-	var (
-		pubsubNotifier    *notifier.PubSubNotifier
-		shouldUseNotifier bool
-	)
-
-	if enableSubscription != "" && enableSubscription == "true" {
-		ctx := context.Background()
-		// Your GCP credentials.
-		// See https://cloud.google.com/docs/authentication/production
-		// for more info on alternatives.
-		creds, err := gcp.DefaultCredentials(ctx)
-		if err != nil {
-			panic(err)
-		}
-		// Open a gRPC connection to the GCP Pub/Sub API.
-		conn, cleanup, err := gcppubsub.Dial(ctx, creds.TokenSource)
-		if err != nil {
-			panic(err)
-		}
-		defer cleanup()
+	// "users migrate ..." manages db schema instead of starting the service
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
+	}
 
-		// Construct a PublisherClient using the connection.
-		pubClient, err := gcppubsub.PublisherClient(ctx, conn)
+	// Open Pub/Sub topic in order to send notifications. Broker is picked by the URL scheme, see notifier.OpenPubSubNotifier
+	var pubsubNotifier *notifier.PubSubNotifier
+	pubsubPolicy := notifier.PolicyRequired
+	if topicURL := userTopicURL(); topicURL != "" {
+		// Events are published as plain JSON unless USER_EVENT_FORMAT says otherwise
+		eventFormat, err := notifier.ParseFormat(os.Getenv("USER_EVENT_FORMAT"))
 		if err != nil {
 			panic(err)
 		}
-		defer pubClient.Close()
-
-		// Construct a *pubsub.Topic.
-		topic, err := gcppubsub.OpenTopicByPath(pubClient, "projects/myprojectID/topics/example-topic", nil)
+		// Failed publishing is retried unless USER_TOPIC_POLICY says otherwise
+		pubsubPolicy, err = notifier.ParsePolicy(os.Getenv("USER_TOPIC_POLICY"))
 		if err != nil {
 			panic(err)
 		}
-		defer topic.Shutdown(ctx)
-		topicURL := os.Getenv("GCP_USER_TOPIC_URL")
-		if topicURL == "" {
-			panic(errors.New("'GCP_USER_TOPIC_URL' must be provided"))
-		}
-		// Construct a *pubsub.Topic.
-		topic, err = gcppubsub.OpenTopicByPath(pubClient, topicURL, nil)
+		ctx := context.Background()
+		pubsubNotifier, err = notifier.OpenPubSubNotifier(ctx, topicURL, eventFormat)
 		if err != nil {
 			panic(err)
 		}
-		defer topic.Shutdown(ctx)
-		pubsubNotifier = notifier.NewPubSubNotifier(*topic)
-		shouldUseNotifier = true
+		defer pubsubNotifier.Shutdown(ctx)
 	}
 
-	db := openConnection("postgres", "postgresql://root@cockroachdb:26257/defaultdb?sslmode=disable", 16, 8, time.Second*time.Duration(300))
+	db := openConnection("postgres", dbConnectString(), 16, 8, time.Second*time.Duration(300))
+	// Bring db schema up to date. Replicas starting at the same time wait for each other.
+	migrateCtx, cancelMigrate := context.WithTimeout(context.Background(), migrateTimeout)
+	err := newMigrator(db).Up(migrateCtx)
+	cancelMigrate()
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to migrate db")
+	}
 	// Create instance of User store
-	userStore := store.NewStore(db)
-	userStore.InitDBTables(context.Background())
+	userStore := store.NewStore(db, newCursorCodec())
+
+	// Passwords are hashed with argon2id. Bcrypt hashes and plaintext passwords, stored before hashing has been
+	// introduced, are still accepted and get rehashed on login.
+	passwordHasher := password.NewHasher(
+		password.NewArgon2idHasher(password.DefaultArgon2idParams),
+		password.NewBcryptHasher(bcrypt.DefaultCost),
+		password.NewPlaintextHasher(),
+	)
+
+	// Mails of email verification and password reset
+	mailSender := newMailer()
+
+	// Emails are verified by one-time tokens, which are mailed to users
+	emailVerification := user.NewEmailVerification(mailSender, pageURL("EMAIL_VERIFICATION_URL"), verificationTTL())
 
 	// Create instance of User manager
-	userManager := user.NewManager(userStore, pubsubNotifier, shouldUseNotifier)
+	userManager := user.NewManager(userStore, passwordHasher, emailVerification)
+
+	// Logins are verified against users, sessions are kept as rotated refresh tokens. Passwords are reset by
+	// one-time tokens, which are mailed to users.
+	tokenIssuer := newTokenIssuer()
+	authManager := auth.NewManager(userManager, authstore.NewStore(db), tokenIssuer, mailSender, authConfig())
+	apiKeyManager := apikey.NewManager(apikeystore.NewStore(db))
+
+	// Webhooks receive events as persisted deliveries, which are sent in background
+	webhookStore := webhookstore.NewStore(db)
+	webhookManager := webhook.NewManager(webhookStore)
+
+	backgroundCtx, cancelBackground := context.WithCancel(context.Background())
+	defer cancelBackground()
+	go webhook.NewDispatcher(webhookStore, webhook.DefaultDispatcherConfig).Run(backgroundCtx)
+
+	// Events are written to the outbox along with users and published in background to all of the sinks.
+	// Webhook deliveries are persisted, hence they are required. Failed required sink makes the relay publish
+	// the event again.
+	sinks := []notifier.Sink{
+		{Name: "webhooks", Notifier: webhookManager, Policy: notifier.PolicyRequired, Timeout: 5 * time.Second},
+	}
+	if pubsubNotifier != nil {
+		sinks = append(sinks, notifier.Sink{Name: "pubsub", Notifier: pubsubNotifier, Policy: pubsubPolicy, Timeout: 10 * time.Second})
+	}
+	if os.Getenv("LOG_EVENTS") == "true" {
+		sinks = append(sinks, notifier.Sink{Name: "log", Notifier: notifier.NewLogNotifier(logrus.InfoLevel), Policy: notifier.PolicyAsync})
+	}
+	eventNotifier := notifier.NewComposite(sinks...)
+	defer eventNotifier.Shutdown(context.Background())
+	go user.NewRelay(userStore, eventNotifier, user.DefaultRelayConfig).Run(backgroundCtx)
 
 	// Create user endpoint
 	createUserEndpoint := userview.NewCreateUserEndpoint(userManager)
 	// Get All Users endpoint
 	getAllUsersEndpoint := userview.NewGetAllUsersEndpoint(userManager)
+	// Get single user endpoint
+	getUserEndpoint := userview.NewGetUserEndpoint(userManager)
 	//Modify user endpoint
 	modifyUserEndpoint := userview.NewUpdateUserEndpoint(userManager)
+	// Patch user endpoint
+	patchUserEndpoint := userview.NewPatchUserEndpoint(userManager)
 	// Delete user endpoint
 	deleteUserEndpoint := userview.NewDeleteUserEndpoint(userManager)
+	// Verify email endpoint
+	verifyEmailEndpoint := userview.NewVerifyEmailEndpoint(userManager)
+
+	// Auth endpoints
+	loginEndpoint := authview.NewLoginEndpoint(authManager)
+	refreshEndpoint := authview.NewRefreshEndpoint(authManager)
+	logoutEndpoint := authview.NewLogoutEndpoint(authManager)
+	requestPasswordResetEndpoint := authview.NewRequestPasswordResetEndpoint(authManager)
+	resetPasswordEndpoint := authview.NewResetPasswordEndpoint(authManager)
+
+	// API key endpoints
+	createAPIKeyEndpoint := apikeyview.NewCreateAPIKeyEndpoint(apiKeyManager)
+	getAllAPIKeysEndpoint := apikeyview.NewGetAllAPIKeysEndpoint(apiKeyManager)
+	rotateAPIKeyEndpoint := apikeyview.NewRotateAPIKeyEndpoint(apiKeyManager)
+	revokeAPIKeyEndpoint := apikeyview.NewRevokeAPIKeyEndpoint(apiKeyManager)
+
+	// Webhook endpoints
+	createWebhookEndpoint := webhookview.NewCreateWebhookEndpoint(webhookManager)
+	getAllWebhooksEndpoint := webhookview.NewGetAllWebhooksEndpoint(webhookManager)
+	getWebhookEndpoint := webhookview.NewGetWebhookEndpoint(webhookManager)
+	updateWebhookEndpoint := webhookview.NewUpdateWebhookEndpoint(webhookManager)
+	deleteWebhookEndpoint := webhookview.NewDeleteWebhookEndpoint(webhookManager)
+	getDeliveriesEndpoint := webhookview.NewGetDeliveriesEndpoint(webhookManager)
 
 	// Create router and bind user handlers
 	router := mux.NewRouter()
-	router.HandleFunc("/api/public/v1/users", createUserEndpoint.ServeHTTP).Methods(http.MethodPost)
-	router.HandleFunc("/api/public/v1/users", getAllUsersEndpoint.ServeHTTP).Methods(http.MethodGet)
-	router.HandleFunc("/api/public/v1/users/{userID}", deleteUserEndpoint.ServeHTTP).Methods(http.MethodDelete)
-	router.HandleFunc("/api/public/v1/users/{userID}", modifyUserEndpoint.ServeHTTP).Methods(http.MethodPut)
+	// Metrics, including the ones of notifier sinks, expose command line and memory stats, hence admins only
+	router.Handle("/debug/vars", authview.RequireRole(core.RoleAdmin)(expvar.Handler())).Methods(http.MethodGet)
+	router.HandleFunc("/api/public/v1/auth/login", loginEndpoint.ServeHTTP).Methods(http.MethodPost)
+	router.HandleFunc("/api/public/v1/auth/refresh", refreshEndpoint.ServeHTTP).Methods(http.MethodPost)
+	router.HandleFunc("/api/public/v1/auth/logout", logoutEndpoint.ServeHTTP).Methods(http.MethodPost)
+	router.HandleFunc("/api/public/v1/auth/password-reset/request", requestPasswordResetEndpoint.ServeHTTP).Methods(http.MethodPost)
+	router.HandleFunc("/api/public/v1/auth/password-reset/confirm", resetPasswordEndpoint.ServeHTTP).Methods(http.MethodPost)
+	// Services calling by API keys need the scope of the route, users are authorized by business logic
+	readUsers := authview.RequireScope(core.ScopeUsersRead)
+	writeUsers := authview.RequireScope(core.ScopeUsersWrite)
+	deleteUsers := authview.RequireScope(core.ScopeUsersDelete)
+	router.Handle("/api/public/v1/users", writeUsers(createUserEndpoint)).Methods(http.MethodPost)
+	router.Handle("/api/public/v1/users", readUsers(getAllUsersEndpoint)).Methods(http.MethodGet)
+	router.Handle("/api/public/v1/users/{userID}", readUsers(getUserEndpoint)).Methods(http.MethodGet)
+	router.Handle("/api/public/v1/users/{userID}", deleteUsers(deleteUserEndpoint)).Methods(http.MethodDelete)
+	router.Handle("/api/public/v1/users/{userID}", writeUsers(modifyUserEndpoint)).Methods(http.MethodPut)
+	router.Handle("/api/public/v1/users/{userID}", writeUsers(patchUserEndpoint)).Methods(http.MethodPatch)
+	router.Handle("/api/public/v1/users/{userID}/verify-email", writeUsers(verifyEmailEndpoint)).Methods(http.MethodPost)
+	// API keys grant access to users of everyone, hence they are managed by admins only
+	apiKeyRouter := router.PathPrefix("/api/public/v1/api-keys").Subrouter()
+	apiKeyRouter.Use(authview.RequireRole(core.RoleAdmin))
+	apiKeyRouter.HandleFunc("", createAPIKeyEndpoint.ServeHTTP).Methods(http.MethodPost)
+	apiKeyRouter.HandleFunc("", getAllAPIKeysEndpoint.ServeHTTP).Methods(http.MethodGet)
+	apiKeyRouter.HandleFunc("/{keyID}/rotate", rotateAPIKeyEndpoint.ServeHTTP).Methods(http.MethodPost)
+	apiKeyRouter.HandleFunc("/{keyID}", revokeAPIKeyEndpoint.ServeHTTP).Methods(http.MethodDelete)
+	// Webhooks get events of all users, hence they are managed by admins only
+	webhookRouter := router.PathPrefix("/api/public/v1/webhooks").Subrouter()
+	webhookRouter.Use(authview.RequireRole(core.RoleAdmin))
+	webhookRouter.HandleFunc("", createWebhookEndpoint.ServeHTTP).Methods(http.MethodPost)
+	webhookRouter.HandleFunc("", getAllWebhooksEndpoint.ServeHTTP).Methods(http.MethodGet)
+	webhookRouter.HandleFunc("/{webhookID}", getWebhookEndpoint.ServeHTTP).Methods(http.MethodGet)
+	webhookRouter.HandleFunc("/{webhookID}", updateWebhookEndpoint.ServeHTTP).Methods(http.MethodPut)
+	webhookRouter.HandleFunc("/{webhookID}", deleteWebhookEndpoint.ServeHTTP).Methods(http.MethodDelete)
+	webhookRouter.HandleFunc("/{webhookID}/deliveries", getDeliveriesEndpoint.ServeHTTP).Methods(http.MethodGet)
+	// Bearer tokens and API keys identify actors of all requests, requests without them are anonymous
+	router.Use(authview.NewAuthMiddleware(newTokenVerifier(tokenIssuer, userManager), apiKeyManager))
 
 	logrus.Info("starting web server")
-	err := http.ListenAndServe(":8080", router)
+	err = http.ListenAndServe(":8080", router)
 	if err != nil {
 		logrus.WithError(err).Error("ListenAndServe exited with error")
 	}
 
 }
 
+// userTopicURL - gocloud URL of the topic user events are published to, empty one disables notifications.
+// ENABLE_GCP_SUBSCRIPTION along with GCP_USER_TOPIC_URL holding the topic path is still supported, but deprecated.
+func userTopicURL() string {
+	if topicURL := os.Getenv("USER_TOPIC_URL"); topicURL != "" {
+		return topicURL
+	}
+	if os.Getenv("ENABLE_GCP_SUBSCRIPTION") != "true" {
+		return ""
+	}
+	topicPath := os.Getenv("GCP_USER_TOPIC_URL")
+	if topicPath == "" {
+		panic(errors.New("'GCP_USER_TOPIC_URL' must be provided"))
+	}
+	return "gcppubsub://" + topicPath
+}
+
+// newCursorCodec - pagination cursors are signed by CURSOR_SECRET, which has to be shared by all of the replicas.
+// They expire after CURSOR_TTL (24h by default).
+func newCursorCodec() *cursor.Codec {
+	ttl := cursor.DefaultTTL
+	if rawTTL := os.Getenv("CURSOR_TTL"); rawTTL != "" {
+		var err error
+		ttl, err = time.ParseDuration(rawTTL)
+		if err != nil {
+			panic(fmt.Errorf("'CURSOR_TTL' is invalid: %w", err))
+		}
+	}
+	key := []byte(os.Getenv("CURSOR_SECRET"))
+	if len(key) == 0 {
+		logrus.Warn("'CURSOR_SECRET' is not set, cursors are signed by a random key and they do not survive restarts")
+		var err error
+		key, err = cursor.GenerateKey()
+		if err != nil {
+			panic(err)
+		}
+	}
+	return cursor.NewCodec(key, ttl)
+}
+
+// verificationTTL - email verification tokens expire after EMAIL_VERIFICATION_TTL (48h by default)
+func verificationTTL() time.Duration {
+	rawTTL := os.Getenv("EMAIL_VERIFICATION_TTL")
+	if rawTTL == "" {
+		return user.DefaultVerificationTTL
+	}
+	ttl, err := time.ParseDuration(rawTTL)
+	if err != nil {
+		panic(fmt.Errorf("'EMAIL_VERIFICATION_TTL' is invalid: %w", err))
+	}
+	return ttl
+}
+
+// pageURL - page users open from mails, e.g. EMAIL_VERIFICATION_URL to verify their emails or PASSWORD_RESET_URL
+// to reset their passwords. Without it mails carry bare tokens.
+func pageURL(env string) *url.URL {
+	rawURL := os.Getenv(env)
+	if rawURL == "" {
+		return nil
+	}
+	page, err := url.Parse(rawURL)
+	if err != nil || !page.IsAbs() {
+		panic(fmt.Errorf("'%s' must be an absolute URL, got %q", env, rawURL))
+	}
+	return page
+}
+
+// newMailer - mails are relayed through SMTP_ADDR (host:port) from SMTP_FROM, SMTP_USERNAME and SMTP_PASSWORD
+// authenticate the service. Without SMTP_ADDR mails are only logged.
+func newMailer() mailer.Mailer {
+	addr := os.Getenv("SMTP_ADDR")
+	if addr == "" {
+		logrus.Warn("'SMTP_ADDR' is not set, mails are logged instead of being sent")
+		return mailer.NewLogMailer()
+	}
+	smtpMailer, err := mailer.NewSMTPMailer(mailer.SMTPConfig{
+		Addr:     addr,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("SMTP_FROM"),
+	})
+	if err != nil {
+		panic(err)
+	}
+	return smtpMailer
+}
+
+// newTokenIssuer - access tokens are signed by the private key in JWT_SIGNING_KEY_FILE (PEM), which has to be shared
+// by all of the replicas
+func newTokenIssuer() *auth.Issuer {
+	var (
+		key crypto.Signer
+		err error
+	)
+	if keyFile := os.Getenv("JWT_SIGNING_KEY_FILE"); keyFile != "" {
+		key, err = auth.LoadSigningKey(keyFile)
+	} else {
+		logrus.Warn("'JWT_SIGNING_KEY_FILE' is not set, tokens are signed by a random key and they do not survive restarts")
+		key, err = auth.GenerateSigningKey()
+	}
+	if err != nil {
+		panic(err)
+	}
+	issuer, err := auth.NewIssuer(key, jwtIssuer(), jwtAudience())
+	if err != nil {
+		panic(err)
+	}
+	return issuer
+}
+
+// newTokenVerifier - access tokens are verified by the key of the issuer along with keys of JWT_JWKS_FILE (JSON Web
+// Key Set) or the static key of JWT_PUBLIC_KEY_FILE (PEM), so tokens issued elsewhere, by JWT_EXTERNAL_ISSUER, are
+// accepted as well. Roles of their users are looked up.
+func newTokenVerifier(issuer *auth.Issuer, users auth.UserFinder) *auth.Verifier {
+	var (
+		externalKeys auth.KeySet
+		err          error
+	)
+	if jwksFile := os.Getenv("JWT_JWKS_FILE"); jwksFile != "" {
+		externalKeys, err = auth.LoadJWKS(jwksFile)
+	} else if publicKeyFile := os.Getenv("JWT_PUBLIC_KEY_FILE"); publicKeyFile != "" {
+		externalKeys, err = auth.LoadPublicKey(publicKeyFile)
+	}
+	if err != nil {
+		panic(err)
+	}
+	externalIssuer := os.Getenv("JWT_EXTERNAL_ISSUER")
+	if externalIssuer == "" {
+		externalIssuer = jwtIssuer()
+	}
+	return auth.NewVerifier(issuer.KeySet(), users, auth.VerifierConfig{
+		Issuer:         jwtIssuer(),
+		Audience:       jwtAudience(),
+		ExternalIssuer: externalIssuer,
+		ExternalKeys:   externalKeys,
+	})
+}
+
+// jwtIssuer - iss claim of access tokens, "users" unless JWT_ISSUER says otherwise
+func jwtIssuer() string {
+	if name := os.Getenv("JWT_ISSUER"); name != "" {
+		return name
+	}
+	return "users"
+}
+
+// jwtAudience - aud claim of access tokens, "users" unless JWT_AUDIENCE says otherwise
+func jwtAudience() string {
+	if audience := os.Getenv("JWT_AUDIENCE"); audience != "" {
+		return audience
+	}
+	return "users"
+}
+
+// authConfig - lifetime of tokens is overridden by ACCESS_TOKEN_TTL, REFRESH_TOKEN_TTL and PASSWORD_RESET_TTL
+func authConfig() auth.Config {
+	config := auth.DefaultConfig
+	config.PasswordResetPage = pageURL("PASSWORD_RESET_URL")
+	for env, ttl := range map[string]*time.Duration{
+		"ACCESS_TOKEN_TTL":   &config.AccessTokenTTL,
+		"REFRESH_TOKEN_TTL":  &config.RefreshTokenTTL,
+		"PASSWORD_RESET_TTL": &config.PasswordResetTTL,
+	} {
+		rawTTL := os.Getenv(env)
+		if rawTTL == "" {
+			continue
+		}
+		var err error
+		*ttl, err = time.ParseDuration(rawTTL)
+		if err != nil {
+			panic(fmt.Errorf("'%s' is invalid: %w", env, err))
+		}
+	}
+	return config
+}
+
 func openConnection(
 	driverName,
 	connectionStr string,
 	maxOpenDBConnections, maxIdleDBConnections int,
 	maxLifetimeDBConnections time.Duration,
-) sql.DB {
+) *sql.DB {
 
 	db, err := sql.Open(driverName, connectionStr)
 	if err != nil {
@@ -122,28 +399,5 @@ func openConnection(
 	db.SetMaxIdleConns(maxIdleDBConnections)
 	db.SetConnMaxLifetime(maxLifetimeDBConnections)
 
-	return *db
+	return db
 }
-
-// func createPublisherClient(ctx context.Context) pubsub.PublisherClient {
-// 	// Your GCP credentials.
-// 	// See https://cloud.google.com/docs/authentication/production
-// 	// for more info on alternatives.
-// 	creds, err := gcp.DefaultCredentials(ctx)
-// 	if err != nil {
-// 		return err
-// 	}
-// 	// Open a gRPC connection to the GCP Pub/Sub API.
-// 	conn, cleanup, err := gcppubsub.Dial(ctx, creds.TokenSource)
-// 	if err != nil {
-// 		return err
-// 	}
-// 	defer cleanup()
-
-// 	// Construct a PublisherClient using the connection.
-// 	pubClient, err := gcppubsub.PublisherClient(ctx, conn)
-// 	if err != nil {
-// 		return err
-// 	}
-// 	return pubClient
-// }
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"com.user.com/user/internal/migrate"
+	"com.user.com/user/migrations"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultDBConnectString = "postgresql://root@cockroachdb:26257/defaultdb?sslmode=disable"
+	// migrateTimeout - includes waiting for the migration lock. Holding the lock is limited by migrate package too.
+	migrateTimeout = 5 * time.Minute
+	migrateUsage   = "usage: users migrate up|down|status|to <version>"
+)
+
+func dbConnectString() string {
+	if connectString := os.Getenv("DB_CONNECT_STRING"); connectString != "" {
+		return connectString
+	}
+	return defaultDBConnectString
+}
+
+func newMigrator(db *sql.DB) *migrate.Migrator {
+	ms, err := migrate.Load(migrations.FS)
+	if err != nil {
+		// Embedded migrations are broken, nothing to recover from
+		panic(err)
+	}
+	return migrate.NewMigrator(db, ms)
+}
+
+// runMigrate - executes migrate subcommand and returns process exit code
+func runMigrate(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	db := openConnection("postgres", dbConnectString(), 2, 1, time.Minute)
+	defer db.Close()
+	migrator := newMigrator(db)
+	ctx, cancel := context.WithTimeout(context.Background(), migrateTimeout)
+	defer cancel()
+
+	var err error
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		err = migrator.Up(ctx)
+	case args[0] == "down" && len(args) == 1:
+		err = migrator.Down(ctx)
+	case args[0] == "status" && len(args) == 1:
+		err = printMigrationStatus(ctx, migrator)
+	case args[0] == "to" && len(args) == 2:
+		version, convErr := strconv.Atoi(args[1])
+		if convErr != nil {
+			fmt.Fprintln(os.Stderr, migrateUsage)
+			return 2
+		}
+		err = migrator.To(ctx, version)
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+	if err != nil {
+		logrus.WithError(err).Error("migrate: command failed")
+		return 1
+	}
+	return 0
+}
+
+func printMigrationStatus(ctx context.Context, migrator *migrate.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, s := range statuses {
+		appliedAt := "pending"
+		if s.Applied() {
+			appliedAt = s.AppliedAt.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+	}
+	return w.Flush()
+}
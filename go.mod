@@ -1,14 +1,16 @@
 module com.user.com/user
 
-go 1.15
+go 1.16
 
 require (
 	contrib.go.opencensus.io/resource v0.1.1 // indirect
 	github.com/Azure/azure-amqp-common-go/v2 v2.1.0 // indirect
 	github.com/cockroachdb/cockroach-go/v2 v2.2.5 // indirect
+	github.com/evanphx/json-patch v4.12.0+incompatible
 	github.com/fatih/color v1.7.0 // indirect
 	github.com/go-playground/universal-translator v0.18.0 // indirect
 	github.com/go-playground/validator v9.31.0+incompatible
+	github.com/golang-jwt/jwt/v4 v4.5.2
 	github.com/golang-migrate/migrate v3.5.4+incompatible // indirect
 	github.com/google/uuid v1.3.0
 	github.com/googleapis/gax-go v2.0.2+incompatible // indirect
@@ -21,4 +23,8 @@ require (
 	github.com/onsi/gomega v1.11.0
 	github.com/sirupsen/logrus v1.8.1
 	gocloud.dev v0.24.0
+	gocloud.dev/pubsub/kafkapubsub v0.24.0
+	gocloud.dev/pubsub/natspubsub v0.24.0
+	gocloud.dev/pubsub/rabbitpubsub v0.24.0
+	golang.org/x/crypto v0.0.0-20210817164053-32db794688a5
 )
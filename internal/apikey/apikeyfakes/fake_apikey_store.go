@@ -0,0 +1,519 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package apikeyfakes
+
+import (
+	"context"
+	"sync"
+
+	"com.user.com/user/internal/apikey"
+	"com.user.com/user/internal/core"
+	"github.com/google/uuid"
+)
+
+type FakeAPIKeyStore struct {
+	GetAPIKeyByHashStub        func(context.Context, string) (core.APIKey, error)
+	getAPIKeyByHashMutex       sync.RWMutex
+	getAPIKeyByHashArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	getAPIKeyByHashReturns struct {
+		result1 core.APIKey
+		result2 error
+	}
+	getAPIKeyByHashReturnsOnCall map[int]struct {
+		result1 core.APIKey
+		result2 error
+	}
+	GetAPIKeyByIDStub        func(context.Context, uuid.UUID) (core.APIKey, error)
+	getAPIKeyByIDMutex       sync.RWMutex
+	getAPIKeyByIDArgsForCall []struct {
+		arg1 context.Context
+		arg2 uuid.UUID
+	}
+	getAPIKeyByIDReturns struct {
+		result1 core.APIKey
+		result2 error
+	}
+	getAPIKeyByIDReturnsOnCall map[int]struct {
+		result1 core.APIKey
+		result2 error
+	}
+	GetAllAPIKeysStub        func(context.Context) ([]core.APIKey, error)
+	getAllAPIKeysMutex       sync.RWMutex
+	getAllAPIKeysArgsForCall []struct {
+		arg1 context.Context
+	}
+	getAllAPIKeysReturns struct {
+		result1 []core.APIKey
+		result2 error
+	}
+	getAllAPIKeysReturnsOnCall map[int]struct {
+		result1 []core.APIKey
+		result2 error
+	}
+	RevokeAPIKeyStub        func(context.Context, uuid.UUID) error
+	revokeAPIKeyMutex       sync.RWMutex
+	revokeAPIKeyArgsForCall []struct {
+		arg1 context.Context
+		arg2 uuid.UUID
+	}
+	revokeAPIKeyReturns struct {
+		result1 error
+	}
+	revokeAPIKeyReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SaveAPIKeyStub        func(context.Context, core.APIKey) (core.APIKey, error)
+	saveAPIKeyMutex       sync.RWMutex
+	saveAPIKeyArgsForCall []struct {
+		arg1 context.Context
+		arg2 core.APIKey
+	}
+	saveAPIKeyReturns struct {
+		result1 core.APIKey
+		result2 error
+	}
+	saveAPIKeyReturnsOnCall map[int]struct {
+		result1 core.APIKey
+		result2 error
+	}
+	UpdateAPIKeyHashStub        func(context.Context, core.APIKey) (core.APIKey, error)
+	updateAPIKeyHashMutex       sync.RWMutex
+	updateAPIKeyHashArgsForCall []struct {
+		arg1 context.Context
+		arg2 core.APIKey
+	}
+	updateAPIKeyHashReturns struct {
+		result1 core.APIKey
+		result2 error
+	}
+	updateAPIKeyHashReturnsOnCall map[int]struct {
+		result1 core.APIKey
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeAPIKeyStore) GetAPIKeyByHash(arg1 context.Context, arg2 string) (core.APIKey, error) {
+	fake.getAPIKeyByHashMutex.Lock()
+	ret, specificReturn := fake.getAPIKeyByHashReturnsOnCall[len(fake.getAPIKeyByHashArgsForCall)]
+	fake.getAPIKeyByHashArgsForCall = append(fake.getAPIKeyByHashArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetAPIKeyByHashStub
+	fakeReturns := fake.getAPIKeyByHashReturns
+	fake.recordInvocation("GetAPIKeyByHash", []interface{}{arg1, arg2})
+	fake.getAPIKeyByHashMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeAPIKeyStore) GetAPIKeyByHashCallCount() int {
+	fake.getAPIKeyByHashMutex.RLock()
+	defer fake.getAPIKeyByHashMutex.RUnlock()
+	return len(fake.getAPIKeyByHashArgsForCall)
+}
+
+func (fake *FakeAPIKeyStore) GetAPIKeyByHashCalls(stub func(context.Context, string) (core.APIKey, error)) {
+	fake.getAPIKeyByHashMutex.Lock()
+	defer fake.getAPIKeyByHashMutex.Unlock()
+	fake.GetAPIKeyByHashStub = stub
+}
+
+func (fake *FakeAPIKeyStore) GetAPIKeyByHashArgsForCall(i int) (context.Context, string) {
+	fake.getAPIKeyByHashMutex.RLock()
+	defer fake.getAPIKeyByHashMutex.RUnlock()
+	argsForCall := fake.getAPIKeyByHashArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeAPIKeyStore) GetAPIKeyByHashReturns(result1 core.APIKey, result2 error) {
+	fake.getAPIKeyByHashMutex.Lock()
+	defer fake.getAPIKeyByHashMutex.Unlock()
+	fake.GetAPIKeyByHashStub = nil
+	fake.getAPIKeyByHashReturns = struct {
+		result1 core.APIKey
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPIKeyStore) GetAPIKeyByHashReturnsOnCall(i int, result1 core.APIKey, result2 error) {
+	fake.getAPIKeyByHashMutex.Lock()
+	defer fake.getAPIKeyByHashMutex.Unlock()
+	fake.GetAPIKeyByHashStub = nil
+	if fake.getAPIKeyByHashReturnsOnCall == nil {
+		fake.getAPIKeyByHashReturnsOnCall = make(map[int]struct {
+			result1 core.APIKey
+			result2 error
+		})
+	}
+	fake.getAPIKeyByHashReturnsOnCall[i] = struct {
+		result1 core.APIKey
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPIKeyStore) GetAPIKeyByID(arg1 context.Context, arg2 uuid.UUID) (core.APIKey, error) {
+	fake.getAPIKeyByIDMutex.Lock()
+	ret, specificReturn := fake.getAPIKeyByIDReturnsOnCall[len(fake.getAPIKeyByIDArgsForCall)]
+	fake.getAPIKeyByIDArgsForCall = append(fake.getAPIKeyByIDArgsForCall, struct {
+		arg1 context.Context
+		arg2 uuid.UUID
+	}{arg1, arg2})
+	stub := fake.GetAPIKeyByIDStub
+	fakeReturns := fake.getAPIKeyByIDReturns
+	fake.recordInvocation("GetAPIKeyByID", []interface{}{arg1, arg2})
+	fake.getAPIKeyByIDMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeAPIKeyStore) GetAPIKeyByIDCallCount() int {
+	fake.getAPIKeyByIDMutex.RLock()
+	defer fake.getAPIKeyByIDMutex.RUnlock()
+	return len(fake.getAPIKeyByIDArgsForCall)
+}
+
+func (fake *FakeAPIKeyStore) GetAPIKeyByIDCalls(stub func(context.Context, uuid.UUID) (core.APIKey, error)) {
+	fake.getAPIKeyByIDMutex.Lock()
+	defer fake.getAPIKeyByIDMutex.Unlock()
+	fake.GetAPIKeyByIDStub = stub
+}
+
+func (fake *FakeAPIKeyStore) GetAPIKeyByIDArgsForCall(i int) (context.Context, uuid.UUID) {
+	fake.getAPIKeyByIDMutex.RLock()
+	defer fake.getAPIKeyByIDMutex.RUnlock()
+	argsForCall := fake.getAPIKeyByIDArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeAPIKeyStore) GetAPIKeyByIDReturns(result1 core.APIKey, result2 error) {
+	fake.getAPIKeyByIDMutex.Lock()
+	defer fake.getAPIKeyByIDMutex.Unlock()
+	fake.GetAPIKeyByIDStub = nil
+	fake.getAPIKeyByIDReturns = struct {
+		result1 core.APIKey
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPIKeyStore) GetAPIKeyByIDReturnsOnCall(i int, result1 core.APIKey, result2 error) {
+	fake.getAPIKeyByIDMutex.Lock()
+	defer fake.getAPIKeyByIDMutex.Unlock()
+	fake.GetAPIKeyByIDStub = nil
+	if fake.getAPIKeyByIDReturnsOnCall == nil {
+		fake.getAPIKeyByIDReturnsOnCall = make(map[int]struct {
+			result1 core.APIKey
+			result2 error
+		})
+	}
+	fake.getAPIKeyByIDReturnsOnCall[i] = struct {
+		result1 core.APIKey
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPIKeyStore) GetAllAPIKeys(arg1 context.Context) ([]core.APIKey, error) {
+	fake.getAllAPIKeysMutex.Lock()
+	ret, specificReturn := fake.getAllAPIKeysReturnsOnCall[len(fake.getAllAPIKeysArgsForCall)]
+	fake.getAllAPIKeysArgsForCall = append(fake.getAllAPIKeysArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.GetAllAPIKeysStub
+	fakeReturns := fake.getAllAPIKeysReturns
+	fake.recordInvocation("GetAllAPIKeys", []interface{}{arg1})
+	fake.getAllAPIKeysMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeAPIKeyStore) GetAllAPIKeysCallCount() int {
+	fake.getAllAPIKeysMutex.RLock()
+	defer fake.getAllAPIKeysMutex.RUnlock()
+	return len(fake.getAllAPIKeysArgsForCall)
+}
+
+func (fake *FakeAPIKeyStore) GetAllAPIKeysCalls(stub func(context.Context) ([]core.APIKey, error)) {
+	fake.getAllAPIKeysMutex.Lock()
+	defer fake.getAllAPIKeysMutex.Unlock()
+	fake.GetAllAPIKeysStub = stub
+}
+
+func (fake *FakeAPIKeyStore) GetAllAPIKeysArgsForCall(i int) context.Context {
+	fake.getAllAPIKeysMutex.RLock()
+	defer fake.getAllAPIKeysMutex.RUnlock()
+	argsForCall := fake.getAllAPIKeysArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeAPIKeyStore) GetAllAPIKeysReturns(result1 []core.APIKey, result2 error) {
+	fake.getAllAPIKeysMutex.Lock()
+	defer fake.getAllAPIKeysMutex.Unlock()
+	fake.GetAllAPIKeysStub = nil
+	fake.getAllAPIKeysReturns = struct {
+		result1 []core.APIKey
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPIKeyStore) GetAllAPIKeysReturnsOnCall(i int, result1 []core.APIKey, result2 error) {
+	fake.getAllAPIKeysMutex.Lock()
+	defer fake.getAllAPIKeysMutex.Unlock()
+	fake.GetAllAPIKeysStub = nil
+	if fake.getAllAPIKeysReturnsOnCall == nil {
+		fake.getAllAPIKeysReturnsOnCall = make(map[int]struct {
+			result1 []core.APIKey
+			result2 error
+		})
+	}
+	fake.getAllAPIKeysReturnsOnCall[i] = struct {
+		result1 []core.APIKey
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPIKeyStore) RevokeAPIKey(arg1 context.Context, arg2 uuid.UUID) error {
+	fake.revokeAPIKeyMutex.Lock()
+	ret, specificReturn := fake.revokeAPIKeyReturnsOnCall[len(fake.revokeAPIKeyArgsForCall)]
+	fake.revokeAPIKeyArgsForCall = append(fake.revokeAPIKeyArgsForCall, struct {
+		arg1 context.Context
+		arg2 uuid.UUID
+	}{arg1, arg2})
+	stub := fake.RevokeAPIKeyStub
+	fakeReturns := fake.revokeAPIKeyReturns
+	fake.recordInvocation("RevokeAPIKey", []interface{}{arg1, arg2})
+	fake.revokeAPIKeyMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeAPIKeyStore) RevokeAPIKeyCallCount() int {
+	fake.revokeAPIKeyMutex.RLock()
+	defer fake.revokeAPIKeyMutex.RUnlock()
+	return len(fake.revokeAPIKeyArgsForCall)
+}
+
+func (fake *FakeAPIKeyStore) RevokeAPIKeyCalls(stub func(context.Context, uuid.UUID) error) {
+	fake.revokeAPIKeyMutex.Lock()
+	defer fake.revokeAPIKeyMutex.Unlock()
+	fake.RevokeAPIKeyStub = stub
+}
+
+func (fake *FakeAPIKeyStore) RevokeAPIKeyArgsForCall(i int) (context.Context, uuid.UUID) {
+	fake.revokeAPIKeyMutex.RLock()
+	defer fake.revokeAPIKeyMutex.RUnlock()
+	argsForCall := fake.revokeAPIKeyArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeAPIKeyStore) RevokeAPIKeyReturns(result1 error) {
+	fake.revokeAPIKeyMutex.Lock()
+	defer fake.revokeAPIKeyMutex.Unlock()
+	fake.RevokeAPIKeyStub = nil
+	fake.revokeAPIKeyReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAPIKeyStore) RevokeAPIKeyReturnsOnCall(i int, result1 error) {
+	fake.revokeAPIKeyMutex.Lock()
+	defer fake.revokeAPIKeyMutex.Unlock()
+	fake.RevokeAPIKeyStub = nil
+	if fake.revokeAPIKeyReturnsOnCall == nil {
+		fake.revokeAPIKeyReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.revokeAPIKeyReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAPIKeyStore) SaveAPIKey(arg1 context.Context, arg2 core.APIKey) (core.APIKey, error) {
+	fake.saveAPIKeyMutex.Lock()
+	ret, specificReturn := fake.saveAPIKeyReturnsOnCall[len(fake.saveAPIKeyArgsForCall)]
+	fake.saveAPIKeyArgsForCall = append(fake.saveAPIKeyArgsForCall, struct {
+		arg1 context.Context
+		arg2 core.APIKey
+	}{arg1, arg2})
+	stub := fake.SaveAPIKeyStub
+	fakeReturns := fake.saveAPIKeyReturns
+	fake.recordInvocation("SaveAPIKey", []interface{}{arg1, arg2})
+	fake.saveAPIKeyMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeAPIKeyStore) SaveAPIKeyCallCount() int {
+	fake.saveAPIKeyMutex.RLock()
+	defer fake.saveAPIKeyMutex.RUnlock()
+	return len(fake.saveAPIKeyArgsForCall)
+}
+
+func (fake *FakeAPIKeyStore) SaveAPIKeyCalls(stub func(context.Context, core.APIKey) (core.APIKey, error)) {
+	fake.saveAPIKeyMutex.Lock()
+	defer fake.saveAPIKeyMutex.Unlock()
+	fake.SaveAPIKeyStub = stub
+}
+
+func (fake *FakeAPIKeyStore) SaveAPIKeyArgsForCall(i int) (context.Context, core.APIKey) {
+	fake.saveAPIKeyMutex.RLock()
+	defer fake.saveAPIKeyMutex.RUnlock()
+	argsForCall := fake.saveAPIKeyArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeAPIKeyStore) SaveAPIKeyReturns(result1 core.APIKey, result2 error) {
+	fake.saveAPIKeyMutex.Lock()
+	defer fake.saveAPIKeyMutex.Unlock()
+	fake.SaveAPIKeyStub = nil
+	fake.saveAPIKeyReturns = struct {
+		result1 core.APIKey
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPIKeyStore) SaveAPIKeyReturnsOnCall(i int, result1 core.APIKey, result2 error) {
+	fake.saveAPIKeyMutex.Lock()
+	defer fake.saveAPIKeyMutex.Unlock()
+	fake.SaveAPIKeyStub = nil
+	if fake.saveAPIKeyReturnsOnCall == nil {
+		fake.saveAPIKeyReturnsOnCall = make(map[int]struct {
+			result1 core.APIKey
+			result2 error
+		})
+	}
+	fake.saveAPIKeyReturnsOnCall[i] = struct {
+		result1 core.APIKey
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPIKeyStore) UpdateAPIKeyHash(arg1 context.Context, arg2 core.APIKey) (core.APIKey, error) {
+	fake.updateAPIKeyHashMutex.Lock()
+	ret, specificReturn := fake.updateAPIKeyHashReturnsOnCall[len(fake.updateAPIKeyHashArgsForCall)]
+	fake.updateAPIKeyHashArgsForCall = append(fake.updateAPIKeyHashArgsForCall, struct {
+		arg1 context.Context
+		arg2 core.APIKey
+	}{arg1, arg2})
+	stub := fake.UpdateAPIKeyHashStub
+	fakeReturns := fake.updateAPIKeyHashReturns
+	fake.recordInvocation("UpdateAPIKeyHash", []interface{}{arg1, arg2})
+	fake.updateAPIKeyHashMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeAPIKeyStore) UpdateAPIKeyHashCallCount() int {
+	fake.updateAPIKeyHashMutex.RLock()
+	defer fake.updateAPIKeyHashMutex.RUnlock()
+	return len(fake.updateAPIKeyHashArgsForCall)
+}
+
+func (fake *FakeAPIKeyStore) UpdateAPIKeyHashCalls(stub func(context.Context, core.APIKey) (core.APIKey, error)) {
+	fake.updateAPIKeyHashMutex.Lock()
+	defer fake.updateAPIKeyHashMutex.Unlock()
+	fake.UpdateAPIKeyHashStub = stub
+}
+
+func (fake *FakeAPIKeyStore) UpdateAPIKeyHashArgsForCall(i int) (context.Context, core.APIKey) {
+	fake.updateAPIKeyHashMutex.RLock()
+	defer fake.updateAPIKeyHashMutex.RUnlock()
+	argsForCall := fake.updateAPIKeyHashArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeAPIKeyStore) UpdateAPIKeyHashReturns(result1 core.APIKey, result2 error) {
+	fake.updateAPIKeyHashMutex.Lock()
+	defer fake.updateAPIKeyHashMutex.Unlock()
+	fake.UpdateAPIKeyHashStub = nil
+	fake.updateAPIKeyHashReturns = struct {
+		result1 core.APIKey
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPIKeyStore) UpdateAPIKeyHashReturnsOnCall(i int, result1 core.APIKey, result2 error) {
+	fake.updateAPIKeyHashMutex.Lock()
+	defer fake.updateAPIKeyHashMutex.Unlock()
+	fake.UpdateAPIKeyHashStub = nil
+	if fake.updateAPIKeyHashReturnsOnCall == nil {
+		fake.updateAPIKeyHashReturnsOnCall = make(map[int]struct {
+			result1 core.APIKey
+			result2 error
+		})
+	}
+	fake.updateAPIKeyHashReturnsOnCall[i] = struct {
+		result1 core.APIKey
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPIKeyStore) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.getAPIKeyByHashMutex.RLock()
+	defer fake.getAPIKeyByHashMutex.RUnlock()
+	fake.getAPIKeyByIDMutex.RLock()
+	defer fake.getAPIKeyByIDMutex.RUnlock()
+	fake.getAllAPIKeysMutex.RLock()
+	defer fake.getAllAPIKeysMutex.RUnlock()
+	fake.revokeAPIKeyMutex.RLock()
+	defer fake.revokeAPIKeyMutex.RUnlock()
+	fake.saveAPIKeyMutex.RLock()
+	defer fake.saveAPIKeyMutex.RUnlock()
+	fake.updateAPIKeyHashMutex.RLock()
+	defer fake.updateAPIKeyHashMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeAPIKeyStore) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ apikey.APIKeyStore = new(FakeAPIKeyStore)
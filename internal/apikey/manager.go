@@ -0,0 +1,166 @@
+// Package apikey - API keys of services calling the API. Keys are opaque, only their hashes are stored.
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"github.com/google/uuid"
+)
+
+//go:generate ~/go/bin/counterfeiter  . APIKeyStore
+
+type APIKeyStore interface {
+	SaveAPIKey(ctx context.Context, key core.APIKey) (core.APIKey, error)
+	// GetAPIKeyByID - returns core.NotFoundError if there is no such key
+	GetAPIKeyByID(ctx context.Context, id uuid.UUID) (core.APIKey, error)
+	// GetAPIKeyByHash - returns core.NotFoundError if there is no key with such hash
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (core.APIKey, error)
+	GetAllAPIKeys(ctx context.Context) ([]core.APIKey, error)
+	// UpdateAPIKeyHash - replaces prefix and hash of the key. Returns core.NotFoundError if there is no such
+	// active key.
+	UpdateAPIKeyHash(ctx context.Context, key core.APIKey) (core.APIKey, error)
+	// RevokeAPIKey - returns core.NotFoundError if there is no such key, revoking a revoked key is a no-op
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) error
+}
+
+const (
+	// keyPrefix - tells API keys apart from other secrets, e.g. in secret scanners
+	keyPrefix  = "usk_"
+	keySize    = 32
+	prefixSize = 12
+	maxNameLen = 100
+)
+
+var errInvalidKey = &core.UnauthorizedError{Message: "api key is invalid, expired or revoked"}
+
+// Manager - mints API keys and authenticates services by them
+type Manager struct {
+	store APIKeyStore
+}
+
+func NewManager(store APIKeyStore) *Manager {
+	return &Manager{
+		store: store,
+	}
+}
+
+// CreateAPIKey - mints a new key. Returned key holds the key itself, which is never available again.
+func (m *Manager) CreateAPIKey(ctx context.Context, key core.APIKey) (core.APIKey, error) {
+	err := validateAPIKey(key, time.Now())
+	if err != nil {
+		return core.APIKey{}, err
+	}
+	key.ID = uuid.New()
+	key.RevokedAt = time.Time{}
+	err = generateKey(&key)
+	if err != nil {
+		return core.APIKey{}, err
+	}
+	saved, err := m.store.SaveAPIKey(ctx, key)
+	if err != nil {
+		return core.APIKey{}, err
+	}
+	saved.Key = key.Key
+	return saved, nil
+}
+
+func (m *Manager) GetAllAPIKeys(ctx context.Context) ([]core.APIKey, error) {
+	return m.store.GetAllAPIKeys(ctx)
+}
+
+// RotateAPIKey - replaces the key, keeping its name, scopes and expiry. The old key stops working at once.
+// Returns core.ConflictError if the key has been revoked or has expired.
+func (m *Manager) RotateAPIKey(ctx context.Context, id uuid.UUID) (core.APIKey, error) {
+	key, err := m.store.GetAPIKeyByID(ctx, id)
+	if err != nil {
+		return core.APIKey{}, err
+	}
+	if !key.Active(time.Now()) {
+		return core.APIKey{}, &core.ConflictError{Message: "revoked or expired api key cannot be rotated"}
+	}
+	err = generateKey(&key)
+	if err != nil {
+		return core.APIKey{}, err
+	}
+	rotated, err := m.store.UpdateAPIKeyHash(ctx, key)
+	var notFoundErr *core.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		// Revoked in the meantime
+		return core.APIKey{}, &core.ConflictError{Message: "revoked or expired api key cannot be rotated"}
+	}
+	if err != nil {
+		return core.APIKey{}, err
+	}
+	rotated.Key = key.Key
+	return rotated, nil
+}
+
+// RevokeAPIKey - the key stops working at once, it is kept for auditing though
+func (m *Manager) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	return m.store.RevokeAPIKey(ctx, id)
+}
+
+// Authenticate - service actor of the key. Returns core.UnauthorizedError if the key is unknown, expired or revoked.
+func (m *Manager) Authenticate(ctx context.Context, key string) (core.Actor, error) {
+	if !strings.HasPrefix(key, keyPrefix) {
+		return core.Actor{}, errInvalidKey
+	}
+	stored, err := m.store.GetAPIKeyByHash(ctx, hashKey(key))
+	var notFoundErr *core.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		return core.Actor{}, errInvalidKey
+	}
+	if err != nil {
+		return core.Actor{}, err
+	}
+	if !stored.Active(time.Now()) {
+		return core.Actor{}, errInvalidKey
+	}
+	return stored.Actor(), nil
+}
+
+func validateAPIKey(key core.APIKey, now time.Time) error {
+	if strings.TrimSpace(key.Name) == "" || len(key.Name) > maxNameLen {
+		return &core.ValidationError{Field: "name", Message: fmt.Sprintf("name is required and must be at most %d characters long", maxNameLen)}
+	}
+	if len(key.Scopes) == 0 {
+		return &core.ValidationError{Field: "scopes", Message: "at least one scope is required"}
+	}
+	for _, scope := range key.Scopes {
+		if !scope.Valid() {
+			return &core.ValidationError{Field: "scopes", Message: fmt.Sprintf("unknown scope %q", scope)}
+		}
+	}
+	if !key.ExpiresAt.IsZero() && !key.ExpiresAt.After(now) {
+		return &core.ValidationError{Field: "expires_at", Message: "expires_at must be in the future"}
+	}
+	return nil
+}
+
+// generateKey - sets a new random key along with its prefix and hash
+func generateKey(key *core.APIKey) error {
+	raw := make([]byte, keySize)
+	_, err := rand.Read(raw)
+	if err != nil {
+		return err
+	}
+	key.Key = keyPrefix + base64.RawURLEncoding.EncodeToString(raw)
+	key.Prefix = key.Key[:prefixSize]
+	key.KeyHash = hashKey(key.Key)
+	return nil
+}
+
+// hashKey - keys are random and long, so a plain hash is enough to look them up without storing them
+func hashKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
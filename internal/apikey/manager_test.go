@@ -0,0 +1,178 @@
+package apikey_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"com.user.com/user/internal/apikey"
+	"com.user.com/user/internal/apikey/apikeyfakes"
+	"com.user.com/user/internal/core"
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("APIKey Manager", func() {
+	var (
+		store   *apikeyfakes.FakeAPIKeyStore
+		manager *apikey.Manager
+		ctx     context.Context
+	)
+
+	BeforeEach(func() {
+		store = &apikeyfakes.FakeAPIKeyStore{}
+		store.SaveAPIKeyStub = func(_ context.Context, key core.APIKey) (core.APIKey, error) {
+			key.Key = ""
+			return key, nil
+		}
+		store.UpdateAPIKeyHashStub = func(_ context.Context, key core.APIKey) (core.APIKey, error) {
+			key.Key = ""
+			return key, nil
+		}
+		manager = apikey.NewManager(store)
+		ctx = context.Background()
+	})
+
+	Context("CreateAPIKey", func() {
+		var (
+			params  core.APIKey
+			created core.APIKey
+			err     error
+		)
+		BeforeEach(func() {
+			params = core.APIKey{Name: "billing", Scopes: []core.Scope{core.ScopeUsersRead}}
+		})
+		JustBeforeEach(func() {
+			created, err = manager.CreateAPIKey(ctx, params)
+		})
+
+		It("returns the key once and stores only its hash", func() {
+			Expect(err).To(BeNil())
+			Expect(created.Key).To(HavePrefix("usk_"))
+			Expect(created.Prefix).To(Equal(created.Key[:12]))
+			Expect(store.SaveAPIKeyCallCount()).To(Equal(1))
+			_, stored := store.SaveAPIKeyArgsForCall(0)
+			Expect(stored.ID).ToNot(Equal(uuid.Nil))
+			Expect(stored.KeyHash).To(HaveLen(64))
+			Expect(stored.KeyHash).ToNot(ContainSubstring(created.Key))
+		})
+
+		Context("With unknown scope", func() {
+			BeforeEach(func() {
+				params.Scopes = append(params.Scopes, "users:everything")
+			})
+			It("fails with validation error", func() {
+				var validationErr *core.ValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				Expect(validationErr.Field).To(Equal("scopes"))
+				Expect(store.SaveAPIKeyCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("With expiry in the past", func() {
+			BeforeEach(func() {
+				params.ExpiresAt = time.Now().Add(-time.Minute)
+			})
+			It("fails with validation error", func() {
+				var validationErr *core.ValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				Expect(validationErr.Field).To(Equal("expires_at"))
+			})
+		})
+
+		Context("Without name", func() {
+			BeforeEach(func() {
+				params.Name = " "
+			})
+			It("fails with validation error", func() {
+				var validationErr *core.ValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				Expect(validationErr.Field).To(Equal("name"))
+			})
+		})
+	})
+
+	Context("Authenticate", func() {
+		var (
+			key    core.APIKey
+			stored core.APIKey
+		)
+		BeforeEach(func() {
+			var err error
+			key, err = manager.CreateAPIKey(ctx, core.APIKey{Name: "billing", Scopes: []core.Scope{core.ScopeUsersRead, core.ScopeUsersWrite}})
+			Expect(err).To(BeNil())
+			_, stored = store.SaveAPIKeyArgsForCall(0)
+			store.GetAPIKeyByHashReturns(stored, nil)
+		})
+
+		It("returns service actor limited to scopes of the key", func() {
+			actor, err := manager.Authenticate(ctx, key.Key)
+			Expect(err).To(BeNil())
+			Expect(actor.Type).To(Equal(core.ActorTypeService))
+			Expect(actor.ID).To(Equal(key.ID.String()))
+			Expect(actor.HasScope(core.ScopeUsersWrite)).To(BeTrue())
+			Expect(actor.HasScope(core.ScopeUsersDelete)).To(BeFalse())
+			_, hash := store.GetAPIKeyByHashArgsForCall(0)
+			Expect(hash).To(Equal(stored.KeyHash))
+		})
+
+		It("rejects revoked key", func() {
+			stored.RevokedAt = time.Now()
+			store.GetAPIKeyByHashReturns(stored, nil)
+			_, err := manager.Authenticate(ctx, key.Key)
+			var unauthorizedErr *core.UnauthorizedError
+			Expect(errors.As(err, &unauthorizedErr)).To(BeTrue())
+		})
+
+		It("rejects expired key", func() {
+			stored.ExpiresAt = time.Now().Add(-time.Second)
+			store.GetAPIKeyByHashReturns(stored, nil)
+			_, err := manager.Authenticate(ctx, key.Key)
+			var unauthorizedErr *core.UnauthorizedError
+			Expect(errors.As(err, &unauthorizedErr)).To(BeTrue())
+		})
+
+		It("rejects unknown key", func() {
+			store.GetAPIKeyByHashReturns(core.APIKey{}, &core.NotFoundError{Entity: "api key"})
+			_, err := manager.Authenticate(ctx, key.Key)
+			var unauthorizedErr *core.UnauthorizedError
+			Expect(errors.As(err, &unauthorizedErr)).To(BeTrue())
+		})
+
+		It("rejects malformed key without looking it up", func() {
+			_, err := manager.Authenticate(ctx, strings.TrimPrefix(key.Key, "usk_"))
+			var unauthorizedErr *core.UnauthorizedError
+			Expect(errors.As(err, &unauthorizedErr)).To(BeTrue())
+			Expect(store.GetAPIKeyByHashCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("RotateAPIKey", func() {
+		var current core.APIKey
+		BeforeEach(func() {
+			current = core.APIKey{ID: uuid.New(), Name: "billing", Prefix: "usk_abcdefgh", KeyHash: "old-hash", Scopes: []core.Scope{core.ScopeUsersRead}}
+			store.GetAPIKeyByIDReturns(current, nil)
+		})
+
+		It("replaces the key and keeps its scopes", func() {
+			rotated, err := manager.RotateAPIKey(ctx, current.ID)
+			Expect(err).To(BeNil())
+			Expect(rotated.Key).To(HavePrefix("usk_"))
+			Expect(rotated.Scopes).To(Equal(current.Scopes))
+			_, updated := store.UpdateAPIKeyHashArgsForCall(0)
+			Expect(updated.ID).To(Equal(current.ID))
+			Expect(updated.KeyHash).ToNot(Equal("old-hash"))
+		})
+
+		It("fails with conflict error on revoked key", func() {
+			current.RevokedAt = time.Now()
+			store.GetAPIKeyByIDReturns(current, nil)
+			_, err := manager.RotateAPIKey(ctx, current.ID)
+			var conflictErr *core.ConflictError
+			Expect(errors.As(err, &conflictErr)).To(BeTrue())
+			Expect(store.UpdateAPIKeyHashCallCount()).To(Equal(0))
+		})
+	})
+})
@@ -0,0 +1,12 @@
+package store
+
+import "com.user.com/user/internal/dberror"
+
+// translateError - converts db specific errors into core error types, e.g. violation of api_keys_key_hash_key into
+// conflict on key
+var translateError = dberror.Translator{
+	Entity: "api key",
+	Fields: []dberror.Field{
+		{Constraint: "key_hash", Name: "key"},
+	},
+}.Translate
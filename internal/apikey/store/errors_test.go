@@ -0,0 +1,24 @@
+package store
+
+import (
+	"errors"
+
+	"com.user.com/user/internal/core"
+	"github.com/lib/pq"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Translate error", func() {
+	table.DescribeTable("unique violation is a conflict on the violated field",
+		func(pqErr *pq.Error, field string) {
+			var conflict *core.ConflictError
+			Expect(errors.As(translateError(pqErr), &conflict)).To(BeTrue())
+			Expect(conflict.Field).To(Equal(field))
+		},
+		table.Entry("key hash by constraint", &pq.Error{Code: "23505", Constraint: "api_keys_key_hash_key"}, "key"),
+		table.Entry("key hash by message", &pq.Error{Code: "23505", Message: `duplicate key value violates unique constraint "key_hash"`}, "key"),
+		table.Entry("primary key", &pq.Error{Code: "23505", Constraint: "api_keys_pkey"}, "id"),
+	)
+})
@@ -0,0 +1,179 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	apiKeyColumns     = `id, name, prefix, key_hash, scopes, expires_at, revoked_at, created_at, updated_at`
+	storeAPIKeyStmt   = `INSERT INTO api_keys (id, name, prefix, key_hash, scopes, expires_at, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, now(), now()) RETURNING created_at, updated_at`
+	updateKeyHashStmt = `UPDATE api_keys SET prefix=$1, key_hash=$2, updated_at=now() WHERE id=$3 AND revoked_at IS NULL RETURNING created_at, updated_at`
+	revokeAPIKeyStmt  = `UPDATE api_keys SET revoked_at=COALESCE(revoked_at, now()), updated_at=now() WHERE id=$1`
+	getAPIKeyStmt     = `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE id=$1`
+	getAPIKeyByHash   = `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash=$1`
+	getAllAPIKeysStmt = `SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY created_at`
+)
+
+// Store - API keys in db
+type Store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{
+		db: db,
+	}
+}
+
+// SaveAPIKey - stores the key and returns it along with db generated timestamps
+func (s *Store) SaveAPIKey(ctx context.Context, key core.APIKey) (core.APIKey, error) {
+	err := s.db.QueryRowContext(ctx,
+		storeAPIKeyStmt,
+		key.ID,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		pq.Array(scopesToStrings(key.Scopes)),
+		nullTime(key.ExpiresAt),
+	).Scan(
+		&key.CreatedAt,
+		&key.UpdatedAt,
+	)
+	if err != nil {
+		return core.APIKey{}, translateError(err)
+	}
+	return key, nil
+}
+
+// GetAPIKeyByID - returns core.NotFoundError if there is no such key
+func (s *Store) GetAPIKeyByID(ctx context.Context, id uuid.UUID) (core.APIKey, error) {
+	key, err := scanAPIKey(s.db.QueryRowContext(ctx, getAPIKeyStmt, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.APIKey{}, &core.NotFoundError{Entity: "api key", Key: id.String()}
+	}
+	if err != nil {
+		return core.APIKey{}, translateError(err)
+	}
+	return key, nil
+}
+
+// GetAPIKeyByHash - returns core.NotFoundError if there is no key with such hash
+func (s *Store) GetAPIKeyByHash(ctx context.Context, keyHash string) (core.APIKey, error) {
+	key, err := scanAPIKey(s.db.QueryRowContext(ctx, getAPIKeyByHash, keyHash))
+	if errors.Is(err, sql.ErrNoRows) {
+		// Hash is not logged, it is as good as the key for looking it up
+		return core.APIKey{}, &core.NotFoundError{Entity: "api key"}
+	}
+	if err != nil {
+		return core.APIKey{}, translateError(err)
+	}
+	return key, nil
+}
+
+func (s *Store) GetAllAPIKeys(ctx context.Context) ([]core.APIKey, error) {
+	rows, err := s.db.QueryContext(ctx, getAllAPIKeysStmt)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	keys := make([]core.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, translateError(err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, translateError(rows.Err())
+}
+
+// UpdateAPIKeyHash - replaces prefix and hash of the key. Returns core.NotFoundError if there is no such active key.
+func (s *Store) UpdateAPIKeyHash(ctx context.Context, key core.APIKey) (core.APIKey, error) {
+	err := s.db.QueryRowContext(ctx,
+		updateKeyHashStmt,
+		key.Prefix,
+		key.KeyHash,
+		key.ID,
+	).Scan(
+		&key.CreatedAt,
+		&key.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.APIKey{}, &core.NotFoundError{Entity: "api key", Key: key.ID.String()}
+	}
+	if err != nil {
+		return core.APIKey{}, translateError(err)
+	}
+	return key, nil
+}
+
+// RevokeAPIKey - returns core.NotFoundError if there is no such key, revoking a revoked key is a no-op
+func (s *Store) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	res, err := s.db.ExecContext(ctx, revokeAPIKeyStmt, id)
+	if err != nil {
+		return translateError(err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return translateError(err)
+	}
+	if affected == 0 {
+		return &core.NotFoundError{Entity: "api key", Key: id.String()}
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAPIKey(row rowScanner) (core.APIKey, error) {
+	var (
+		key                  core.APIKey
+		scopes               []string
+		expiresAt, revokedAt sql.NullTime
+	)
+	err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		pq.Array(&scopes),
+		&expiresAt,
+		&revokedAt,
+		&key.CreatedAt,
+		&key.UpdatedAt,
+	)
+	if err != nil {
+		return core.APIKey{}, err
+	}
+	for _, scope := range scopes {
+		key.Scopes = append(key.Scopes, core.Scope(scope))
+	}
+	key.ExpiresAt = expiresAt.Time
+	key.RevokedAt = revokedAt.Time
+	return key, nil
+}
+
+func scopesToStrings(scopes []core.Scope) []string {
+	result := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		result = append(result, string(scope))
+	}
+	return result
+}
+
+// nullTime - zero time is stored as NULL
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
@@ -0,0 +1,58 @@
+package apikeyview
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/view"
+	"github.com/go-playground/validator"
+	"github.com/sirupsen/logrus"
+)
+
+type CreateAPIKeyEndpoint struct {
+	keyCreator APIKeyCreator
+	validator  *validator.Validate
+}
+
+type APIKeyCreator interface {
+	CreateAPIKey(ctx context.Context, key core.APIKey) (core.APIKey, error)
+}
+
+func NewCreateAPIKeyEndpoint(keyCreator APIKeyCreator) *CreateAPIKeyEndpoint {
+	return &CreateAPIKeyEndpoint{
+		keyCreator: keyCreator,
+		validator:  view.NewValidator(),
+	}
+}
+
+func (c *CreateAPIKeyEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(time.Millisecond*10000))
+	defer cancel()
+	logrus.WithContext(ctx).
+		WithField("Endoint", "apikeyview.CreateAPIKeyEndpoint").
+		Debug("request started")
+
+	var params APIKeyParams
+	if !view.ReadBody(ctx, w, r, &params, c.validator) {
+		return
+	}
+
+	createdKey, err := c.keyCreator.CreateAPIKey(ctx, params.toAPIKey())
+	if err != nil {
+		view.RespondError(ctx, w, err, "creating api key")
+		return
+	}
+
+	// Key is returned only once, it cannot be recovered afterwards
+	resp := newPublicAPIKey(createdKey)
+	resp.Key = createdKey.Key
+	w.Header().Set("Location", path.Join(r.URL.Path, createdKey.ID.String()))
+	w.Header().Set("Cache-Control", "no-store")
+	view.RespondJSON(ctx, w, http.StatusCreated, resp)
+	logrus.WithContext(ctx).
+		WithField("Endoint", "apikeyview.CreateAPIKeyEndpoint").
+		Debug("request completed")
+}
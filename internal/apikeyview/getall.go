@@ -0,0 +1,54 @@
+package apikeyview
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/view"
+	"github.com/sirupsen/logrus"
+)
+
+type GetAllAPIKeysEndpoint struct {
+	keyGetter APIKeyGetter
+}
+
+type GetAllAPIKeysResponse struct {
+	APIKeys []PublicAPIKey `json:"api_keys"`
+}
+
+type APIKeyGetter interface {
+	GetAllAPIKeys(ctx context.Context) ([]core.APIKey, error)
+}
+
+func NewGetAllAPIKeysEndpoint(keyGetter APIKeyGetter) *GetAllAPIKeysEndpoint {
+	return &GetAllAPIKeysEndpoint{
+		keyGetter: keyGetter,
+	}
+}
+
+func (g *GetAllAPIKeysEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(time.Millisecond*10000))
+	defer cancel()
+	logrus.WithContext(ctx).
+		WithField("Endoint", "apikeyview.GetAllAPIKeysEndpoint").
+		Debug("request started")
+
+	keys, err := g.keyGetter.GetAllAPIKeys(ctx)
+	if err != nil {
+		view.RespondError(ctx, w, err, "getting api keys")
+		return
+	}
+	response := GetAllAPIKeysResponse{
+		APIKeys: make([]PublicAPIKey, 0, len(keys)),
+	}
+	for _, key := range keys {
+		response.APIKeys = append(response.APIKeys, newPublicAPIKey(key))
+	}
+
+	view.RespondJSON(ctx, w, http.StatusOK, &response)
+	logrus.WithContext(ctx).
+		WithField("Endoint", "apikeyview.GetAllAPIKeysEndpoint").
+		Debug("request completed")
+}
@@ -0,0 +1,84 @@
+package apikeyview
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/view"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// PublicAPIKey - representation of the API key exposed to clients. Key itself is exposed only once, when the
+// key is minted or rotated.
+type PublicAPIKey struct {
+	ID        uuid.UUID  `json:"id"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"`
+	Key       string     `json:"key,omitempty"`
+	Scopes    []string   `json:"scopes"`
+	Active    bool       `json:"active"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func newPublicAPIKey(key core.APIKey) PublicAPIKey {
+	scopes := make([]string, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+	return PublicAPIKey{
+		ID:        key.ID,
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		Scopes:    scopes,
+		Active:    key.Active(time.Now()),
+		ExpiresAt: optionalTime(key.ExpiresAt),
+		RevokedAt: optionalTime(key.RevokedAt),
+		CreatedAt: key.CreatedAt,
+		UpdatedAt: key.UpdatedAt,
+	}
+}
+
+// APIKeyParams - body of mint requests. Keys without expires_at never expire.
+type APIKeyParams struct {
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func (p APIKeyParams) toAPIKey() core.APIKey {
+	key := core.APIKey{
+		Name: p.Name,
+	}
+	if p.ExpiresAt != nil {
+		key.ExpiresAt = *p.ExpiresAt
+	}
+	for _, scope := range p.Scopes {
+		key.Scopes = append(key.Scopes, core.Scope(scope))
+	}
+	return key
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// tryParsingKeyID - responds with bad request if keyID path param is not valid
+func tryParsingKeyID(ctx context.Context, w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	keyID := mux.Vars(r)["keyID"]
+	id, err := uuid.Parse(keyID)
+	if err != nil {
+		view.RespondBadRequest(ctx, w, fmt.Sprintf("invalid api key id: %v", keyID), view.InvalidParam{Name: "keyID", Reason: "must be a valid UUID"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -0,0 +1,48 @@
+package apikeyview
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"com.user.com/user/internal/view"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type RevokeAPIKeyEndpoint struct {
+	keyRevoker APIKeyRevoker
+}
+
+type APIKeyRevoker interface {
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) error
+}
+
+func NewRevokeAPIKeyEndpoint(keyRevoker APIKeyRevoker) *RevokeAPIKeyEndpoint {
+	return &RevokeAPIKeyEndpoint{
+		keyRevoker: keyRevoker,
+	}
+}
+
+func (d *RevokeAPIKeyEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(time.Millisecond*10000))
+	defer cancel()
+	logrus.WithContext(ctx).
+		WithField("Endoint", "apikeyview.RevokeAPIKeyEndpoint").
+		Debug("request started")
+
+	id, ok := tryParsingKeyID(ctx, w, r)
+	if !ok {
+		return
+	}
+	err := d.keyRevoker.RevokeAPIKey(ctx, id)
+	if err != nil {
+		view.RespondError(ctx, w, err, "revoking api key")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	logrus.WithContext(ctx).
+		WithField("Endoint", "apikeyview.RevokeAPIKeyEndpoint").
+		Debug("request completed")
+}
@@ -0,0 +1,53 @@
+package apikeyview
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/view"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type RotateAPIKeyEndpoint struct {
+	keyRotator APIKeyRotator
+}
+
+type APIKeyRotator interface {
+	RotateAPIKey(ctx context.Context, id uuid.UUID) (core.APIKey, error)
+}
+
+func NewRotateAPIKeyEndpoint(keyRotator APIKeyRotator) *RotateAPIKeyEndpoint {
+	return &RotateAPIKeyEndpoint{
+		keyRotator: keyRotator,
+	}
+}
+
+func (rt *RotateAPIKeyEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(time.Millisecond*10000))
+	defer cancel()
+	logrus.WithContext(ctx).
+		WithField("Endoint", "apikeyview.RotateAPIKeyEndpoint").
+		Debug("request started")
+
+	id, ok := tryParsingKeyID(ctx, w, r)
+	if !ok {
+		return
+	}
+	rotatedKey, err := rt.keyRotator.RotateAPIKey(ctx, id)
+	if err != nil {
+		view.RespondError(ctx, w, err, "rotating api key")
+		return
+	}
+
+	// New key is returned only once, like a minted one
+	resp := newPublicAPIKey(rotatedKey)
+	resp.Key = rotatedKey.Key
+	w.Header().Set("Cache-Control", "no-store")
+	view.RespondJSON(ctx, w, http.StatusOK, resp)
+	logrus.WithContext(ctx).
+		WithField("Endoint", "apikeyview.RotateAPIKeyEndpoint").
+		Debug("request completed")
+}
@@ -0,0 +1,508 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package authfakes
+
+import (
+	"context"
+	"sync"
+
+	"com.user.com/user/internal/auth"
+	"com.user.com/user/internal/core"
+	"github.com/google/uuid"
+)
+
+type FakeTokenStore struct {
+	GetPasswordResetTokenStub        func(context.Context, string) (core.PasswordResetToken, error)
+	getPasswordResetTokenMutex       sync.RWMutex
+	getPasswordResetTokenArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	getPasswordResetTokenReturns struct {
+		result1 core.PasswordResetToken
+		result2 error
+	}
+	getPasswordResetTokenReturnsOnCall map[int]struct {
+		result1 core.PasswordResetToken
+		result2 error
+	}
+	GetRefreshTokenStub        func(context.Context, string) (core.RefreshToken, error)
+	getRefreshTokenMutex       sync.RWMutex
+	getRefreshTokenArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	getRefreshTokenReturns struct {
+		result1 core.RefreshToken
+		result2 error
+	}
+	getRefreshTokenReturnsOnCall map[int]struct {
+		result1 core.RefreshToken
+		result2 error
+	}
+	RevokeTokenFamilyStub        func(context.Context, uuid.UUID) error
+	revokeTokenFamilyMutex       sync.RWMutex
+	revokeTokenFamilyArgsForCall []struct {
+		arg1 context.Context
+		arg2 uuid.UUID
+	}
+	revokeTokenFamilyReturns struct {
+		result1 error
+	}
+	revokeTokenFamilyReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RotateRefreshTokenStub        func(context.Context, uuid.UUID, core.RefreshToken) error
+	rotateRefreshTokenMutex       sync.RWMutex
+	rotateRefreshTokenArgsForCall []struct {
+		arg1 context.Context
+		arg2 uuid.UUID
+		arg3 core.RefreshToken
+	}
+	rotateRefreshTokenReturns struct {
+		result1 error
+	}
+	rotateRefreshTokenReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SavePasswordResetTokenStub        func(context.Context, core.PasswordResetToken) error
+	savePasswordResetTokenMutex       sync.RWMutex
+	savePasswordResetTokenArgsForCall []struct {
+		arg1 context.Context
+		arg2 core.PasswordResetToken
+	}
+	savePasswordResetTokenReturns struct {
+		result1 error
+	}
+	savePasswordResetTokenReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SaveRefreshTokenStub        func(context.Context, core.RefreshToken) error
+	saveRefreshTokenMutex       sync.RWMutex
+	saveRefreshTokenArgsForCall []struct {
+		arg1 context.Context
+		arg2 core.RefreshToken
+	}
+	saveRefreshTokenReturns struct {
+		result1 error
+	}
+	saveRefreshTokenReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeTokenStore) GetPasswordResetToken(arg1 context.Context, arg2 string) (core.PasswordResetToken, error) {
+	fake.getPasswordResetTokenMutex.Lock()
+	ret, specificReturn := fake.getPasswordResetTokenReturnsOnCall[len(fake.getPasswordResetTokenArgsForCall)]
+	fake.getPasswordResetTokenArgsForCall = append(fake.getPasswordResetTokenArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetPasswordResetTokenStub
+	fakeReturns := fake.getPasswordResetTokenReturns
+	fake.recordInvocation("GetPasswordResetToken", []interface{}{arg1, arg2})
+	fake.getPasswordResetTokenMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeTokenStore) GetPasswordResetTokenCallCount() int {
+	fake.getPasswordResetTokenMutex.RLock()
+	defer fake.getPasswordResetTokenMutex.RUnlock()
+	return len(fake.getPasswordResetTokenArgsForCall)
+}
+
+func (fake *FakeTokenStore) GetPasswordResetTokenCalls(stub func(context.Context, string) (core.PasswordResetToken, error)) {
+	fake.getPasswordResetTokenMutex.Lock()
+	defer fake.getPasswordResetTokenMutex.Unlock()
+	fake.GetPasswordResetTokenStub = stub
+}
+
+func (fake *FakeTokenStore) GetPasswordResetTokenArgsForCall(i int) (context.Context, string) {
+	fake.getPasswordResetTokenMutex.RLock()
+	defer fake.getPasswordResetTokenMutex.RUnlock()
+	argsForCall := fake.getPasswordResetTokenArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeTokenStore) GetPasswordResetTokenReturns(result1 core.PasswordResetToken, result2 error) {
+	fake.getPasswordResetTokenMutex.Lock()
+	defer fake.getPasswordResetTokenMutex.Unlock()
+	fake.GetPasswordResetTokenStub = nil
+	fake.getPasswordResetTokenReturns = struct {
+		result1 core.PasswordResetToken
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeTokenStore) GetPasswordResetTokenReturnsOnCall(i int, result1 core.PasswordResetToken, result2 error) {
+	fake.getPasswordResetTokenMutex.Lock()
+	defer fake.getPasswordResetTokenMutex.Unlock()
+	fake.GetPasswordResetTokenStub = nil
+	if fake.getPasswordResetTokenReturnsOnCall == nil {
+		fake.getPasswordResetTokenReturnsOnCall = make(map[int]struct {
+			result1 core.PasswordResetToken
+			result2 error
+		})
+	}
+	fake.getPasswordResetTokenReturnsOnCall[i] = struct {
+		result1 core.PasswordResetToken
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeTokenStore) GetRefreshToken(arg1 context.Context, arg2 string) (core.RefreshToken, error) {
+	fake.getRefreshTokenMutex.Lock()
+	ret, specificReturn := fake.getRefreshTokenReturnsOnCall[len(fake.getRefreshTokenArgsForCall)]
+	fake.getRefreshTokenArgsForCall = append(fake.getRefreshTokenArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetRefreshTokenStub
+	fakeReturns := fake.getRefreshTokenReturns
+	fake.recordInvocation("GetRefreshToken", []interface{}{arg1, arg2})
+	fake.getRefreshTokenMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeTokenStore) GetRefreshTokenCallCount() int {
+	fake.getRefreshTokenMutex.RLock()
+	defer fake.getRefreshTokenMutex.RUnlock()
+	return len(fake.getRefreshTokenArgsForCall)
+}
+
+func (fake *FakeTokenStore) GetRefreshTokenCalls(stub func(context.Context, string) (core.RefreshToken, error)) {
+	fake.getRefreshTokenMutex.Lock()
+	defer fake.getRefreshTokenMutex.Unlock()
+	fake.GetRefreshTokenStub = stub
+}
+
+func (fake *FakeTokenStore) GetRefreshTokenArgsForCall(i int) (context.Context, string) {
+	fake.getRefreshTokenMutex.RLock()
+	defer fake.getRefreshTokenMutex.RUnlock()
+	argsForCall := fake.getRefreshTokenArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeTokenStore) GetRefreshTokenReturns(result1 core.RefreshToken, result2 error) {
+	fake.getRefreshTokenMutex.Lock()
+	defer fake.getRefreshTokenMutex.Unlock()
+	fake.GetRefreshTokenStub = nil
+	fake.getRefreshTokenReturns = struct {
+		result1 core.RefreshToken
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeTokenStore) GetRefreshTokenReturnsOnCall(i int, result1 core.RefreshToken, result2 error) {
+	fake.getRefreshTokenMutex.Lock()
+	defer fake.getRefreshTokenMutex.Unlock()
+	fake.GetRefreshTokenStub = nil
+	if fake.getRefreshTokenReturnsOnCall == nil {
+		fake.getRefreshTokenReturnsOnCall = make(map[int]struct {
+			result1 core.RefreshToken
+			result2 error
+		})
+	}
+	fake.getRefreshTokenReturnsOnCall[i] = struct {
+		result1 core.RefreshToken
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeTokenStore) RevokeTokenFamily(arg1 context.Context, arg2 uuid.UUID) error {
+	fake.revokeTokenFamilyMutex.Lock()
+	ret, specificReturn := fake.revokeTokenFamilyReturnsOnCall[len(fake.revokeTokenFamilyArgsForCall)]
+	fake.revokeTokenFamilyArgsForCall = append(fake.revokeTokenFamilyArgsForCall, struct {
+		arg1 context.Context
+		arg2 uuid.UUID
+	}{arg1, arg2})
+	stub := fake.RevokeTokenFamilyStub
+	fakeReturns := fake.revokeTokenFamilyReturns
+	fake.recordInvocation("RevokeTokenFamily", []interface{}{arg1, arg2})
+	fake.revokeTokenFamilyMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeTokenStore) RevokeTokenFamilyCallCount() int {
+	fake.revokeTokenFamilyMutex.RLock()
+	defer fake.revokeTokenFamilyMutex.RUnlock()
+	return len(fake.revokeTokenFamilyArgsForCall)
+}
+
+func (fake *FakeTokenStore) RevokeTokenFamilyCalls(stub func(context.Context, uuid.UUID) error) {
+	fake.revokeTokenFamilyMutex.Lock()
+	defer fake.revokeTokenFamilyMutex.Unlock()
+	fake.RevokeTokenFamilyStub = stub
+}
+
+func (fake *FakeTokenStore) RevokeTokenFamilyArgsForCall(i int) (context.Context, uuid.UUID) {
+	fake.revokeTokenFamilyMutex.RLock()
+	defer fake.revokeTokenFamilyMutex.RUnlock()
+	argsForCall := fake.revokeTokenFamilyArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeTokenStore) RevokeTokenFamilyReturns(result1 error) {
+	fake.revokeTokenFamilyMutex.Lock()
+	defer fake.revokeTokenFamilyMutex.Unlock()
+	fake.RevokeTokenFamilyStub = nil
+	fake.revokeTokenFamilyReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeTokenStore) RevokeTokenFamilyReturnsOnCall(i int, result1 error) {
+	fake.revokeTokenFamilyMutex.Lock()
+	defer fake.revokeTokenFamilyMutex.Unlock()
+	fake.RevokeTokenFamilyStub = nil
+	if fake.revokeTokenFamilyReturnsOnCall == nil {
+		fake.revokeTokenFamilyReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.revokeTokenFamilyReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeTokenStore) RotateRefreshToken(arg1 context.Context, arg2 uuid.UUID, arg3 core.RefreshToken) error {
+	fake.rotateRefreshTokenMutex.Lock()
+	ret, specificReturn := fake.rotateRefreshTokenReturnsOnCall[len(fake.rotateRefreshTokenArgsForCall)]
+	fake.rotateRefreshTokenArgsForCall = append(fake.rotateRefreshTokenArgsForCall, struct {
+		arg1 context.Context
+		arg2 uuid.UUID
+		arg3 core.RefreshToken
+	}{arg1, arg2, arg3})
+	stub := fake.RotateRefreshTokenStub
+	fakeReturns := fake.rotateRefreshTokenReturns
+	fake.recordInvocation("RotateRefreshToken", []interface{}{arg1, arg2, arg3})
+	fake.rotateRefreshTokenMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeTokenStore) RotateRefreshTokenCallCount() int {
+	fake.rotateRefreshTokenMutex.RLock()
+	defer fake.rotateRefreshTokenMutex.RUnlock()
+	return len(fake.rotateRefreshTokenArgsForCall)
+}
+
+func (fake *FakeTokenStore) RotateRefreshTokenCalls(stub func(context.Context, uuid.UUID, core.RefreshToken) error) {
+	fake.rotateRefreshTokenMutex.Lock()
+	defer fake.rotateRefreshTokenMutex.Unlock()
+	fake.RotateRefreshTokenStub = stub
+}
+
+func (fake *FakeTokenStore) RotateRefreshTokenArgsForCall(i int) (context.Context, uuid.UUID, core.RefreshToken) {
+	fake.rotateRefreshTokenMutex.RLock()
+	defer fake.rotateRefreshTokenMutex.RUnlock()
+	argsForCall := fake.rotateRefreshTokenArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeTokenStore) RotateRefreshTokenReturns(result1 error) {
+	fake.rotateRefreshTokenMutex.Lock()
+	defer fake.rotateRefreshTokenMutex.Unlock()
+	fake.RotateRefreshTokenStub = nil
+	fake.rotateRefreshTokenReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeTokenStore) RotateRefreshTokenReturnsOnCall(i int, result1 error) {
+	fake.rotateRefreshTokenMutex.Lock()
+	defer fake.rotateRefreshTokenMutex.Unlock()
+	fake.RotateRefreshTokenStub = nil
+	if fake.rotateRefreshTokenReturnsOnCall == nil {
+		fake.rotateRefreshTokenReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.rotateRefreshTokenReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeTokenStore) SavePasswordResetToken(arg1 context.Context, arg2 core.PasswordResetToken) error {
+	fake.savePasswordResetTokenMutex.Lock()
+	ret, specificReturn := fake.savePasswordResetTokenReturnsOnCall[len(fake.savePasswordResetTokenArgsForCall)]
+	fake.savePasswordResetTokenArgsForCall = append(fake.savePasswordResetTokenArgsForCall, struct {
+		arg1 context.Context
+		arg2 core.PasswordResetToken
+	}{arg1, arg2})
+	stub := fake.SavePasswordResetTokenStub
+	fakeReturns := fake.savePasswordResetTokenReturns
+	fake.recordInvocation("SavePasswordResetToken", []interface{}{arg1, arg2})
+	fake.savePasswordResetTokenMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeTokenStore) SavePasswordResetTokenCallCount() int {
+	fake.savePasswordResetTokenMutex.RLock()
+	defer fake.savePasswordResetTokenMutex.RUnlock()
+	return len(fake.savePasswordResetTokenArgsForCall)
+}
+
+func (fake *FakeTokenStore) SavePasswordResetTokenCalls(stub func(context.Context, core.PasswordResetToken) error) {
+	fake.savePasswordResetTokenMutex.Lock()
+	defer fake.savePasswordResetTokenMutex.Unlock()
+	fake.SavePasswordResetTokenStub = stub
+}
+
+func (fake *FakeTokenStore) SavePasswordResetTokenArgsForCall(i int) (context.Context, core.PasswordResetToken) {
+	fake.savePasswordResetTokenMutex.RLock()
+	defer fake.savePasswordResetTokenMutex.RUnlock()
+	argsForCall := fake.savePasswordResetTokenArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeTokenStore) SavePasswordResetTokenReturns(result1 error) {
+	fake.savePasswordResetTokenMutex.Lock()
+	defer fake.savePasswordResetTokenMutex.Unlock()
+	fake.SavePasswordResetTokenStub = nil
+	fake.savePasswordResetTokenReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeTokenStore) SavePasswordResetTokenReturnsOnCall(i int, result1 error) {
+	fake.savePasswordResetTokenMutex.Lock()
+	defer fake.savePasswordResetTokenMutex.Unlock()
+	fake.SavePasswordResetTokenStub = nil
+	if fake.savePasswordResetTokenReturnsOnCall == nil {
+		fake.savePasswordResetTokenReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.savePasswordResetTokenReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeTokenStore) SaveRefreshToken(arg1 context.Context, arg2 core.RefreshToken) error {
+	fake.saveRefreshTokenMutex.Lock()
+	ret, specificReturn := fake.saveRefreshTokenReturnsOnCall[len(fake.saveRefreshTokenArgsForCall)]
+	fake.saveRefreshTokenArgsForCall = append(fake.saveRefreshTokenArgsForCall, struct {
+		arg1 context.Context
+		arg2 core.RefreshToken
+	}{arg1, arg2})
+	stub := fake.SaveRefreshTokenStub
+	fakeReturns := fake.saveRefreshTokenReturns
+	fake.recordInvocation("SaveRefreshToken", []interface{}{arg1, arg2})
+	fake.saveRefreshTokenMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeTokenStore) SaveRefreshTokenCallCount() int {
+	fake.saveRefreshTokenMutex.RLock()
+	defer fake.saveRefreshTokenMutex.RUnlock()
+	return len(fake.saveRefreshTokenArgsForCall)
+}
+
+func (fake *FakeTokenStore) SaveRefreshTokenCalls(stub func(context.Context, core.RefreshToken) error) {
+	fake.saveRefreshTokenMutex.Lock()
+	defer fake.saveRefreshTokenMutex.Unlock()
+	fake.SaveRefreshTokenStub = stub
+}
+
+func (fake *FakeTokenStore) SaveRefreshTokenArgsForCall(i int) (context.Context, core.RefreshToken) {
+	fake.saveRefreshTokenMutex.RLock()
+	defer fake.saveRefreshTokenMutex.RUnlock()
+	argsForCall := fake.saveRefreshTokenArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeTokenStore) SaveRefreshTokenReturns(result1 error) {
+	fake.saveRefreshTokenMutex.Lock()
+	defer fake.saveRefreshTokenMutex.Unlock()
+	fake.SaveRefreshTokenStub = nil
+	fake.saveRefreshTokenReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeTokenStore) SaveRefreshTokenReturnsOnCall(i int, result1 error) {
+	fake.saveRefreshTokenMutex.Lock()
+	defer fake.saveRefreshTokenMutex.Unlock()
+	fake.SaveRefreshTokenStub = nil
+	if fake.saveRefreshTokenReturnsOnCall == nil {
+		fake.saveRefreshTokenReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.saveRefreshTokenReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeTokenStore) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.getPasswordResetTokenMutex.RLock()
+	defer fake.getPasswordResetTokenMutex.RUnlock()
+	fake.getRefreshTokenMutex.RLock()
+	defer fake.getRefreshTokenMutex.RUnlock()
+	fake.revokeTokenFamilyMutex.RLock()
+	defer fake.revokeTokenFamilyMutex.RUnlock()
+	fake.rotateRefreshTokenMutex.RLock()
+	defer fake.rotateRefreshTokenMutex.RUnlock()
+	fake.savePasswordResetTokenMutex.RLock()
+	defer fake.savePasswordResetTokenMutex.RUnlock()
+	fake.saveRefreshTokenMutex.RLock()
+	defer fake.saveRefreshTokenMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeTokenStore) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ auth.TokenStore = new(FakeTokenStore)
@@ -0,0 +1,376 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package authfakes
+
+import (
+	"context"
+	"sync"
+
+	"com.user.com/user/internal/auth"
+	"com.user.com/user/internal/core"
+	"github.com/google/uuid"
+)
+
+type FakeUserAuthenticator struct {
+	AuthenticateStub        func(context.Context, string, string) (core.User, error)
+	authenticateMutex       sync.RWMutex
+	authenticateArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}
+	authenticateReturns struct {
+		result1 core.User
+		result2 error
+	}
+	authenticateReturnsOnCall map[int]struct {
+		result1 core.User
+		result2 error
+	}
+	GetUserByEmailStub        func(context.Context, core.Actor, string) (core.User, error)
+	getUserByEmailMutex       sync.RWMutex
+	getUserByEmailArgsForCall []struct {
+		arg1 context.Context
+		arg2 core.Actor
+		arg3 string
+	}
+	getUserByEmailReturns struct {
+		result1 core.User
+		result2 error
+	}
+	getUserByEmailReturnsOnCall map[int]struct {
+		result1 core.User
+		result2 error
+	}
+	GetUserByIDStub        func(context.Context, core.Actor, uuid.UUID) (core.User, error)
+	getUserByIDMutex       sync.RWMutex
+	getUserByIDArgsForCall []struct {
+		arg1 context.Context
+		arg2 core.Actor
+		arg3 uuid.UUID
+	}
+	getUserByIDReturns struct {
+		result1 core.User
+		result2 error
+	}
+	getUserByIDReturnsOnCall map[int]struct {
+		result1 core.User
+		result2 error
+	}
+	ResetPasswordStub        func(context.Context, core.Actor, uuid.UUID, string, string) (core.User, error)
+	resetPasswordMutex       sync.RWMutex
+	resetPasswordArgsForCall []struct {
+		arg1 context.Context
+		arg2 core.Actor
+		arg3 uuid.UUID
+		arg4 string
+		arg5 string
+	}
+	resetPasswordReturns struct {
+		result1 core.User
+		result2 error
+	}
+	resetPasswordReturnsOnCall map[int]struct {
+		result1 core.User
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeUserAuthenticator) Authenticate(arg1 context.Context, arg2 string, arg3 string) (core.User, error) {
+	fake.authenticateMutex.Lock()
+	ret, specificReturn := fake.authenticateReturnsOnCall[len(fake.authenticateArgsForCall)]
+	fake.authenticateArgsForCall = append(fake.authenticateArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.AuthenticateStub
+	fakeReturns := fake.authenticateReturns
+	fake.recordInvocation("Authenticate", []interface{}{arg1, arg2, arg3})
+	fake.authenticateMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeUserAuthenticator) AuthenticateCallCount() int {
+	fake.authenticateMutex.RLock()
+	defer fake.authenticateMutex.RUnlock()
+	return len(fake.authenticateArgsForCall)
+}
+
+func (fake *FakeUserAuthenticator) AuthenticateCalls(stub func(context.Context, string, string) (core.User, error)) {
+	fake.authenticateMutex.Lock()
+	defer fake.authenticateMutex.Unlock()
+	fake.AuthenticateStub = stub
+}
+
+func (fake *FakeUserAuthenticator) AuthenticateArgsForCall(i int) (context.Context, string, string) {
+	fake.authenticateMutex.RLock()
+	defer fake.authenticateMutex.RUnlock()
+	argsForCall := fake.authenticateArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeUserAuthenticator) AuthenticateReturns(result1 core.User, result2 error) {
+	fake.authenticateMutex.Lock()
+	defer fake.authenticateMutex.Unlock()
+	fake.AuthenticateStub = nil
+	fake.authenticateReturns = struct {
+		result1 core.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserAuthenticator) AuthenticateReturnsOnCall(i int, result1 core.User, result2 error) {
+	fake.authenticateMutex.Lock()
+	defer fake.authenticateMutex.Unlock()
+	fake.AuthenticateStub = nil
+	if fake.authenticateReturnsOnCall == nil {
+		fake.authenticateReturnsOnCall = make(map[int]struct {
+			result1 core.User
+			result2 error
+		})
+	}
+	fake.authenticateReturnsOnCall[i] = struct {
+		result1 core.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserAuthenticator) GetUserByEmail(arg1 context.Context, arg2 core.Actor, arg3 string) (core.User, error) {
+	fake.getUserByEmailMutex.Lock()
+	ret, specificReturn := fake.getUserByEmailReturnsOnCall[len(fake.getUserByEmailArgsForCall)]
+	fake.getUserByEmailArgsForCall = append(fake.getUserByEmailArgsForCall, struct {
+		arg1 context.Context
+		arg2 core.Actor
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.GetUserByEmailStub
+	fakeReturns := fake.getUserByEmailReturns
+	fake.recordInvocation("GetUserByEmail", []interface{}{arg1, arg2, arg3})
+	fake.getUserByEmailMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeUserAuthenticator) GetUserByEmailCallCount() int {
+	fake.getUserByEmailMutex.RLock()
+	defer fake.getUserByEmailMutex.RUnlock()
+	return len(fake.getUserByEmailArgsForCall)
+}
+
+func (fake *FakeUserAuthenticator) GetUserByEmailCalls(stub func(context.Context, core.Actor, string) (core.User, error)) {
+	fake.getUserByEmailMutex.Lock()
+	defer fake.getUserByEmailMutex.Unlock()
+	fake.GetUserByEmailStub = stub
+}
+
+func (fake *FakeUserAuthenticator) GetUserByEmailArgsForCall(i int) (context.Context, core.Actor, string) {
+	fake.getUserByEmailMutex.RLock()
+	defer fake.getUserByEmailMutex.RUnlock()
+	argsForCall := fake.getUserByEmailArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeUserAuthenticator) GetUserByEmailReturns(result1 core.User, result2 error) {
+	fake.getUserByEmailMutex.Lock()
+	defer fake.getUserByEmailMutex.Unlock()
+	fake.GetUserByEmailStub = nil
+	fake.getUserByEmailReturns = struct {
+		result1 core.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserAuthenticator) GetUserByEmailReturnsOnCall(i int, result1 core.User, result2 error) {
+	fake.getUserByEmailMutex.Lock()
+	defer fake.getUserByEmailMutex.Unlock()
+	fake.GetUserByEmailStub = nil
+	if fake.getUserByEmailReturnsOnCall == nil {
+		fake.getUserByEmailReturnsOnCall = make(map[int]struct {
+			result1 core.User
+			result2 error
+		})
+	}
+	fake.getUserByEmailReturnsOnCall[i] = struct {
+		result1 core.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserAuthenticator) GetUserByID(arg1 context.Context, arg2 core.Actor, arg3 uuid.UUID) (core.User, error) {
+	fake.getUserByIDMutex.Lock()
+	ret, specificReturn := fake.getUserByIDReturnsOnCall[len(fake.getUserByIDArgsForCall)]
+	fake.getUserByIDArgsForCall = append(fake.getUserByIDArgsForCall, struct {
+		arg1 context.Context
+		arg2 core.Actor
+		arg3 uuid.UUID
+	}{arg1, arg2, arg3})
+	stub := fake.GetUserByIDStub
+	fakeReturns := fake.getUserByIDReturns
+	fake.recordInvocation("GetUserByID", []interface{}{arg1, arg2, arg3})
+	fake.getUserByIDMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeUserAuthenticator) GetUserByIDCallCount() int {
+	fake.getUserByIDMutex.RLock()
+	defer fake.getUserByIDMutex.RUnlock()
+	return len(fake.getUserByIDArgsForCall)
+}
+
+func (fake *FakeUserAuthenticator) GetUserByIDCalls(stub func(context.Context, core.Actor, uuid.UUID) (core.User, error)) {
+	fake.getUserByIDMutex.Lock()
+	defer fake.getUserByIDMutex.Unlock()
+	fake.GetUserByIDStub = stub
+}
+
+func (fake *FakeUserAuthenticator) GetUserByIDArgsForCall(i int) (context.Context, core.Actor, uuid.UUID) {
+	fake.getUserByIDMutex.RLock()
+	defer fake.getUserByIDMutex.RUnlock()
+	argsForCall := fake.getUserByIDArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeUserAuthenticator) GetUserByIDReturns(result1 core.User, result2 error) {
+	fake.getUserByIDMutex.Lock()
+	defer fake.getUserByIDMutex.Unlock()
+	fake.GetUserByIDStub = nil
+	fake.getUserByIDReturns = struct {
+		result1 core.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserAuthenticator) GetUserByIDReturnsOnCall(i int, result1 core.User, result2 error) {
+	fake.getUserByIDMutex.Lock()
+	defer fake.getUserByIDMutex.Unlock()
+	fake.GetUserByIDStub = nil
+	if fake.getUserByIDReturnsOnCall == nil {
+		fake.getUserByIDReturnsOnCall = make(map[int]struct {
+			result1 core.User
+			result2 error
+		})
+	}
+	fake.getUserByIDReturnsOnCall[i] = struct {
+		result1 core.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserAuthenticator) ResetPassword(arg1 context.Context, arg2 core.Actor, arg3 uuid.UUID, arg4 string, arg5 string) (core.User, error) {
+	fake.resetPasswordMutex.Lock()
+	ret, specificReturn := fake.resetPasswordReturnsOnCall[len(fake.resetPasswordArgsForCall)]
+	fake.resetPasswordArgsForCall = append(fake.resetPasswordArgsForCall, struct {
+		arg1 context.Context
+		arg2 core.Actor
+		arg3 uuid.UUID
+		arg4 string
+		arg5 string
+	}{arg1, arg2, arg3, arg4, arg5})
+	stub := fake.ResetPasswordStub
+	fakeReturns := fake.resetPasswordReturns
+	fake.recordInvocation("ResetPassword", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.resetPasswordMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeUserAuthenticator) ResetPasswordCallCount() int {
+	fake.resetPasswordMutex.RLock()
+	defer fake.resetPasswordMutex.RUnlock()
+	return len(fake.resetPasswordArgsForCall)
+}
+
+func (fake *FakeUserAuthenticator) ResetPasswordCalls(stub func(context.Context, core.Actor, uuid.UUID, string, string) (core.User, error)) {
+	fake.resetPasswordMutex.Lock()
+	defer fake.resetPasswordMutex.Unlock()
+	fake.ResetPasswordStub = stub
+}
+
+func (fake *FakeUserAuthenticator) ResetPasswordArgsForCall(i int) (context.Context, core.Actor, uuid.UUID, string, string) {
+	fake.resetPasswordMutex.RLock()
+	defer fake.resetPasswordMutex.RUnlock()
+	argsForCall := fake.resetPasswordArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeUserAuthenticator) ResetPasswordReturns(result1 core.User, result2 error) {
+	fake.resetPasswordMutex.Lock()
+	defer fake.resetPasswordMutex.Unlock()
+	fake.ResetPasswordStub = nil
+	fake.resetPasswordReturns = struct {
+		result1 core.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserAuthenticator) ResetPasswordReturnsOnCall(i int, result1 core.User, result2 error) {
+	fake.resetPasswordMutex.Lock()
+	defer fake.resetPasswordMutex.Unlock()
+	fake.ResetPasswordStub = nil
+	if fake.resetPasswordReturnsOnCall == nil {
+		fake.resetPasswordReturnsOnCall = make(map[int]struct {
+			result1 core.User
+			result2 error
+		})
+	}
+	fake.resetPasswordReturnsOnCall[i] = struct {
+		result1 core.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserAuthenticator) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.authenticateMutex.RLock()
+	defer fake.authenticateMutex.RUnlock()
+	fake.getUserByEmailMutex.RLock()
+	defer fake.getUserByEmailMutex.RUnlock()
+	fake.getUserByIDMutex.RLock()
+	defer fake.getUserByIDMutex.RUnlock()
+	fake.resetPasswordMutex.RLock()
+	defer fake.resetPasswordMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeUserAuthenticator) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ auth.UserAuthenticator = new(FakeUserAuthenticator)
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+// Issuer - signs access tokens by the private key. Tokens are JWTs carrying the user ID as subject along with
+// the role of the user.
+type Issuer struct {
+	key      crypto.Signer
+	method   jwt.SigningMethod
+	keyID    string
+	name     string
+	audience string
+}
+
+// NewIssuer - name is the iss claim of issued tokens, audience is their aud claim
+func NewIssuer(key crypto.Signer, name, audience string) (*Issuer, error) {
+	method, err := signingMethod(key)
+	if err != nil {
+		return nil, err
+	}
+	keyID, err := publicKeyID(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &Issuer{
+		key:      key,
+		method:   method,
+		keyID:    keyID,
+		name:     name,
+		audience: audience,
+	}, nil
+}
+
+// Issue - signed access token of the user, valid until expiresAt
+func (i *Issuer) Issue(user core.User, expiresAt time.Time) (string, error) {
+	token := jwt.NewWithClaims(i.method, accessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Issuer:    i.name,
+			Audience:  jwt.ClaimStrings{i.audience},
+			Subject:   user.ID.String(),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		Role: user.Role,
+	})
+	// kid lets verifiers pick the right key once keys are rotated
+	token.Header["kid"] = i.keyID
+	return token.SignedString(i.key)
+}
+
+// KeySet - public key of the issuer, tokens it issues are verified by
+func (i *Issuer) KeySet() KeySet {
+	return KeySet{i.keyID: i.key.Public()}
+}
+
+// publicKeyID - digest of the public key, stable across restarts as long as the key stays the same
+func publicKeyID(public crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256(der)
+	return base64.RawURLEncoding.EncodeToString(digest[:12]), nil
+}
@@ -0,0 +1,185 @@
+package auth_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"com.user.com/user/internal/auth"
+	"com.user.com/user/internal/core"
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Load Signing Key", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "auth-keys")
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		_ = os.RemoveAll(dir)
+	})
+
+	writeKey := func(blockType string, der []byte) string {
+		path := filepath.Join(dir, "key.pem")
+		Expect(ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600)).To(Succeed())
+		return path
+	}
+
+	It("loads PKCS #8 key written by MarshalSigningKey", func() {
+		key, err := auth.GenerateSigningKey()
+		Expect(err).To(BeNil())
+		data, err := auth.MarshalSigningKey(key)
+		Expect(err).To(BeNil())
+		path := filepath.Join(dir, "key.pem")
+		Expect(ioutil.WriteFile(path, data, 0600)).To(Succeed())
+
+		loaded, err := auth.LoadSigningKey(path)
+		Expect(err).To(BeNil())
+		Expect(loaded.Public()).To(Equal(key.Public()))
+	})
+
+	It("loads PKCS #1 RSA key", func() {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).To(BeNil())
+		loaded, err := auth.LoadSigningKey(writeKey("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key)))
+		Expect(err).To(BeNil())
+		Expect(loaded.Public()).To(Equal(key.Public()))
+	})
+
+	It("loads SEC 1 EC key", func() {
+		key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		Expect(err).To(BeNil())
+		der, err := x509.MarshalECPrivateKey(key)
+		Expect(err).To(BeNil())
+		loaded, err := auth.LoadSigningKey(writeKey("EC PRIVATE KEY", der))
+		Expect(err).To(BeNil())
+		Expect(loaded.Public()).To(Equal(key.Public()))
+	})
+
+	It("fails when there is no private key", func() {
+		_, err := auth.LoadSigningKey(writeKey("PUBLIC KEY", []byte("not-a-key")))
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("fails when the file does not exist", func() {
+		_, err := auth.LoadSigningKey(filepath.Join(dir, "missing.pem"))
+		Expect(err).ToNot(BeNil())
+	})
+})
+
+// ownTokens - claims of tokens issued by this service
+var ownTokens = auth.VerifierConfig{Issuer: "users", Audience: "users"}
+
+var _ = Describe("Token Issuer", func() {
+	var (
+		key      crypto.Signer
+		issuer   *auth.Issuer
+		verifier *auth.Verifier
+		user     core.User
+	)
+
+	BeforeEach(func() {
+		var err error
+		key, err = auth.GenerateSigningKey()
+		Expect(err).To(BeNil())
+		issuer, err = auth.NewIssuer(key, "users", "users")
+		Expect(err).To(BeNil())
+		verifier = auth.NewVerifier(issuer.KeySet(), nil, ownTokens)
+		user = core.User{ID: uuid.New(), Role: core.RoleAdmin}
+	})
+
+	It("issues tokens identifying the user along with its role", func() {
+		token, err := issuer.Issue(user, time.Now().Add(time.Minute))
+		Expect(err).To(BeNil())
+		actor, err := verifier.Verify(context.Background(), token)
+		Expect(err).To(BeNil())
+		Expect(actor).To(Equal(core.Actor{ID: user.ID.String(), Type: core.ActorTypeUser, Role: core.RoleAdmin}))
+	})
+
+	It("signs tokens by every supported key type", func() {
+		_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+		Expect(err).To(BeNil())
+		rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).To(BeNil())
+		p521Key, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+		Expect(err).To(BeNil())
+
+		for _, key := range []crypto.Signer{rsaKey, p521Key, ed25519Key} {
+			issuer, err := auth.NewIssuer(key, "users", "users")
+			Expect(err).To(BeNil())
+			token, err := issuer.Issue(user, time.Now().Add(time.Minute))
+			Expect(err).To(BeNil())
+			actor, err := auth.NewVerifier(issuer.KeySet(), nil, ownTokens).Verify(context.Background(), token)
+			Expect(err).To(BeNil())
+			Expect(actor.ID).To(Equal(user.ID.String()))
+		}
+	})
+
+	It("rejects expired token", func() {
+		token, err := issuer.Issue(user, time.Now().Add(-time.Second))
+		Expect(err).To(BeNil())
+		_, err = verifier.Verify(context.Background(), token)
+		Expect(errors.Is(err, auth.ErrInvalidToken)).To(BeTrue())
+	})
+
+	It("rejects token signed by another key", func() {
+		otherKey, err := auth.GenerateSigningKey()
+		Expect(err).To(BeNil())
+		other, err := auth.NewIssuer(otherKey, "users", "users")
+		Expect(err).To(BeNil())
+		token, err := other.Issue(user, time.Now().Add(time.Minute))
+		Expect(err).To(BeNil())
+
+		_, err = verifier.Verify(context.Background(), token)
+		Expect(errors.Is(err, auth.ErrInvalidToken)).To(BeTrue())
+	})
+
+	It("rejects token of another issuer", func() {
+		other, err := auth.NewIssuer(key, "someone-else", "users")
+		Expect(err).To(BeNil())
+		token, err := other.Issue(user, time.Now().Add(time.Minute))
+		Expect(err).To(BeNil())
+
+		_, err = verifier.Verify(context.Background(), token)
+		Expect(errors.Is(err, auth.ErrInvalidToken)).To(BeTrue())
+	})
+
+	It("rejects token meant for another audience", func() {
+		other, err := auth.NewIssuer(key, "users", "billing")
+		Expect(err).To(BeNil())
+		token, err := other.Issue(user, time.Now().Add(time.Minute))
+		Expect(err).To(BeNil())
+
+		_, err = verifier.Verify(context.Background(), token)
+		Expect(errors.Is(err, auth.ErrInvalidToken)).To(BeTrue())
+	})
+
+	It("rejects unsigned token", func() {
+		token, err := issuer.Issue(user, time.Now().Add(time.Minute))
+		Expect(err).To(BeNil())
+		parts := strings.Split(token, ".")
+		// {"alg":"none","typ":"JWT"}
+		unsigned := "eyJhbGciOiJub25lIiwidHlwIjoiSldUIn0." + parts[1] + "."
+
+		_, err = verifier.Verify(context.Background(), unsigned)
+		Expect(errors.Is(err, auth.ErrInvalidToken)).To(BeTrue())
+	})
+})
@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// LoadSigningKey - reads the private key access tokens are signed by out of a PEM file. RSA, ECDSA (P-256, P-384,
+// P-521) and Ed25519 keys are supported, in PKCS #8, PKCS #1 or SEC 1 encoding.
+func LoadSigningKey(path string) (crypto.Signer, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("auth: failed to read signing key: %w", err)
+	}
+	return ParseSigningKey(data)
+}
+
+// ParseSigningKey - parses the first private key found in PEM data
+func ParseSigningKey(data []byte) (crypto.Signer, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, errors.New("auth: no private key found in PEM data")
+		}
+		switch block.Type {
+		case "PRIVATE KEY":
+			key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("auth: failed to parse PKCS #8 key: %w", err)
+			}
+			signer, ok := key.(crypto.Signer)
+			if !ok {
+				return nil, fmt.Errorf("auth: unsupported key type %T", key)
+			}
+			return signer, nil
+		case "RSA PRIVATE KEY":
+			key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("auth: failed to parse PKCS #1 key: %w", err)
+			}
+			return key, nil
+		case "EC PRIVATE KEY":
+			key, err := x509.ParseECPrivateKey(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("auth: failed to parse SEC 1 key: %w", err)
+			}
+			return key, nil
+		}
+		// Other blocks, e.g. EC PARAMETERS written by openssl, are skipped
+	}
+}
+
+// GenerateSigningKey - random ECDSA P-256 key, good for a single instance only, since tokens do not survive its restart
+func GenerateSigningKey() (crypto.Signer, error) {
+	return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+}
+
+// MarshalSigningKey - encodes the key as PKCS #8 PEM, the way LoadSigningKey reads it
+func MarshalSigningKey(key crypto.Signer) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// signingMethod - JWT algorithm matching the key
+func signingMethod(key crypto.Signer) (jwt.SigningMethod, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return jwt.SigningMethodRS256, nil
+	case *ecdsa.PrivateKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return jwt.SigningMethodES256, nil
+		case elliptic.P384():
+			return jwt.SigningMethodES384, nil
+		case elliptic.P521():
+			return jwt.SigningMethodES512, nil
+		}
+		return nil, fmt.Errorf("auth: unsupported curve %s", k.Curve.Params().Name)
+	case ed25519.PrivateKey:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("auth: unsupported key type %T", key)
+	}
+}
@@ -0,0 +1,312 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+//go:generate ~/go/bin/counterfeiter  . TokenStore
+//go:generate ~/go/bin/counterfeiter  . UserAuthenticator
+
+type TokenStore interface {
+	SaveRefreshToken(ctx context.Context, token core.RefreshToken) error
+	// GetRefreshToken - returns core.NotFoundError if there is no token with such hash
+	GetRefreshToken(ctx context.Context, tokenHash string) (core.RefreshToken, error)
+	// RotateRefreshToken - revokes the current token and stores the next one in the same transaction.
+	// Returns core.ConflictError if the current token has been revoked in the meantime.
+	RotateRefreshToken(ctx context.Context, currentID uuid.UUID, next core.RefreshToken) error
+	// RevokeTokenFamily - revokes all active tokens of the family
+	RevokeTokenFamily(ctx context.Context, familyID uuid.UUID) error
+
+	SavePasswordResetToken(ctx context.Context, token core.PasswordResetToken) error
+	// GetPasswordResetToken - returns core.NotFoundError if there is no such token which is neither used nor expired
+	GetPasswordResetToken(ctx context.Context, tokenHash string) (core.PasswordResetToken, error)
+}
+
+// UserAuthenticator - users tokens are issued for and whose passwords are reset
+type UserAuthenticator interface {
+	// Authenticate - returns core.UnauthorizedError if there is no user with such email and password
+	Authenticate(ctx context.Context, email, password string) (core.User, error)
+	GetUserByID(ctx context.Context, actor core.Actor, id uuid.UUID) (core.User, error)
+	GetUserByEmail(ctx context.Context, actor core.Actor, email string) (core.User, error)
+	// ResetPassword - uses up the password reset token with such hash and all other ones of the user, replaces the
+	// password and ends all sessions of the user at once. The change is published as user password changed event.
+	// Returns core.NotFoundError if the token is not usable.
+	ResetPassword(ctx context.Context, actor core.Actor, id uuid.UUID, tokenHash, password string) (core.User, error)
+}
+
+// Mailer - sends mails to users
+type Mailer interface {
+	Send(ctx context.Context, mail core.Mail) error
+}
+
+type Config struct {
+	// AccessTokenTTL - access tokens cannot be revoked, so they should be short lived
+	AccessTokenTTL time.Duration
+	// RefreshTokenTTL - how long a session lasts without being refreshed
+	RefreshTokenTTL time.Duration
+	// PasswordResetTTL - how long a password reset token is valid
+	PasswordResetTTL time.Duration
+	// PasswordResetPage - resets the password, token is added to its query. Without it mails carry the token only.
+	PasswordResetPage *url.URL
+}
+
+var DefaultConfig = Config{
+	AccessTokenTTL:   15 * time.Minute,
+	RefreshTokenTTL:  30 * 24 * time.Hour,
+	PasswordResetTTL: time.Hour,
+}
+
+const (
+	tokenSize = 32
+	// mailTimeout - mails are sent in background, hence their own timeout
+	mailTimeout = 30 * time.Second
+)
+
+var (
+	errInvalidRefreshToken = &core.UnauthorizedError{Message: "refresh token is invalid or expired"}
+	errInvalidResetToken   = &core.ValidationError{Field: "token", Message: "password reset token is invalid or expired"}
+)
+
+// Manager - issues access and refresh tokens. Refresh tokens are rotated, every one of them can be used once.
+// Using a rotated token again revokes the whole session, since either the client or an attacker holds a stolen copy.
+type Manager struct {
+	users  UserAuthenticator
+	tokens TokenStore
+	issuer *Issuer
+	mailer Mailer
+	config Config
+}
+
+func NewManager(users UserAuthenticator, tokens TokenStore, issuer *Issuer, mailer Mailer, config Config) *Manager {
+	return &Manager{
+		users:  users,
+		tokens: tokens,
+		issuer: issuer,
+		mailer: mailer,
+		config: config,
+	}
+}
+
+// Login - verifies the credentials and starts a new session
+func (m *Manager) Login(ctx context.Context, email, password string) (core.TokenPair, error) {
+	user, err := m.users.Authenticate(ctx, email, password)
+	if err != nil {
+		return core.TokenPair{}, err
+	}
+	refreshToken, stored, err := m.newRefreshToken(user.ID, uuid.New())
+	if err != nil {
+		return core.TokenPair{}, err
+	}
+	err = m.tokens.SaveRefreshToken(ctx, stored)
+	if err != nil {
+		return core.TokenPair{}, err
+	}
+	return m.tokenPair(user, refreshToken, stored)
+}
+
+// Refresh - exchanges the refresh token for a new pair of tokens. Returns core.UnauthorizedError if the token
+// is unknown, expired or revoked, or if its user does not exist anymore.
+func (m *Manager) Refresh(ctx context.Context, refreshToken string) (core.TokenPair, error) {
+	current, err := m.tokens.GetRefreshToken(ctx, hashToken(refreshToken))
+	var notFoundErr *core.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		return core.TokenPair{}, errInvalidRefreshToken
+	}
+	if err != nil {
+		return core.TokenPair{}, err
+	}
+	if !current.RevokedAt.IsZero() {
+		m.revokeReused(ctx, current)
+		return core.TokenPair{}, errInvalidRefreshToken
+	}
+	if !current.Active(time.Now()) {
+		return core.TokenPair{}, errInvalidRefreshToken
+	}
+	// Role of the user may have changed since the last refresh
+	user, err := m.users.GetUserByID(ctx, core.SystemActor, current.UserID)
+	if errors.As(err, &notFoundErr) {
+		return core.TokenPair{}, errInvalidRefreshToken
+	}
+	if err != nil {
+		return core.TokenPair{}, err
+	}
+
+	nextToken, next, err := m.newRefreshToken(current.UserID, current.FamilyID)
+	if err != nil {
+		return core.TokenPair{}, err
+	}
+	err = m.tokens.RotateRefreshToken(ctx, current.ID, next)
+	var conflictErr *core.ConflictError
+	if errors.As(err, &conflictErr) {
+		// Concurrent refresh has rotated the token first, which is reuse as well
+		m.revokeReused(ctx, current)
+		return core.TokenPair{}, errInvalidRefreshToken
+	}
+	if err != nil {
+		return core.TokenPair{}, err
+	}
+	return m.tokenPair(user, nextToken, next)
+}
+
+// Logout - ends the session of the refresh token. Unknown, expired and revoked tokens have no session to end,
+// so they are ignored.
+func (m *Manager) Logout(ctx context.Context, refreshToken string) error {
+	current, err := m.tokens.GetRefreshToken(ctx, hashToken(refreshToken))
+	var notFoundErr *core.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return m.tokens.RevokeTokenFamily(ctx, current.FamilyID)
+}
+
+// RequestPasswordReset - mails a password reset token to the user with such email. Unknown emails are ignored and
+// the mail is sent in background, so neither the result nor the response time tell whether the email exists.
+func (m *Manager) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := m.users.GetUserByEmail(ctx, core.SystemActor, email)
+	var notFoundErr *core.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	token, err := generateToken()
+	if err != nil {
+		return err
+	}
+	err = m.tokens.SavePasswordResetToken(ctx, core.PasswordResetToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(m.config.PasswordResetTTL).UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	go m.sendPasswordReset(user, token)
+	return nil
+}
+
+// ResetPassword - replaces the password of the user the token has been mailed to. Token works once, it expires and
+// it is useless once any other token of the user has been used. All sessions of the user are ended, since whoever
+// has known the old password may hold one. Token is used up, the password replaced and the sessions ended at once,
+// so concurrent resets by the same token replace the password only once and a failed reset can be retried.
+func (m *Manager) ResetPassword(ctx context.Context, token, password string) error {
+	if password == "" {
+		return &core.ValidationError{Field: "password", Message: "password is required"}
+	}
+	tokenHash := hashToken(token)
+	reset, err := m.tokens.GetPasswordResetToken(ctx, tokenHash)
+	var notFoundErr *core.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		return errInvalidResetToken
+	}
+	if err != nil {
+		return err
+	}
+	// Token proves that the user is the one resetting its password
+	actor := core.Actor{ID: reset.UserID.String(), Type: core.ActorTypeUser}
+	_, err = m.users.ResetPassword(ctx, actor, reset.UserID, tokenHash, password)
+	if errors.As(err, &notFoundErr) {
+		// Either the token has been used in the meantime or the user has been deleted
+		return errInvalidResetToken
+	}
+	return err
+}
+
+func (m *Manager) sendPasswordReset(user core.User, token string) {
+	ctx, cancel := context.WithTimeout(context.Background(), mailTimeout)
+	defer cancel()
+	body := fmt.Sprintf("Hi %s,\n\nreset of your password has been requested.", user.FirstName)
+	if m.config.PasswordResetPage != nil {
+		page := *m.config.PasswordResetPage
+		query := page.Query()
+		query.Set("token", token)
+		page.RawQuery = query.Encode()
+		body += fmt.Sprintf(" Choose a new password by opening\n\n%s\n", page.String())
+	} else {
+		body += fmt.Sprintf(" Choose a new password by sending password reset token\n\n%s\n", token)
+	}
+	body += fmt.Sprintf("\nThe token expires in %s. Ignore this mail if you have not requested the reset.\n", m.config.PasswordResetTTL)
+	err := m.mailer.Send(ctx, core.Mail{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body:    body,
+	})
+	if err != nil {
+		logrus.WithError(err).
+			WithField("user_id", user.ID).
+			Warn("failed to send password reset")
+	}
+}
+
+// revokeReused - ends the session of the reused token. Refresh fails anyway, so failure is only logged.
+func (m *Manager) revokeReused(ctx context.Context, reused core.RefreshToken) {
+	logger := logrus.WithContext(ctx).
+		WithField("user_id", reused.UserID).
+		WithField("family_id", reused.FamilyID)
+	logger.Warn("refresh token reused, revoking the session")
+	err := m.tokens.RevokeTokenFamily(ctx, reused.FamilyID)
+	if err != nil {
+		logger.WithError(err).Error("failed to revoke the session")
+	}
+}
+
+func (m *Manager) newRefreshToken(userID, familyID uuid.UUID) (string, core.RefreshToken, error) {
+	token, err := generateToken()
+	if err != nil {
+		return "", core.RefreshToken{}, err
+	}
+	return token, core.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(m.config.RefreshTokenTTL).UTC(),
+	}, nil
+}
+
+func (m *Manager) tokenPair(user core.User, refreshToken string, stored core.RefreshToken) (core.TokenPair, error) {
+	expiresAt := time.Now().Add(m.config.AccessTokenTTL).UTC()
+	accessToken, err := m.issuer.Issue(user, expiresAt)
+	if err != nil {
+		return core.TokenPair{}, err
+	}
+	return core.TokenPair{
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  expiresAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: stored.ExpiresAt,
+	}, nil
+}
+
+// generateToken - opaque random token, e.g. refresh or password reset one
+func generateToken() (string, error) {
+	secret := make([]byte, tokenSize)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// hashToken - tokens are random, hence a plain digest is enough to keep stolen db rows useless
+func hashToken(token string) string {
+	digest := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(digest[:])
+}
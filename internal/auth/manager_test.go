@@ -0,0 +1,368 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"com.user.com/user/internal/auth"
+	"com.user.com/user/internal/auth/authfakes"
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/mailer"
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Auth Manager", func() {
+	var (
+		users    *authfakes.FakeUserAuthenticator
+		tokens   *authfakes.FakeTokenStore
+		issuer   *auth.Issuer
+		verifier *auth.Verifier
+		mails    *mailer.Memory
+		manager  *auth.Manager
+		user     core.User
+		ctx      context.Context
+	)
+
+	BeforeEach(func() {
+		users = &authfakes.FakeUserAuthenticator{}
+		tokens = &authfakes.FakeTokenStore{}
+		key, err := auth.GenerateSigningKey()
+		Expect(err).To(BeNil())
+		issuer, err = auth.NewIssuer(key, "users", "users")
+		Expect(err).To(BeNil())
+		verifier = auth.NewVerifier(issuer.KeySet(), nil, ownTokens)
+		mails = mailer.NewMemory()
+		manager = auth.NewManager(users, tokens, issuer, mails, auth.DefaultConfig)
+		user = core.User{ID: uuid.New(), Email: "test@faceit.com", Role: core.RoleUser}
+		users.AuthenticateReturns(user, nil)
+		promoted := user
+		promoted.Role = core.RoleAdmin
+		users.GetUserByIDReturns(promoted, nil)
+		ctx = context.Background()
+	})
+
+	Context("Login", func() {
+		var (
+			pair core.TokenPair
+			err  error
+		)
+		JustBeforeEach(func() {
+			pair, err = manager.Login(ctx, "test@faceit.com", "secret")
+		})
+
+		Context("With invalid credentials", func() {
+			BeforeEach(func() {
+				users.AuthenticateReturns(core.User{}, &core.UnauthorizedError{Message: "invalid email or password"})
+			})
+			It("fails with unauthorized error and starts no session", func() {
+				var unauthorizedErr *core.UnauthorizedError
+				Expect(errors.As(err, &unauthorizedErr)).To(BeTrue())
+				Expect(tokens.SaveRefreshTokenCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("With valid credentials", func() {
+			It("issues access token of the user", func() {
+				Expect(err).To(BeNil())
+				actor, err := verifier.Verify(context.Background(), pair.AccessToken)
+				Expect(err).To(BeNil())
+				Expect(actor.ID).To(Equal(user.ID.String()))
+				Expect(pair.AccessTokenExpiresAt).To(BeTemporally("~", time.Now().Add(auth.DefaultConfig.AccessTokenTTL), time.Second))
+			})
+			It("stores only the hash of refresh token", func() {
+				Expect(tokens.SaveRefreshTokenCallCount()).To(Equal(1))
+				_, stored := tokens.SaveRefreshTokenArgsForCall(0)
+				Expect(stored.UserID).To(Equal(user.ID))
+				Expect(stored.FamilyID).ToNot(Equal(uuid.Nil))
+				Expect(stored.TokenHash).To(HaveLen(64))
+				Expect(stored.TokenHash).ToNot(ContainSubstring(pair.RefreshToken))
+				Expect(stored.ExpiresAt).To(Equal(pair.RefreshTokenExpiresAt))
+			})
+		})
+
+		Context("When refresh token cannot be stored", func() {
+			BeforeEach(func() {
+				tokens.SaveRefreshTokenReturns(errors.New("store-error"))
+			})
+			It("fails", func() {
+				Expect(err).To(MatchError("store-error"))
+			})
+		})
+	})
+
+	Context("Refresh", func() {
+		var (
+			refreshToken string
+			current      core.RefreshToken
+			pair         core.TokenPair
+			err          error
+		)
+		BeforeEach(func() {
+			issued, err := manager.Login(ctx, "test@faceit.com", "secret")
+			Expect(err).To(BeNil())
+			refreshToken = issued.RefreshToken
+			_, current = tokens.SaveRefreshTokenArgsForCall(0)
+			tokens.GetRefreshTokenStub = func(_ context.Context, tokenHash string) (core.RefreshToken, error) {
+				if tokenHash != current.TokenHash {
+					return core.RefreshToken{}, &core.NotFoundError{Entity: "refresh token"}
+				}
+				return current, nil
+			}
+		})
+		JustBeforeEach(func() {
+			pair, err = manager.Refresh(ctx, refreshToken)
+		})
+
+		Context("With unknown token", func() {
+			BeforeEach(func() {
+				refreshToken = "unknown-token"
+			})
+			It("fails with unauthorized error", func() {
+				var unauthorizedErr *core.UnauthorizedError
+				Expect(errors.As(err, &unauthorizedErr)).To(BeTrue())
+			})
+		})
+
+		Context("With issued token", func() {
+			It("rotates the token within the same session", func() {
+				Expect(err).To(BeNil())
+				Expect(tokens.RotateRefreshTokenCallCount()).To(Equal(1))
+				_, currentID, next := tokens.RotateRefreshTokenArgsForCall(0)
+				Expect(currentID).To(Equal(current.ID))
+				Expect(next.FamilyID).To(Equal(current.FamilyID))
+				Expect(next.TokenHash).ToNot(Equal(current.TokenHash))
+				Expect(pair.RefreshToken).ToNot(Equal(refreshToken))
+				actor, err := verifier.Verify(context.Background(), pair.AccessToken)
+				Expect(err).To(BeNil())
+				Expect(actor.ID).To(Equal(user.ID.String()))
+			})
+			It("issues access token with the current role of the user", func() {
+				_, actor, _ := users.GetUserByIDArgsForCall(0)
+				Expect(actor).To(Equal(core.SystemActor))
+				accessActor, err := verifier.Verify(context.Background(), pair.AccessToken)
+				Expect(err).To(BeNil())
+				Expect(accessActor.Role).To(Equal(core.RoleAdmin))
+			})
+
+			Context("When the token has expired", func() {
+				BeforeEach(func() {
+					current.ExpiresAt = time.Now().Add(-time.Second)
+				})
+				It("fails with unauthorized error", func() {
+					var unauthorizedErr *core.UnauthorizedError
+					Expect(errors.As(err, &unauthorizedErr)).To(BeTrue())
+					Expect(tokens.RotateRefreshTokenCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("When the token has already been rotated", func() {
+				BeforeEach(func() {
+					current.RevokedAt = time.Now().Add(-time.Minute)
+				})
+				It("revokes the whole session", func() {
+					var unauthorizedErr *core.UnauthorizedError
+					Expect(errors.As(err, &unauthorizedErr)).To(BeTrue())
+					Expect(tokens.RevokeTokenFamilyCallCount()).To(Equal(1))
+					_, familyID := tokens.RevokeTokenFamilyArgsForCall(0)
+					Expect(familyID).To(Equal(current.FamilyID))
+				})
+			})
+
+			Context("When concurrent refresh rotates the token first", func() {
+				BeforeEach(func() {
+					tokens.RotateRefreshTokenReturns(&core.ConflictError{Message: "refresh token has already been revoked"})
+				})
+				It("revokes the whole session", func() {
+					var unauthorizedErr *core.UnauthorizedError
+					Expect(errors.As(err, &unauthorizedErr)).To(BeTrue())
+					Expect(tokens.RevokeTokenFamilyCallCount()).To(Equal(1))
+				})
+			})
+
+			Context("When the user does not exist anymore", func() {
+				BeforeEach(func() {
+					users.GetUserByIDReturns(core.User{}, &core.NotFoundError{Entity: "user"})
+				})
+				It("fails with unauthorized error", func() {
+					var unauthorizedErr *core.UnauthorizedError
+					Expect(errors.As(err, &unauthorizedErr)).To(BeTrue())
+					Expect(tokens.RotateRefreshTokenCallCount()).To(Equal(0))
+				})
+			})
+		})
+	})
+
+	Context("Logout", func() {
+		var (
+			stored core.RefreshToken
+			err    error
+		)
+		BeforeEach(func() {
+			stored = core.RefreshToken{ID: uuid.New(), FamilyID: uuid.New()}
+			tokens.GetRefreshTokenReturns(stored, nil)
+		})
+		JustBeforeEach(func() {
+			err = manager.Logout(ctx, "refresh-token")
+		})
+
+		It("revokes the session of the token", func() {
+			Expect(err).To(BeNil())
+			Expect(tokens.RevokeTokenFamilyCallCount()).To(Equal(1))
+			_, familyID := tokens.RevokeTokenFamilyArgsForCall(0)
+			Expect(familyID).To(Equal(stored.FamilyID))
+		})
+
+		Context("With unknown token", func() {
+			BeforeEach(func() {
+				tokens.GetRefreshTokenReturns(core.RefreshToken{}, &core.NotFoundError{Entity: "refresh token"})
+			})
+			It("succeeds without revoking anything", func() {
+				Expect(err).To(BeNil())
+				Expect(tokens.RevokeTokenFamilyCallCount()).To(Equal(0))
+			})
+		})
+	})
+
+	Context("RequestPasswordReset", func() {
+		var err error
+		BeforeEach(func() {
+			users.GetUserByEmailReturns(user, nil)
+		})
+		JustBeforeEach(func() {
+			err = manager.RequestPasswordReset(ctx, user.Email)
+		})
+
+		It("stores only the hash of the token and mails the token", func() {
+			Expect(err).To(BeNil())
+			Expect(tokens.SavePasswordResetTokenCallCount()).To(Equal(1))
+			_, stored := tokens.SavePasswordResetTokenArgsForCall(0)
+			Expect(stored.UserID).To(Equal(user.ID))
+			Expect(stored.TokenHash).To(HaveLen(64))
+			Expect(stored.ExpiresAt).To(BeTemporally("~", time.Now().Add(auth.DefaultConfig.PasswordResetTTL), time.Second))
+
+			Eventually(mails.Sent).Should(HaveLen(1))
+			mail := mails.Sent()[0]
+			Expect(mail.To).To(Equal(user.Email))
+			Expect(mail.Body).ToNot(ContainSubstring(stored.TokenHash))
+		})
+
+		Context("With unknown email", func() {
+			BeforeEach(func() {
+				users.GetUserByEmailReturns(core.User{}, &core.NotFoundError{Entity: "user", Key: user.Email})
+			})
+			It("succeeds without mailing anything", func() {
+				Expect(err).To(BeNil())
+				Expect(tokens.SavePasswordResetTokenCallCount()).To(Equal(0))
+				Consistently(mails.Sent, 100*time.Millisecond).Should(BeEmpty())
+			})
+		})
+
+		Context("When mail cannot be sent", func() {
+			BeforeEach(func() {
+				mails.FailWith(errors.New("smtp-error"))
+			})
+			It("succeeds", func() {
+				Expect(err).To(BeNil())
+			})
+		})
+	})
+
+	Context("ResetPassword", func() {
+		var (
+			reset    core.PasswordResetToken
+			password string
+			err      error
+		)
+		BeforeEach(func() {
+			password = "new-secret"
+			reset = core.PasswordResetToken{ID: uuid.New(), UserID: user.ID}
+			tokens.GetPasswordResetTokenReturns(reset, nil)
+			users.ResetPasswordReturns(user, nil)
+		})
+		JustBeforeEach(func() {
+			err = manager.ResetPassword(ctx, "reset-token", password)
+		})
+
+		It("resets the password as the user of the token", func() {
+			Expect(err).To(BeNil())
+			_, tokenHash := tokens.GetPasswordResetTokenArgsForCall(0)
+			Expect(tokenHash).To(HaveLen(64))
+			Expect(users.ResetPasswordCallCount()).To(Equal(1))
+			_, actor, id, usedHash, newPassword := users.ResetPasswordArgsForCall(0)
+			Expect(actor.ID).To(Equal(user.ID.String()))
+			Expect(actor.Type).To(Equal(core.ActorTypeUser))
+			Expect(id).To(Equal(user.ID))
+			Expect(usedHash).To(Equal(tokenHash))
+			Expect(newPassword).To(Equal(password))
+		})
+
+		Context("When the token has been used in the meantime", func() {
+			BeforeEach(func() {
+				users.ResetPasswordReturns(core.User{}, &core.NotFoundError{Entity: "password reset token"})
+			})
+			It("fails with validation error", func() {
+				var validationErr *core.ValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				Expect(validationErr.Field).To(Equal("token"))
+			})
+		})
+
+		Context("When the token is confirmed twice", func() {
+			BeforeEach(func() {
+				users.ResetPasswordReturnsOnCall(1, core.User{}, &core.NotFoundError{Entity: "password reset token"})
+			})
+			It("rejects the second confirmation", func() {
+				Expect(err).To(BeNil())
+				err = manager.ResetPassword(ctx, "reset-token", "other-secret")
+				var validationErr *core.ValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				Expect(validationErr.Field).To(Equal("token"))
+			})
+		})
+
+		Context("When the password cannot be replaced", func() {
+			BeforeEach(func() {
+				users.ResetPasswordReturns(core.User{}, &core.PreconditionFailedError{Message: "user has been modified in the meantime"})
+			})
+			It("fails, so the reset can be retried with the same token", func() {
+				Expect(err).To(BeAssignableToTypeOf(&core.PreconditionFailedError{}))
+			})
+		})
+
+		Context("With used, expired or unknown token", func() {
+			BeforeEach(func() {
+				tokens.GetPasswordResetTokenReturns(core.PasswordResetToken{}, &core.NotFoundError{Entity: "password reset token"})
+			})
+			It("fails with validation error", func() {
+				var validationErr *core.ValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				Expect(validationErr.Field).To(Equal("token"))
+				Expect(users.ResetPasswordCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("Without password", func() {
+			BeforeEach(func() {
+				password = ""
+			})
+			It("fails with validation error and keeps the token", func() {
+				var validationErr *core.ValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				Expect(tokens.GetPasswordResetTokenCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("When the user does not exist anymore", func() {
+			BeforeEach(func() {
+				users.ResetPasswordReturns(core.User{}, &core.NotFoundError{Entity: "user", Key: user.ID.String()})
+			})
+			It("fails with validation error", func() {
+				var validationErr *core.ValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+			})
+		})
+	})
+})
@@ -0,0 +1,12 @@
+package store
+
+import "com.user.com/user/internal/dberror"
+
+// translateError - converts db specific errors into core error types, e.g. violation of refresh_tokens_token_hash_key
+// into conflict on token
+var translateError = dberror.Translator{
+	Entity: "token",
+	Fields: []dberror.Field{
+		{Constraint: "token_hash", Name: "token"},
+	},
+}.Translate
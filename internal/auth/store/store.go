@@ -0,0 +1,145 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"com.user.com/user/internal/core"
+	"github.com/google/uuid"
+)
+
+const (
+	refreshTokenColumns   = `id, user_id, family_id, token_hash, expires_at, created_at, revoked_at`
+	storeRefreshTokenStmt = `INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, expires_at, created_at) VALUES ($1, $2, $3, $4, $5, now())`
+	getRefreshTokenStmt   = `SELECT ` + refreshTokenColumns + ` FROM refresh_tokens WHERE token_hash=$1`
+	revokeTokenStmt       = `UPDATE refresh_tokens SET revoked_at=now() WHERE id=$1 AND revoked_at IS NULL`
+	revokeFamilyStmt      = `UPDATE refresh_tokens SET revoked_at=now() WHERE family_id=$1 AND revoked_at IS NULL`
+
+	storePasswordResetTokenStmt = `INSERT INTO password_reset_tokens (id, user_id, token_hash, expires_at, created_at) VALUES ($1, $2, $3, $4, now())`
+	getPasswordResetTokenStmt   = `SELECT id, user_id, token_hash, expires_at, created_at FROM password_reset_tokens WHERE token_hash=$1 AND used_at IS NULL AND expires_at > now()`
+)
+
+// Store - refresh and password reset tokens in db. Password reset tokens are used up by the user store, along with
+// the password being replaced.
+type Store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{
+		db: db,
+	}
+}
+
+func (s *Store) SaveRefreshToken(ctx context.Context, token core.RefreshToken) error {
+	return translateError(saveRefreshToken(ctx, s.db, token))
+}
+
+// GetRefreshToken - returns core.NotFoundError if there is no token with such hash
+func (s *Store) GetRefreshToken(ctx context.Context, tokenHash string) (core.RefreshToken, error) {
+	var (
+		token     core.RefreshToken
+		revokedAt sql.NullTime
+	)
+	err := s.db.QueryRowContext(ctx, getRefreshTokenStmt, tokenHash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.FamilyID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.CreatedAt,
+		&revokedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Hash is not logged, it is as good as the token for looking it up
+		return core.RefreshToken{}, &core.NotFoundError{Entity: "refresh token"}
+	}
+	if err != nil {
+		return core.RefreshToken{}, translateError(err)
+	}
+	token.RevokedAt = revokedAt.Time
+	return token, nil
+}
+
+// RotateRefreshToken - revokes the current token and stores the next one in the same transaction.
+// Returns core.ConflictError if the current token has been revoked in the meantime.
+func (s *Store) RotateRefreshToken(ctx context.Context, currentID uuid.UUID, next core.RefreshToken) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return translateError(err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	res, err := tx.ExecContext(ctx, revokeTokenStmt, currentID)
+	if err != nil {
+		return translateError(err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return translateError(err)
+	}
+	if affected == 0 {
+		return &core.ConflictError{Message: "refresh token has already been revoked"}
+	}
+	err = saveRefreshToken(ctx, tx, next)
+	if err != nil {
+		return translateError(err)
+	}
+	return translateError(tx.Commit())
+}
+
+// RevokeTokenFamily - revokes all active tokens of the family
+func (s *Store) RevokeTokenFamily(ctx context.Context, familyID uuid.UUID) error {
+	_, err := s.db.ExecContext(ctx, revokeFamilyStmt, familyID)
+	return translateError(err)
+}
+
+func (s *Store) SavePasswordResetToken(ctx context.Context, token core.PasswordResetToken) error {
+	_, err := s.db.ExecContext(ctx,
+		storePasswordResetTokenStmt,
+		token.ID,
+		token.UserID,
+		token.TokenHash,
+		token.ExpiresAt,
+	)
+	return translateError(err)
+}
+
+// GetPasswordResetToken - returns core.NotFoundError if there is no unused and unexpired token with such hash
+func (s *Store) GetPasswordResetToken(ctx context.Context, tokenHash string) (core.PasswordResetToken, error) {
+	var token core.PasswordResetToken
+	err := s.db.QueryRowContext(ctx, getPasswordResetTokenStmt, tokenHash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Hash is not logged, it is as good as the token for looking it up
+		return core.PasswordResetToken{}, &core.NotFoundError{Entity: "password reset token"}
+	}
+	if err != nil {
+		return core.PasswordResetToken{}, translateError(err)
+	}
+	return token, nil
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func saveRefreshToken(ctx context.Context, db execer, token core.RefreshToken) error {
+	_, err := db.ExecContext(ctx,
+		storeRefreshTokenStmt,
+		token.ID,
+		token.UserID,
+		token.FamilyID,
+		token.TokenHash,
+		token.ExpiresAt,
+	)
+	return err
+}
@@ -0,0 +1,325 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+
+	"com.user.com/user/internal/core"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidToken = errors.New("access token is invalid")
+
+// accessClaims - claims of access tokens. Role is the role of the user at the time the token has been issued, it is
+// trusted only in tokens of this service.
+type accessClaims struct {
+	jwt.RegisteredClaims
+	Role core.Role `json:"role,omitempty"`
+}
+
+// KeySet - public keys access tokens are verified by, keyed by their kid. Key with empty kid verifies tokens
+// regardless of their kid.
+type KeySet map[string]crypto.PublicKey
+
+// Merge - keys of both sets, keys of the other set win
+func (k KeySet) Merge(other KeySet) KeySet {
+	merged := make(KeySet, len(k)+len(other))
+	for kid, key := range k {
+		merged[kid] = key
+	}
+	for kid, key := range other {
+		merged[kid] = key
+	}
+	return merged
+}
+
+// UserFinder - users of tokens issued elsewhere, their roles are looked up rather than taken from the tokens
+type UserFinder interface {
+	GetUserByID(ctx context.Context, actor core.Actor, id uuid.UUID) (core.User, error)
+}
+
+// VerifierConfig - claims tokens have to carry along with keys of tokens issued elsewhere
+type VerifierConfig struct {
+	// Issuer - iss claim of tokens issued by this service
+	Issuer string
+	// Audience - aud claim of every token, tokens meant for other services are rejected
+	Audience string
+	// ExternalIssuer - iss claim of tokens issued elsewhere, e.g. by an identity provider
+	ExternalIssuer string
+	// ExternalKeys - keys of tokens issued elsewhere, none are accepted without them
+	ExternalKeys KeySet
+}
+
+// Verifier - verifies access tokens and tells who they have been issued for
+type Verifier struct {
+	keys   KeySet
+	users  UserFinder
+	config VerifierConfig
+}
+
+// NewVerifier - keys verify tokens issued by this service, only those are trusted to carry the role of the user
+func NewVerifier(keys KeySet, users UserFinder, config VerifierConfig) *Verifier {
+	return &Verifier{
+		keys:   keys,
+		users:  users,
+		config: config,
+	}
+}
+
+// Verify - returns the user the token has been issued for as an actor. Any failure of the token, including expiry,
+// is ErrInvalidToken. Users of tokens issued elsewhere have to exist, their role is the one they have here.
+func (v *Verifier) Verify(ctx context.Context, token string) (core.Actor, error) {
+	var (
+		claims   accessClaims
+		external bool
+	)
+	_, err := jwt.ParseWithClaims(token, &claims, func(token *jwt.Token) (interface{}, error) {
+		var (
+			key crypto.PublicKey
+			err error
+		)
+		key, external, err = v.key(token)
+		return key, err
+	})
+	if err != nil {
+		return core.Actor{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	issuer := v.config.Issuer
+	if external {
+		issuer = v.config.ExternalIssuer
+	}
+	if !claims.VerifyIssuer(issuer, true) {
+		return core.Actor{}, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, claims.Issuer)
+	}
+	if !claims.VerifyAudience(v.config.Audience, true) {
+		return core.Actor{}, fmt.Errorf("%w: unexpected audience %q", ErrInvalidToken, claims.Audience)
+	}
+	// Tokens without expiry would be valid forever
+	if claims.ExpiresAt == nil {
+		return core.Actor{}, fmt.Errorf("%w: missing expiry", ErrInvalidToken)
+	}
+	if claims.Subject == "" {
+		return core.Actor{}, fmt.Errorf("%w: missing subject", ErrInvalidToken)
+	}
+	actor := core.Actor{
+		ID:   claims.Subject,
+		Type: core.ActorTypeUser,
+		Role: claims.Role,
+	}
+	if external {
+		actor.Role, err = v.role(ctx, claims.Subject)
+		if err != nil {
+			return core.Actor{}, err
+		}
+	}
+	return actor, nil
+}
+
+// role - role of the user of a token issued elsewhere, whatever the token claims
+func (v *Verifier) role(ctx context.Context, subject string) (core.Role, error) {
+	id, err := uuid.Parse(subject)
+	if err != nil {
+		return "", fmt.Errorf("%w: subject is not a user", ErrInvalidToken)
+	}
+	user, err := v.users.GetUserByID(ctx, core.SystemActor, id)
+	var notFoundErr *core.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		return "", fmt.Errorf("%w: unknown user", ErrInvalidToken)
+	}
+	if err != nil {
+		return "", err
+	}
+	return user.Role, nil
+}
+
+// key - picks the key by kid header of the token, keys of this service go first. Algorithm of the token has to
+// match the key, so a public key cannot be abused as HMAC secret.
+func (v *Verifier) key(token *jwt.Token) (crypto.PublicKey, bool, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, external := lookupKey(v.keys, kid), false
+	if key == nil {
+		key, external = lookupKey(v.config.ExternalKeys, kid), true
+	}
+	if key == nil {
+		return nil, false, fmt.Errorf("unknown key %q", kid)
+	}
+	if !keyAccepts(key, token.Method) {
+		return nil, false, fmt.Errorf("algorithm %s does not match the key", token.Method.Alg())
+	}
+	return key, external, nil
+}
+
+// lookupKey - key of the kid or the one verifying tokens regardless of their kid, nil if there is neither
+func lookupKey(keys KeySet, kid string) crypto.PublicKey {
+	if key, ok := keys[kid]; ok {
+		return key
+	}
+	return keys[""]
+}
+
+func keyAccepts(key crypto.PublicKey, method jwt.SigningMethod) bool {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		_, isRSA := method.(*jwt.SigningMethodRSA)
+		_, isPSS := method.(*jwt.SigningMethodRSAPSS)
+		return isRSA || isPSS
+	case *ecdsa.PublicKey:
+		m, ok := method.(*jwt.SigningMethodECDSA)
+		return ok && m.CurveBits == k.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		_, ok := method.(*jwt.SigningMethodEd25519)
+		return ok
+	default:
+		return false
+	}
+}
+
+// LoadPublicKey - reads a static verification key out of a PEM file, either PKIX public key or certificate.
+// The key verifies tokens regardless of their kid.
+func LoadPublicKey(path string) (KeySet, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("auth: failed to read public key: %w", err)
+	}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, errors.New("auth: no public key found in PEM data")
+		}
+		switch block.Type {
+		case "PUBLIC KEY":
+			key, err := x509.ParsePKIXPublicKey(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("auth: failed to parse public key: %w", err)
+			}
+			return KeySet{"": key}, nil
+		case "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("auth: failed to parse certificate: %w", err)
+			}
+			return KeySet{"": cert.PublicKey}, nil
+		}
+	}
+}
+
+// jwk - JSON Web Key (RFC 7517) of RSA, EC or OKP (Ed25519) type
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// LoadJWKS - reads verification keys out of a JSON Web Key Set file. Keys which are not meant for signatures
+// are skipped.
+func LoadJWKS(path string) (KeySet, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("auth: failed to read JWKS: %w", err)
+	}
+	return ParseJWKS(data)
+}
+
+func ParseJWKS(data []byte) (KeySet, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("auth: failed to parse JWKS: %w", err)
+	}
+	keys := make(KeySet, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("auth: key %q of JWKS: %w", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("auth: JWKS has no signature keys")
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("RSA exponent is too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("malformed Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(data) == 0 {
+		return nil, errors.New("malformed key parameter")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,199 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"com.user.com/user/internal/auth"
+	"com.user.com/user/internal/auth/authfakes"
+	"com.user.com/user/internal/core"
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Token Verifier", func() {
+	var (
+		dir    string
+		key    *ecdsa.PrivateKey
+		issuer *auth.Issuer
+		token  string
+		user   core.User
+		users  *authfakes.FakeUserAuthenticator
+	)
+
+	// external - verifier of tokens issued by the identity provider
+	external := func(keys auth.KeySet) *auth.Verifier {
+		return auth.NewVerifier(nil, users, auth.VerifierConfig{
+			Issuer:         "users",
+			Audience:       "users",
+			ExternalIssuer: "idp",
+			ExternalKeys:   keys,
+		})
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "auth-verifier")
+		Expect(err).To(BeNil())
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).To(BeNil())
+		issuer, err = auth.NewIssuer(key, "idp", "users")
+		Expect(err).To(BeNil())
+		user = core.User{ID: uuid.New(), Role: core.RoleUser}
+		users = &authfakes.FakeUserAuthenticator{}
+		users.GetUserByIDReturns(user, nil)
+		// Identity provider claims a role of its own
+		claimed := user
+		claimed.Role = core.RoleAdmin
+		token, err = issuer.Issue(claimed, time.Now().Add(time.Minute))
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		_ = os.RemoveAll(dir)
+	})
+
+	writeFile := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		Expect(ioutil.WriteFile(path, data, 0600)).To(Succeed())
+		return path
+	}
+
+	Context("With JWKS", func() {
+		var jwks map[string]interface{}
+		BeforeEach(func() {
+			// Issuer has a single key
+			var kid string
+			for id := range issuer.KeySet() {
+				kid = id
+			}
+			jwks = map[string]interface{}{"keys": []map[string]string{
+				{"kty": "EC", "kid": "encryption", "use": "enc", "crv": "P-256", "x": "AA", "y": "AA"},
+				{
+					"kty": "EC",
+					"kid": kid,
+					"use": "sig",
+					"crv": "P-256",
+					"x":   base64.RawURLEncoding.EncodeToString(key.X.Bytes()),
+					"y":   base64.RawURLEncoding.EncodeToString(key.Y.Bytes()),
+				},
+			}}
+		})
+
+		It("verifies tokens by the key of their kid", func() {
+			data, err := json.Marshal(jwks)
+			Expect(err).To(BeNil())
+			keys, err := auth.LoadJWKS(writeFile("jwks.json", data))
+			Expect(err).To(BeNil())
+			Expect(keys).To(HaveLen(1))
+
+			actor, err := external(keys).Verify(context.Background(), token)
+			Expect(err).To(BeNil())
+			Expect(actor.ID).To(Equal(user.ID.String()))
+		})
+
+		It("rejects tokens of unknown kid", func() {
+			jwks["keys"].([]map[string]string)[1]["kid"] = "another-key"
+			data, err := json.Marshal(jwks)
+			Expect(err).To(BeNil())
+			keys, err := auth.ParseJWKS(data)
+			Expect(err).To(BeNil())
+
+			_, err = external(keys).Verify(context.Background(), token)
+			Expect(errors.Is(err, auth.ErrInvalidToken)).To(BeTrue())
+		})
+
+		It("fails on a point which is not on the curve", func() {
+			jwks["keys"].([]map[string]string)[1]["y"] = base64.RawURLEncoding.EncodeToString(key.X.Bytes())
+			data, err := json.Marshal(jwks)
+			Expect(err).To(BeNil())
+			_, err = auth.ParseJWKS(data)
+			Expect(err).ToNot(BeNil())
+		})
+	})
+
+	Context("With static public key", func() {
+		var keys auth.KeySet
+		BeforeEach(func() {
+			der, err := x509.MarshalPKIXPublicKey(key.Public())
+			Expect(err).To(BeNil())
+			keys, err = auth.LoadPublicKey(writeFile("public.pem", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})))
+			Expect(err).To(BeNil())
+		})
+
+		It("verifies tokens regardless of their kid", func() {
+			actor, err := external(keys).Verify(context.Background(), token)
+			Expect(err).To(BeNil())
+			Expect(actor.ID).To(Equal(user.ID.String()))
+		})
+
+		It("takes the role of the user rather than the one the token claims", func() {
+			actor, err := external(keys).Verify(context.Background(), token)
+			Expect(err).To(BeNil())
+			Expect(actor.Role).To(Equal(core.RoleUser))
+			_, lookupActor, id := users.GetUserByIDArgsForCall(0)
+			Expect(lookupActor).To(Equal(core.SystemActor))
+			Expect(id).To(Equal(user.ID))
+		})
+
+		It("rejects tokens of unknown users", func() {
+			users.GetUserByIDReturns(core.User{}, &core.NotFoundError{Entity: "user", Key: user.ID.String()})
+			_, err := external(keys).Verify(context.Background(), token)
+			Expect(errors.Is(err, auth.ErrInvalidToken)).To(BeTrue())
+		})
+
+		It("fails without rejecting the token when users cannot be looked up", func() {
+			users.GetUserByIDReturns(core.User{}, &core.UnavailableError{Message: "database is unavailable"})
+			_, err := external(keys).Verify(context.Background(), token)
+			Expect(err).To(BeAssignableToTypeOf(&core.UnavailableError{}))
+		})
+
+		It("rejects tokens issued by this service's issuer", func() {
+			own, err := auth.NewIssuer(key, "users", "users")
+			Expect(err).To(BeNil())
+			ownToken, err := own.Issue(user, time.Now().Add(time.Minute))
+			Expect(err).To(BeNil())
+			_, err = external(keys).Verify(context.Background(), ownToken)
+			Expect(errors.Is(err, auth.ErrInvalidToken)).To(BeTrue())
+		})
+
+		It("rejects tokens meant for another audience", func() {
+			other, err := auth.NewIssuer(key, "idp", "billing")
+			Expect(err).To(BeNil())
+			otherToken, err := other.Issue(user, time.Now().Add(time.Minute))
+			Expect(err).To(BeNil())
+			_, err = external(keys).Verify(context.Background(), otherToken)
+			Expect(errors.Is(err, auth.ErrInvalidToken)).To(BeTrue())
+		})
+
+		It("rejects HMAC token keyed by the public key", func() {
+			der, err := x509.MarshalPKIXPublicKey(key.Public())
+			Expect(err).To(BeNil())
+			header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+			claims := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(
+				`{"iss":"idp","sub":"%s","role":"admin","exp":%d}`, user.ID, time.Now().Add(time.Minute).Unix())))
+			mac := hmac.New(sha256.New, der)
+			_, _ = mac.Write([]byte(header + "." + claims))
+			forged := strings.Join([]string{header, claims, base64.RawURLEncoding.EncodeToString(mac.Sum(nil))}, ".")
+
+			_, err = external(keys).Verify(context.Background(), forged)
+			Expect(errors.Is(err, auth.ErrInvalidToken)).To(BeTrue())
+		})
+	})
+})
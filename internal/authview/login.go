@@ -0,0 +1,59 @@
+package authview
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/view"
+	"github.com/go-playground/validator"
+	"github.com/sirupsen/logrus"
+)
+
+type LoginEndpoint struct {
+	loginer   Loginer
+	validator *validator.Validate
+}
+
+type Loginer interface {
+	Login(ctx context.Context, email, password string) (core.TokenPair, error)
+}
+
+type LoginParams struct {
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+func NewLoginEndpoint(loginer Loginer) *LoginEndpoint {
+	return &LoginEndpoint{
+		loginer:   loginer,
+		validator: view.NewValidator(),
+	}
+}
+
+func (l *LoginEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(time.Millisecond*10000))
+	defer cancel()
+	logrus.WithContext(ctx).
+		WithField("Endoint", "authview.LoginEndpoint").
+		Debug("request started")
+
+	var params LoginParams
+	if !view.ReadBody(ctx, w, r, &params, l.validator) {
+		return
+	}
+
+	tokens, err := l.loginer.Login(ctx, params.Email, params.Password)
+	if err != nil {
+		view.RespondError(ctx, w, err, "logging in")
+		return
+	}
+
+	// Tokens must not end up in caches (RFC 6749, section 5.1)
+	w.Header().Set("Cache-Control", "no-store")
+	view.RespondJSON(ctx, w, http.StatusOK, newTokenResponse(tokens))
+	logrus.WithContext(ctx).
+		WithField("Endoint", "authview.LoginEndpoint").
+		Debug("request completed")
+}
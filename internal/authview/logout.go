@@ -0,0 +1,52 @@
+package authview
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"com.user.com/user/internal/view"
+	"github.com/go-playground/validator"
+	"github.com/sirupsen/logrus"
+)
+
+type LogoutEndpoint struct {
+	logouter  Logouter
+	validator *validator.Validate
+}
+
+type Logouter interface {
+	Logout(ctx context.Context, refreshToken string) error
+}
+
+func NewLogoutEndpoint(logouter Logouter) *LogoutEndpoint {
+	return &LogoutEndpoint{
+		logouter:  logouter,
+		validator: view.NewValidator(),
+	}
+}
+
+func (e *LogoutEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(time.Millisecond*10000))
+	defer cancel()
+	logrus.WithContext(ctx).
+		WithField("Endoint", "authview.LogoutEndpoint").
+		Debug("request started")
+
+	var params RefreshTokenParams
+	if !view.ReadBody(ctx, w, r, &params, e.validator) {
+		return
+	}
+
+	// Access tokens stay valid until they expire, the session just cannot be refreshed anymore
+	err := e.logouter.Logout(ctx, params.RefreshToken)
+	if err != nil {
+		view.RespondError(ctx, w, err, "logging out")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	logrus.WithContext(ctx).
+		WithField("Endoint", "authview.LogoutEndpoint").
+		Debug("request completed")
+}
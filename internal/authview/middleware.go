@@ -0,0 +1,112 @@
+package authview
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"com.user.com/user/internal/auth"
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/view"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// TokenVerifier - tells who the access token has been issued for. Fails with auth.ErrInvalidToken if the token
+// is not valid.
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (core.Actor, error)
+}
+
+// APIKeyAuthenticator - tells which service the API key belongs to
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, key string) (core.Actor, error)
+}
+
+// NewAuthMiddleware - attaches the actor of the bearer token or the API key to the request context. Requests
+// without Authorization header go on as anonymous ones, business logic decides what they may do. Requests with
+// invalid or expired credentials are rejected.
+func NewAuthMiddleware(verifier TokenVerifier, keys APIKeyAuthenticator) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			header := r.Header.Get("Authorization")
+			if header == "" {
+				next.ServeHTTP(w, r.WithContext(core.WithActor(ctx, core.Actor{Type: core.ActorTypeAnonymous})))
+				return
+			}
+			scheme, credentials, ok := splitAuthorization(header)
+			switch {
+			case ok && strings.EqualFold(scheme, "Bearer"):
+				actor, err := verifier.Verify(ctx, credentials)
+				if err != nil && !errors.Is(err, auth.ErrInvalidToken) {
+					view.RespondError(ctx, w, err, "authenticating")
+					return
+				}
+				if err != nil {
+					logrus.WithContext(ctx).
+						WithError(err).
+						Debug("rejected access token")
+					view.RespondError(ctx, w, &core.UnauthorizedError{Message: "access token is invalid or expired"}, "authenticating")
+					return
+				}
+				next.ServeHTTP(w, r.WithContext(core.WithActor(ctx, actor)))
+			case ok && strings.EqualFold(scheme, "ApiKey"):
+				actor, err := keys.Authenticate(ctx, credentials)
+				if err != nil {
+					view.RespondError(ctx, w, err, "authenticating")
+					return
+				}
+				next.ServeHTTP(w, r.WithContext(core.WithActor(ctx, actor)))
+			default:
+				view.RespondError(ctx, w, &core.UnauthorizedError{Message: "authorization header must carry a bearer token or an api key"}, "authenticating")
+			}
+		})
+	}
+}
+
+// RequireRole - lets through only actors of the role. It guards routes whose business logic has no policy of its own.
+func RequireRole(role core.Role) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			actor := core.ActorFromContext(ctx)
+			if !actor.Authenticated() {
+				view.RespondError(ctx, w, &core.UnauthorizedError{Message: "authentication is required"}, "authorizing")
+				return
+			}
+			if actor.Role != role {
+				view.RespondError(ctx, w, &core.ForbiddenError{Message: "role " + string(role) + " is required"}, "authorizing")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireScope - services calling by API keys need the scope to get through. Other actors are let through,
+// what they may do is up to business logic.
+func RequireScope(scope core.Scope) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			actor := core.ActorFromContext(ctx)
+			if actor.Type == core.ActorTypeService && !actor.HasScope(scope) {
+				view.RespondError(ctx, w, &core.ForbiddenError{Message: "scope " + string(scope) + " is required"}, "authorizing")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// splitAuthorization - splits "<scheme> <credentials>" header value
+func splitAuthorization(header string) (scheme, credentials string, ok bool) {
+	parts := strings.SplitN(strings.TrimSpace(header), " ", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	credentials = strings.TrimSpace(parts[1])
+	return parts[0], credentials, credentials != ""
+}
@@ -0,0 +1,104 @@
+package authview
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"com.user.com/user/internal/view"
+	"github.com/go-playground/validator"
+	"github.com/sirupsen/logrus"
+)
+
+// RequestPasswordResetParams - body of password reset request
+type RequestPasswordResetParams struct {
+	Email string `json:"email" validate:"required"`
+}
+
+// ResetPasswordParams - body of password reset confirmation
+type ResetPasswordParams struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+type RequestPasswordResetEndpoint struct {
+	requester PasswordResetRequester
+	validator *validator.Validate
+}
+
+type PasswordResetRequester interface {
+	RequestPasswordReset(ctx context.Context, email string) error
+}
+
+func NewRequestPasswordResetEndpoint(requester PasswordResetRequester) *RequestPasswordResetEndpoint {
+	return &RequestPasswordResetEndpoint{
+		requester: requester,
+		validator: view.NewValidator(),
+	}
+}
+
+func (e *RequestPasswordResetEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(time.Millisecond*10000))
+	defer cancel()
+	logrus.WithContext(ctx).
+		WithField("Endoint", "authview.RequestPasswordResetEndpoint").
+		Debug("request started")
+
+	var params RequestPasswordResetParams
+	if !view.ReadBody(ctx, w, r, &params, e.validator) {
+		return
+	}
+
+	// Request is accepted whether the email exists or not, so it cannot be used to find out registered emails
+	err := e.requester.RequestPasswordReset(ctx, params.Email)
+	if err != nil {
+		view.RespondError(ctx, w, err, "requesting password reset")
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	logrus.WithContext(ctx).
+		WithField("Endoint", "authview.RequestPasswordResetEndpoint").
+		Debug("request completed")
+}
+
+type ResetPasswordEndpoint struct {
+	resetter  PasswordResetter
+	validator *validator.Validate
+}
+
+type PasswordResetter interface {
+	ResetPassword(ctx context.Context, token, password string) error
+}
+
+func NewResetPasswordEndpoint(resetter PasswordResetter) *ResetPasswordEndpoint {
+	return &ResetPasswordEndpoint{
+		resetter:  resetter,
+		validator: view.NewValidator(),
+	}
+}
+
+func (e *ResetPasswordEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(time.Millisecond*10000))
+	defer cancel()
+	logrus.WithContext(ctx).
+		WithField("Endoint", "authview.ResetPasswordEndpoint").
+		Debug("request started")
+
+	var params ResetPasswordParams
+	if !view.ReadBody(ctx, w, r, &params, e.validator) {
+		return
+	}
+
+	// Sessions of the user are ended, it logs in again with the new password
+	err := e.resetter.ResetPassword(ctx, params.Token, params.Password)
+	if err != nil {
+		view.RespondError(ctx, w, err, "resetting password")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	logrus.WithContext(ctx).
+		WithField("Endoint", "authview.ResetPasswordEndpoint").
+		Debug("request completed")
+}
@@ -0,0 +1,53 @@
+package authview
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/view"
+	"github.com/go-playground/validator"
+	"github.com/sirupsen/logrus"
+)
+
+type RefreshEndpoint struct {
+	refresher Refresher
+	validator *validator.Validate
+}
+
+type Refresher interface {
+	Refresh(ctx context.Context, refreshToken string) (core.TokenPair, error)
+}
+
+func NewRefreshEndpoint(refresher Refresher) *RefreshEndpoint {
+	return &RefreshEndpoint{
+		refresher: refresher,
+		validator: view.NewValidator(),
+	}
+}
+
+func (e *RefreshEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(time.Millisecond*10000))
+	defer cancel()
+	logrus.WithContext(ctx).
+		WithField("Endoint", "authview.RefreshEndpoint").
+		Debug("request started")
+
+	var params RefreshTokenParams
+	if !view.ReadBody(ctx, w, r, &params, e.validator) {
+		return
+	}
+
+	tokens, err := e.refresher.Refresh(ctx, params.RefreshToken)
+	if err != nil {
+		view.RespondError(ctx, w, err, "refreshing tokens")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	view.RespondJSON(ctx, w, http.StatusOK, newTokenResponse(tokens))
+	logrus.WithContext(ctx).
+		WithField("Endoint", "authview.RefreshEndpoint").
+		Debug("request completed")
+}
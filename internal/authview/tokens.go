@@ -0,0 +1,36 @@
+package authview
+
+import (
+	"math"
+	"time"
+
+	"com.user.com/user/internal/core"
+)
+
+// TokenResponse - issued tokens, shaped after OAuth 2.0 token response (RFC 6749, section 5.1)
+type TokenResponse struct {
+	AccessToken           string `json:"access_token"`
+	TokenType             string `json:"token_type"`
+	ExpiresIn             int    `json:"expires_in"`
+	RefreshToken          string `json:"refresh_token"`
+	RefreshTokenExpiresIn int    `json:"refresh_token_expires_in"`
+}
+
+// RefreshTokenParams - body of refresh and logout requests
+type RefreshTokenParams struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+func newTokenResponse(tokens core.TokenPair) TokenResponse {
+	return TokenResponse{
+		AccessToken:           tokens.AccessToken,
+		TokenType:             "Bearer",
+		ExpiresIn:             secondsUntil(tokens.AccessTokenExpiresAt),
+		RefreshToken:          tokens.RefreshToken,
+		RefreshTokenExpiresIn: secondsUntil(tokens.RefreshTokenExpiresAt),
+	}
+}
+
+func secondsUntil(t time.Time) int {
+	return int(math.Round(time.Until(t).Seconds()))
+}
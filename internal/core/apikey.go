@@ -0,0 +1,59 @@
+package core
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Scope - permission granted to an API key
+type Scope string
+
+const (
+	ScopeUsersRead   Scope = "users:read"
+	ScopeUsersWrite  Scope = "users:write"
+	ScopeUsersDelete Scope = "users:delete"
+)
+
+// Valid - whether the scope is known
+func (s Scope) Valid() bool {
+	switch s {
+	case ScopeUsersRead, ScopeUsersWrite, ScopeUsersDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// APIKey - credential of a service calling the API, e.g. a batch job. Keys act as service actors limited to
+// their scopes.
+type APIKey struct {
+	ID   uuid.UUID
+	Name string
+	// Key - the key itself. It is set only when the key is minted or rotated, only its hash is stored.
+	Key string
+	// Prefix - first characters of the key, they tell keys apart without revealing them
+	Prefix  string
+	KeyHash string
+	Scopes  []Scope
+	// ExpiresAt - zero for keys which never expire
+	ExpiresAt time.Time
+	// RevokedAt - zero unless the key has been revoked
+	RevokedAt time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Active - neither revoked nor expired at given time
+func (k APIKey) Active(at time.Time) bool {
+	return k.RevokedAt.IsZero() && (k.ExpiresAt.IsZero() || at.Before(k.ExpiresAt))
+}
+
+// Actor - service actor acting on behalf of the key
+func (k APIKey) Actor() Actor {
+	return Actor{
+		ID:     k.ID.String(),
+		Type:   ActorTypeService,
+		Scopes: k.Scopes,
+	}
+}
@@ -0,0 +1,45 @@
+package core
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TokenPair - credentials issued on login and refresh. Access token is a signed JWT, refresh token is an opaque
+// secret, which is exchanged for a new pair.
+type TokenPair struct {
+	AccessToken           string
+	AccessTokenExpiresAt  time.Time
+	RefreshToken          string
+	RefreshTokenExpiresAt time.Time
+}
+
+// RefreshToken - persisted refresh token. Only the hash of the secret is stored.
+type RefreshToken struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	// FamilyID - tokens rotated out of the same login share it, so the whole session can be revoked at once
+	FamilyID  uuid.UUID
+	TokenHash string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	// RevokedAt - zero unless the token has been rotated or revoked
+	RevokedAt time.Time
+}
+
+// Active - neither revoked nor expired at given time
+func (t RefreshToken) Active(at time.Time) bool {
+	return t.RevokedAt.IsZero() && at.Before(t.ExpiresAt)
+}
+
+// PasswordResetToken - persisted password reset token. Only the hash of the secret is stored.
+type PasswordResetToken struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	TokenHash string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	// UsedAt - zero unless the password has been reset by the token or by another one of the user
+	UsedAt time.Time
+}
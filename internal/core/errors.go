@@ -0,0 +1,88 @@
+package core
+
+import "fmt"
+
+// Domain error taxonomy. Inner layers return these types, so outer layers can react on the
+// kind of failure (e.g. map it to HTTP status) without knowing where it came from.
+
+// NotFoundError - requested entity does not exist
+type NotFoundError struct {
+	Entity string
+	Key    string
+}
+
+func (e *NotFoundError) Error() string {
+	if e.Key == "" {
+		return fmt.Sprintf("%s not found", e.Entity)
+	}
+	return fmt.Sprintf("%s not found: %s", e.Entity, e.Key)
+}
+
+// ValidationError - provided input violates business rules
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// ConflictError - operation conflicts with existing data, e.g. duplicated unique value
+type ConflictError struct {
+	Field   string
+	Message string
+	Err     error
+}
+
+func (e *ConflictError) Error() string {
+	return e.Message
+}
+
+func (e *ConflictError) Unwrap() error {
+	return e.Err
+}
+
+// PreconditionFailedError - entity has been changed since the client has seen it
+type PreconditionFailedError struct {
+	Message string
+}
+
+func (e *PreconditionFailedError) Error() string {
+	return e.Message
+}
+
+// UnavailableError - transient failure of a dependency. Operation may succeed if retried.
+type UnavailableError struct {
+	Message string
+	Err     error
+}
+
+func (e *UnavailableError) Error() string {
+	if e.Err == nil {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %v", e.Message, e.Err)
+}
+
+func (e *UnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// UnauthorizedError - credentials or token are missing, invalid or expired
+type UnauthorizedError struct {
+	Message string
+}
+
+func (e *UnauthorizedError) Error() string {
+	return e.Message
+}
+
+// ForbiddenError - actor is known, yet it is not allowed to perform the operation
+type ForbiddenError struct {
+	Message string
+}
+
+func (e *ForbiddenError) Error() string {
+	return e.Message
+}
@@ -0,0 +1,218 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventSchemaVersion - version of Event structure. It has to be bumped on every breaking change,
+// so consumers are able to tell apart old and new events.
+const EventSchemaVersion = 1
+
+// EventType - what has happened to the user
+type EventType string
+
+const (
+	EventUserCreated EventType = "user.created"
+	EventUserUpdated EventType = "user.updated"
+	EventUserDeleted EventType = "user.deleted"
+	// EventUserPasswordChanged - password has been reset, sessions of the user have been ended
+	EventUserPasswordChanged EventType = "user.password_changed"
+)
+
+// Valid - whether the event type is known
+func (t EventType) Valid() bool {
+	switch t {
+	case EventUserCreated, EventUserUpdated, EventUserDeleted, EventUserPasswordChanged:
+		return true
+	default:
+		return false
+	}
+}
+
+// ActorType - kind of party which has caused an event
+type ActorType string
+
+const (
+	ActorTypeAnonymous ActorType = "anonymous"
+	ActorTypeUser      ActorType = "user"
+	ActorTypeService   ActorType = "service"
+	ActorTypeSystem    ActorType = "system"
+)
+
+// Actor - party which has caused an event. Authenticated user is an actor of ActorTypeUser, its ID is the ID of
+// the user. API key is an actor of ActorTypeService limited to its scopes.
+type Actor struct {
+	ID     string
+	Type   ActorType
+	Role   Role
+	Scopes []Scope
+}
+
+// SystemActor - the service itself, e.g. refreshing tokens on behalf of their user
+var SystemActor = Actor{ID: "users-service", Type: ActorTypeSystem}
+
+// Authenticated - whether the actor has proven its identity
+func (a Actor) Authenticated() bool {
+	return a.Type != "" && a.Type != ActorTypeAnonymous
+}
+
+// HasScope - whether the actor has been granted the scope
+func (a Actor) HasScope(scope Scope) bool {
+	for _, s := range a.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsUser - whether the actor is the user with given ID
+func (a Actor) IsUser(id uuid.UUID) bool {
+	return a.Type == ActorTypeUser && a.ID == id.String()
+}
+
+type actorCtxKey struct{}
+
+// WithActor - attaches the actor to the context
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorCtxKey{}, actor)
+}
+
+// ActorFromContext - actor attached to the context, anonymous one if there is none
+func ActorFromContext(ctx context.Context) Actor {
+	actor, ok := ctx.Value(actorCtxKey{}).(Actor)
+	if !ok {
+		return Actor{Type: ActorTypeAnonymous}
+	}
+	return actor
+}
+
+// UserSnapshot - state of the user without its secrets
+type UserSnapshot struct {
+	ID        uuid.UUID
+	FirstName string
+	LastName  string
+	Nickname  string
+	Email     string
+	Country   string
+	// EmailVerifiedAt - zero while the email is not verified
+	EmailVerifiedAt time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+func NewUserSnapshot(user User) *UserSnapshot {
+	return &UserSnapshot{
+		ID:              user.ID,
+		FirstName:       user.FirstName,
+		LastName:        user.LastName,
+		Nickname:        user.Nickname,
+		Email:           user.Email,
+		Country:         user.Country,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+	}
+}
+
+// FieldChange - single changed field. Values of redacted fields are never exposed.
+type FieldChange struct {
+	Field    UserField
+	Before   string
+	After    string
+	Redacted bool
+}
+
+// redactedFields - fields whose values must not leave the service. Snapshots never carry them
+// and their changes are reported without values.
+var redactedFields = []UserField{UserFieldPassword}
+
+func isRedacted(field UserField) bool {
+	for _, redacted := range redactedFields {
+		if field == redacted {
+			return true
+		}
+	}
+	return false
+}
+
+// Event - domain event describing a change of the user
+type Event struct {
+	ID            uuid.UUID
+	Type          EventType
+	SchemaVersion int
+	OccurredAt    time.Time
+	Actor         Actor
+	UserID        uuid.UUID
+	// Before - nil for created users
+	Before *UserSnapshot
+	// After - nil for deleted users
+	After          *UserSnapshot
+	Changes        []FieldChange
+	RedactedFields []UserField
+}
+
+func newEvent(actor Actor, eventType EventType, userID uuid.UUID) Event {
+	return Event{
+		ID:             uuid.New(),
+		Type:           eventType,
+		SchemaVersion:  EventSchemaVersion,
+		OccurredAt:     time.Now().UTC(),
+		Actor:          actor,
+		UserID:         userID,
+		RedactedFields: redactedFields,
+	}
+}
+
+func NewUserCreatedEvent(actor Actor, user User) Event {
+	event := newEvent(actor, EventUserCreated, user.ID)
+	event.After = NewUserSnapshot(user)
+	return event
+}
+
+func NewUserUpdatedEvent(actor Actor, before, after User) Event {
+	event := newEvent(actor, EventUserUpdated, after.ID)
+	event.Before = NewUserSnapshot(before)
+	event.After = NewUserSnapshot(after)
+	for _, field := range ChangedFields(before, after) {
+		if isRedacted(field) {
+			event.Changes = append(event.Changes, FieldChange{Field: field, Redacted: true})
+			continue
+		}
+		event.Changes = append(event.Changes, FieldChange{
+			Field:  field,
+			Before: before.Field(field),
+			After:  after.Field(field),
+		})
+	}
+	return event
+}
+
+// NewUserPasswordChangedEvent - the change of the password is reported as redacted, like in user updated events
+func NewUserPasswordChangedEvent(actor Actor, before, after User) Event {
+	event := newEvent(actor, EventUserPasswordChanged, after.ID)
+	event.Before = NewUserSnapshot(before)
+	event.After = NewUserSnapshot(after)
+	event.Changes = []FieldChange{{Field: UserFieldPassword, Redacted: true}}
+	return event
+}
+
+func NewUserDeletedEvent(actor Actor, user User) Event {
+	event := newEvent(actor, EventUserDeleted, user.ID)
+	event.Before = NewUserSnapshot(user)
+	return event
+}
+
+// EventBuilder - builds the event out of the user as it has been written, so the event carries db generated
+// timestamps. Stores persist the event in the same transaction as the user.
+type EventBuilder func(stored User) Event
+
+// PendingEvent - event waiting in the outbox to be published
+type PendingEvent struct {
+	Event Event
+	// Attempts - number of publish attempts, including the current one
+	Attempts int
+}
@@ -15,18 +15,249 @@ type User struct {
 	Password  string
 	Email     string
 	Country   string
+	// Role - what the user is allowed to do, it is not modifiable by clients
+	Role Role
+	// EmailVerifiedAt - zero until the user proves it owns the email, it is reset whenever the email changes
+	EmailVerifiedAt time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// EmailVerified - whether the user has proven it owns its current email
+func (u User) EmailVerified() bool {
+	return !u.EmailVerifiedAt.IsZero()
+}
+
+// EmailVerificationToken - persisted email verification token. Only the hash of the secret is stored, along with
+// the email the token has been mailed to.
+type EmailVerificationToken struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Email     string
+	TokenHash string
+	ExpiresAt time.Time
 	CreatedAt time.Time
-	UpdatedAt time.Time
+	// UsedAt - zero unless the email has been verified by the token or another token has been issued to the user
+	UsedAt time.Time
 }
 
+// Role - set of permissions of the user
+type Role string
+
+const (
+	// RoleUser - manages itself only
+	RoleUser Role = "user"
+	// RoleAdmin - manages every user and webhook
+	RoleAdmin Role = "admin"
+)
+
+// Valid - whether the role is known
+func (r Role) Valid() bool {
+	return r == RoleUser || r == RoleAdmin
+}
+
+// UserField - name of a user property which can be modified by clients
+type UserField string
+
+const (
+	UserFieldFirstName UserField = "first_name"
+	UserFieldLastName  UserField = "last_name"
+	UserFieldNickname  UserField = "nickname"
+	UserFieldPassword  UserField = "password"
+	UserFieldEmail     UserField = "email"
+	UserFieldCountry   UserField = "country"
+)
+
+// Field - value of the modifiable field
+func (u User) Field(field UserField) string {
+	switch field {
+	case UserFieldFirstName:
+		return u.FirstName
+	case UserFieldLastName:
+		return u.LastName
+	case UserFieldNickname:
+		return u.Nickname
+	case UserFieldPassword:
+		return u.Password
+	case UserFieldEmail:
+		return u.Email
+	case UserFieldCountry:
+		return u.Country
+	default:
+		return ""
+	}
+}
+
+// ChangedFields - modifiable fields which differ between two states of the same user
+func ChangedFields(before, after User) []UserField {
+	var changed []UserField
+	if before.FirstName != after.FirstName {
+		changed = append(changed, UserFieldFirstName)
+	}
+	if before.LastName != after.LastName {
+		changed = append(changed, UserFieldLastName)
+	}
+	if before.Nickname != after.Nickname {
+		changed = append(changed, UserFieldNickname)
+	}
+	if before.Password != after.Password {
+		changed = append(changed, UserFieldPassword)
+	}
+	if before.Email != after.Email {
+		changed = append(changed, UserFieldEmail)
+	}
+	if before.Country != after.Country {
+		changed = append(changed, UserFieldCountry)
+	}
+	return changed
+}
+
+// UserPatch - partial modification of the user, e.g. JSON merge patch. Apply returns the user with the
+// patch applied on top of it. Changed password is expected in plain text.
+type UserPatch interface {
+	Apply(user User) (User, error)
+}
+
+// UserPatchFunc - allows ordinary functions to be used as UserPatch
+type UserPatchFunc func(user User) (User, error)
+
+func (f UserPatchFunc) Apply(user User) (User, error) {
+	return f(user)
+}
+
+// UserFilter - criteria of users listing. Zero valued criteria match every user.
 type UserFilter struct {
-	Country   string
-	FirstName string
-	LastName  string
-	Nickname  string
+	// IDs - batch lookup, users with any of the IDs
+	IDs         []uuid.UUID
+	Country     StringCondition
+	FirstName   StringCondition
+	LastName    StringCondition
+	Nickname    StringCondition
+	EmailDomain StringCondition
+	CreatedAt   TimeRange
+	UpdatedAt   TimeRange
+	// Query - search term matched against names, nickname and email, case insensitive, partially or fuzzily.
+	// Users are ordered by relevance when it is set, unless Sort is set.
+	Query string
+	// Sort - ordering of users, newly created users go first when it is empty
+	Sort []UserSort
+	// Count - how matching users are counted, exactly unless stated otherwise
+	Count CountMode
 
 	// Pagination
 	PreviousPage string
 	NextPage     string
-	Limit        int
+	// Limit - page size, zero means the default one
+	Limit int
+}
+
+// CountMode - how users matching a filter are counted. Exact count gets expensive as the table grows.
+type CountMode string
+
+const (
+	CountExact CountMode = "exact"
+	// CountEstimated - estimate taken from table statistics of the db
+	CountEstimated CountMode = "estimated"
+	CountNone      CountMode = "none"
+)
+
+func (m CountMode) Valid() bool {
+	switch m {
+	case CountExact, CountEstimated, CountNone:
+		return true
+	default:
+		return false
+	}
+}
+
+// UserPage - single page of users listing along with cursors of its neighbours
+type UserPage struct {
+	Users        []*User
+	PreviousPage string
+	NextPage     string
+	// HasMore - whether there are users following the page
+	HasMore bool
+	// Total - number of users matching the filter, nil unless they have been counted
+	Total *int
+	// TotalEstimated - whether Total is an estimate
+	TotalEstimated bool
+}
+
+// UserSortField - property users can be sorted by
+type UserSortField string
+
+const (
+	UserSortFirstName UserSortField = "first_name"
+	UserSortLastName  UserSortField = "last_name"
+	UserSortNickname  UserSortField = "nickname"
+	UserSortEmail     UserSortField = "email"
+	UserSortCountry   UserSortField = "country"
+	UserSortCreatedAt UserSortField = "created_at"
+	UserSortUpdatedAt UserSortField = "updated_at"
+)
+
+func (f UserSortField) Valid() bool {
+	switch f {
+	case UserSortFirstName, UserSortLastName, UserSortNickname, UserSortEmail, UserSortCountry, UserSortCreatedAt, UserSortUpdatedAt:
+		return true
+	default:
+		return false
+	}
+}
+
+type UserSort struct {
+	Field      UserSortField
+	Descending bool
+}
+
+// StringCondition - value has to be any of In and none of NotIn. Empty slices impose no condition.
+type StringCondition struct {
+	In    []string
+	NotIn []string
+}
+
+func (c StringCondition) IsZero() bool {
+	return len(c.In) == 0 && len(c.NotIn) == 0
+}
+
+// TimeRange - bounds of a timestamp, zero bounds are open
+type TimeRange struct {
+	// After, Before - exclusive bounds
+	After  time.Time
+	Before time.Time
+	// From, Until - inclusive bounds
+	From  time.Time
+	Until time.Time
+}
+
+func (r TimeRange) IsZero() bool {
+	return r.After.IsZero() && r.Before.IsZero() && r.From.IsZero() && r.Until.IsZero()
+}
+
+// lower, upper - the tightest of the bounds. Exclusive bound wins over inclusive one at the same time.
+func (r TimeRange) lower() (time.Time, bool) {
+	if r.After.IsZero() || (!r.From.IsZero() && r.From.After(r.After)) {
+		return r.From, false
+	}
+	return r.After, true
+}
+
+func (r TimeRange) upper() (time.Time, bool) {
+	if r.Before.IsZero() || (!r.Until.IsZero() && r.Until.Before(r.Before)) {
+		return r.Until, false
+	}
+	return r.Before, true
+}
+
+// IsEmpty - whether no time can fall within the range
+func (r TimeRange) IsEmpty() bool {
+	lower, lowerExclusive := r.lower()
+	upper, upperExclusive := r.upper()
+	if lower.IsZero() || upper.IsZero() {
+		return false
+	}
+	if lowerExclusive || upperExclusive {
+		return !lower.Before(upper)
+	}
+	return lower.After(upper)
 }
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Webhook - subscription of an external endpoint to user events
+type Webhook struct {
+	ID         uuid.UUID
+	URL        string
+	EventTypes []EventType
+	// Secret - key of HMAC-SHA256 signatures of delivered payloads
+	Secret    string
+	Active    bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Subscribes - whether the webhook wants to receive events of given type
+func (w Webhook) Subscribes(eventType EventType) bool {
+	for _, t := range w.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// DeliveryStatus - state of a webhook delivery
+type DeliveryStatus string
+
+const (
+	// DeliveryPending - waiting for its first or next attempt
+	DeliveryPending DeliveryStatus = "pending"
+	// DeliverySucceeded - accepted by the endpoint with 2xx status
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	// DeliveryDeadLetter - given up after all attempts have failed
+	DeliveryDeadLetter DeliveryStatus = "dead_letter"
+)
+
+// WebhookDelivery - single event to be delivered to a webhook
+type WebhookDelivery struct {
+	ID        uuid.UUID
+	WebhookID uuid.UUID
+	EventID   uuid.UUID
+	EventType EventType
+	// Payload - body sent on every attempt, so retries are identical
+	Payload []byte
+	Status  DeliveryStatus
+	// AttemptCount - number of attempts made so far
+	AttemptCount int
+	// Attempts - history of attempts, it is loaded for the delivery log only
+	Attempts  []DeliveryAttempt
+	LastError string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// DeliveryAttempt - outcome of a single attempt to deliver a webhook
+type DeliveryAttempt struct {
+	Number      int
+	AttemptedAt time.Time
+	Duration    time.Duration
+	// StatusCode - zero if no response has been received
+	StatusCode int
+	Error      string
+}
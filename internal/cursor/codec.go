@@ -0,0 +1,129 @@
+// Package cursor - opaque pagination cursors. Cursors are signed, so clients cannot forge them, they expire and
+// they are bound to the query they have been issued for.
+package cursor
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMalformed        = errors.New("cursor is malformed")
+	ErrInvalidSignature = errors.New("cursor signature is invalid")
+	ErrUnknownVersion   = errors.New("cursor version is unknown")
+	ErrExpired          = errors.New("cursor has expired")
+	ErrBindingMismatch  = errors.New("cursor has been issued for a different query")
+)
+
+const (
+	// version - of the envelope, cursors of other versions are rejected
+	version = 1
+
+	DefaultTTL = 24 * time.Hour
+	// KeySize - size of generated keys, keys of any size are accepted though
+	KeySize = 32
+)
+
+// envelope - signed part of the cursor
+type envelope struct {
+	Version   int             `json:"ver"`
+	ExpiresAt time.Time       `json:"exp"`
+	Binding   string          `json:"bnd"`
+	Payload   json.RawMessage `json:"p"`
+}
+
+// Codec - encodes cursors as base64url(envelope).base64url(HMAC-SHA256(envelope))
+type Codec struct {
+	key []byte
+	ttl time.Duration
+}
+
+func NewCodec(key []byte, ttl time.Duration) *Codec {
+	return &Codec{
+		key: key,
+		ttl: ttl,
+	}
+}
+
+// GenerateKey - random key, good for a single instance only, since cursors do not survive its restart
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	_, err := rand.Read(key)
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Encode - signs the payload along with the binding, which identifies the query the cursor is issued for
+func (c *Codec) Encode(binding string, payload interface{}) (string, error) {
+	rawPayload, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	body, err := json.Marshal(envelope{
+		Version:   version,
+		ExpiresAt: time.Now().Add(c.ttl).UTC(),
+		Binding:   bindingDigest(binding),
+		Payload:   rawPayload,
+	})
+	if err != nil {
+		return "", err
+	}
+	encodedBody := base64.RawURLEncoding.EncodeToString(body)
+	return encodedBody + "." + base64.RawURLEncoding.EncodeToString(c.sign(encodedBody)), nil
+}
+
+// Decode - verifies the cursor and decodes its payload. Signature is checked before anything else is trusted.
+func (c *Codec) Decode(token, binding string, payload interface{}) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return ErrMalformed
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ErrMalformed
+	}
+	if !hmac.Equal(signature, c.sign(parts[0])) {
+		return ErrInvalidSignature
+	}
+	body, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return ErrMalformed
+	}
+	var env envelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		return ErrMalformed
+	}
+	if env.Version != version {
+		return ErrUnknownVersion
+	}
+	if !time.Now().Before(env.ExpiresAt) {
+		return ErrExpired
+	}
+	if env.Binding != bindingDigest(binding) {
+		return ErrBindingMismatch
+	}
+	if err := json.Unmarshal(env.Payload, payload); err != nil {
+		return ErrMalformed
+	}
+	return nil
+}
+
+func (c *Codec) sign(encodedBody string) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	_, _ = mac.Write([]byte(encodedBody))
+	return mac.Sum(nil)
+}
+
+// bindingDigest - keeps cursors short regardless of the size of the query
+func bindingDigest(binding string) string {
+	digest := sha256.Sum256([]byte(binding))
+	return base64.RawURLEncoding.EncodeToString(digest[:16])
+}
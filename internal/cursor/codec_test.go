@@ -0,0 +1,76 @@
+package cursor_test
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"com.user.com/user/internal/cursor"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type position struct {
+	Values []interface{} `json:"v"`
+	Offset int           `json:"o"`
+}
+
+var _ = Describe("Cursor Codec", func() {
+	var (
+		codec *cursor.Codec
+		token string
+	)
+
+	BeforeEach(func() {
+		codec = cursor.NewCodec([]byte("test-key"), time.Hour)
+		var err error
+		token, err = codec.Encode("country=BG", position{
+			Values: []interface{}{"2022-01-09T13:34:30.123456789Z", "id"},
+			Offset: 100,
+		})
+		Expect(err).To(BeNil())
+	})
+
+	It("decodes payload of its own cursor", func() {
+		var decoded position
+		Expect(codec.Decode(token, "country=BG", &decoded)).To(Succeed())
+		Expect(decoded.Values).To(Equal([]interface{}{"2022-01-09T13:34:30.123456789Z", "id"}))
+		Expect(decoded.Offset).To(Equal(100))
+	})
+
+	It("rejects cursor bound to a different query", func() {
+		var decoded position
+		Expect(codec.Decode(token, "country=DE", &decoded)).To(MatchError(cursor.ErrBindingMismatch))
+	})
+
+	It("rejects cursor signed by a different key", func() {
+		var decoded position
+		other := cursor.NewCodec([]byte("other-key"), time.Hour)
+		Expect(other.Decode(token, "country=BG", &decoded)).To(MatchError(cursor.ErrInvalidSignature))
+	})
+
+	It("rejects tampered cursor", func() {
+		parts := strings.Split(token, ".")
+		body, err := base64.RawURLEncoding.DecodeString(parts[0])
+		Expect(err).To(BeNil())
+		forged := strings.Replace(string(body), `"o":100`, `"o":0`, 1)
+		Expect(forged).ToNot(Equal(string(body)))
+		tampered := base64.RawURLEncoding.EncodeToString([]byte(forged)) + "." + parts[1]
+
+		var decoded position
+		Expect(codec.Decode(tampered, "country=BG", &decoded)).To(MatchError(cursor.ErrInvalidSignature))
+	})
+
+	It("rejects malformed cursor", func() {
+		var decoded position
+		Expect(codec.Decode("MjAyMi0xLTkgMTM6MzQ6MzAsZGEwZTM", "country=BG", &decoded)).To(MatchError(cursor.ErrMalformed))
+	})
+
+	It("rejects expired cursor", func() {
+		expiring := cursor.NewCodec([]byte("test-key"), -time.Second)
+		token, err := expiring.Encode("country=BG", position{})
+		Expect(err).To(BeNil())
+		var decoded position
+		Expect(codec.Decode(token, "country=BG", &decoded)).To(MatchError(cursor.ErrExpired))
+	})
+})
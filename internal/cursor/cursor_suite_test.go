@@ -0,0 +1,13 @@
+package cursor_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestCursor(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Cursor Suite")
+}
@@ -0,0 +1,13 @@
+package dberror_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestDBError(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "DB Error Suite")
+}
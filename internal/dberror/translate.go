@@ -0,0 +1,81 @@
+// Package dberror - converts errors of the db into core error types, so stores report conflicts and outages the same
+// way. Stores only tell which of their fields are guarded by unique constraints.
+package dberror
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+
+	"com.user.com/user/internal/core"
+	"github.com/lib/pq"
+)
+
+// Postgres/CockroachDB error codes, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	uniqueViolation      = pq.ErrorCode("23505")
+	serializationFailure = pq.ErrorCode("40001")
+	deadlockDetected     = pq.ErrorCode("40P01")
+	adminShutdown        = pq.ErrorCode("57P01")
+	cannotConnectNow     = pq.ErrorCode("57P03")
+
+	connectionExceptionClass  = pq.ErrorClass("08")
+	insufficientResourceClass = pq.ErrorClass("53")
+)
+
+// Field - field guarded by unique constraint whose name contains Constraint, e.g. email of users_email_lower_key
+type Field struct {
+	Constraint string
+	Name       string
+}
+
+// Translator - translates errors of the store of an entity
+type Translator struct {
+	// Entity - name of the entity conflicts are reported on, e.g. user
+	Entity string
+	// Fields - first field matching the violated constraint is reported, id is reported when none matches
+	Fields []Field
+}
+
+// Translate - converts db specific errors into core error types.
+// Errors which cannot be classified are returned untouched.
+func (t Translator) Translate(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, driver.ErrBadConn) {
+		return &core.UnavailableError{Message: "database connection lost", Err: err}
+	}
+
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+	switch {
+	case pqErr.Code == uniqueViolation:
+		field := t.fieldFromConstraint(pqErr)
+		return &core.ConflictError{
+			Field:   field,
+			Message: t.Entity + " with such " + field + " already exists",
+			Err:     err,
+		}
+	case pqErr.Code == serializationFailure, pqErr.Code == deadlockDetected:
+		return &core.UnavailableError{Message: "concurrent modification, please retry", Err: err}
+	case pqErr.Code == adminShutdown, pqErr.Code == cannotConnectNow,
+		pqErr.Code.Class() == connectionExceptionClass, pqErr.Code.Class() == insufficientResourceClass:
+		return &core.UnavailableError{Message: "database is unavailable", Err: err}
+	}
+	return err
+}
+
+// fieldFromConstraint - figures out which field violates unique constraint.
+// CockroachDB does not always fill in constraint name, hence the message is checked as well.
+func (t Translator) fieldFromConstraint(pqErr *pq.Error) string {
+	source := pqErr.Constraint + " " + pqErr.Message
+	for _, field := range t.Fields {
+		if strings.Contains(source, field.Constraint) {
+			return field.Name
+		}
+	}
+	return "id"
+}
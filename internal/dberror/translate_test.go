@@ -0,0 +1,62 @@
+package dberror_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/dberror"
+	"github.com/lib/pq"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Translator", func() {
+	translator := dberror.Translator{Entity: "wizard", Fields: []dberror.Field{
+		{Constraint: "email", Name: "email"},
+		{Constraint: "wand_hash", Name: "wand"},
+	}}
+
+	It("keeps nil", func() {
+		Expect(translator.Translate(nil)).To(BeNil())
+	})
+
+	It("keeps unclassified errors untouched", func() {
+		Expect(translator.Translate(sql.ErrNoRows)).To(Equal(sql.ErrNoRows))
+		other := &pq.Error{Code: "42601"}
+		Expect(translator.Translate(other)).To(Equal(other))
+	})
+
+	table.DescribeTable("unique violation is a conflict on the violated field",
+		func(pqErr *pq.Error, field, message string) {
+			err := translator.Translate(fmt.Errorf("saving: %w", pqErr))
+			var conflict *core.ConflictError
+			Expect(errors.As(err, &conflict)).To(BeTrue())
+			Expect(conflict.Field).To(Equal(field))
+			Expect(conflict.Message).To(Equal(message))
+			Expect(errors.Is(err, pqErr)).To(BeTrue())
+		},
+		table.Entry("field by constraint", &pq.Error{Code: "23505", Constraint: "wizards_email_lower_key"},
+			"email", "wizard with such email already exists"),
+		table.Entry("field by message", &pq.Error{Code: "23505", Message: `duplicate key value violates unique constraint "wizards_wand_hash_key"`},
+			"wand", "wizard with such wand already exists"),
+		table.Entry("primary key", &pq.Error{Code: "23505", Constraint: "wizards_pkey"},
+			"id", "wizard with such id already exists"),
+	)
+
+	table.DescribeTable("failures worth retrying are unavailable",
+		func(err error) {
+			Expect(translator.Translate(err)).To(BeAssignableToTypeOf(&core.UnavailableError{}))
+		},
+		table.Entry("bad connection", driver.ErrBadConn),
+		table.Entry("serialization failure", &pq.Error{Code: "40001"}),
+		table.Entry("deadlock", &pq.Error{Code: "40P01"}),
+		table.Entry("admin shutdown", &pq.Error{Code: "57P01"}),
+		table.Entry("cannot connect now", &pq.Error{Code: "57P03"}),
+		table.Entry("connection failure", &pq.Error{Code: "08006"}),
+		table.Entry("too many connections", &pq.Error{Code: "53300"}),
+	)
+})
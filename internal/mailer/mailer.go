@@ -0,0 +1,13 @@
+// Package mailer - sends mails to users, e.g. email verification tokens
+package mailer
+
+import (
+	"context"
+
+	"com.user.com/user/internal/core"
+)
+
+// Mailer - sends plain text mails
+type Mailer interface {
+	Send(ctx context.Context, mail core.Mail) error
+}
@@ -0,0 +1,44 @@
+package mailer
+
+import (
+	"context"
+	"sync"
+
+	"com.user.com/user/internal/core"
+)
+
+// Memory - keeps mails instead of sending them, so tests can read them
+type Memory struct {
+	mu    sync.Mutex
+	mails []core.Mail
+	// err - returned by Send, the mail is not kept then
+	err error
+}
+
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+func (m *Memory) Send(_ context.Context, mail core.Mail) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	m.mails = append(m.mails, mail)
+	return nil
+}
+
+// Sent - mails sent so far, oldest first
+func (m *Memory) Sent() []core.Mail {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]core.Mail(nil), m.mails...)
+}
+
+// FailWith - makes Send fail with err, nil error makes it succeed again
+func (m *Memory) FailWith(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.err = err
+}
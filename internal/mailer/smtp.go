@@ -0,0 +1,132 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"com.user.com/user/internal/core"
+)
+
+// SMTPConfig - SMTP server mails are relayed through
+type SMTPConfig struct {
+	// Addr - host:port of the server
+	Addr string
+	// Username - authenticates by PLAIN auth unless it is empty
+	Username string
+	Password string
+	// From - sender address, e.g. "Users <no-reply@example.com>"
+	From string
+}
+
+// SMTPMailer - relays mails through an SMTP server. STARTTLS is used whenever the server offers it, credentials
+// are sent over TLS only.
+type SMTPMailer struct {
+	config SMTPConfig
+	from   *mail.Address
+}
+
+func NewSMTPMailer(config SMTPConfig) (*SMTPMailer, error) {
+	if _, _, err := net.SplitHostPort(config.Addr); err != nil {
+		return nil, fmt.Errorf("mailer: invalid SMTP address %q: %w", config.Addr, err)
+	}
+	from, err := mail.ParseAddress(config.From)
+	if err != nil {
+		return nil, fmt.Errorf("mailer: invalid sender %q: %w", config.From, err)
+	}
+	return &SMTPMailer{
+		config: config,
+		from:   from,
+	}, nil
+}
+
+func (s *SMTPMailer) Send(ctx context.Context, m core.Mail) error {
+	to, err := mail.ParseAddress(m.To)
+	if err != nil {
+		return fmt.Errorf("mailer: invalid recipient %q: %w", m.To, err)
+	}
+	message, err := buildMessage(s.from, to, m, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", s.config.Addr)
+	if err != nil {
+		return fmt.Errorf("mailer: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	host, _, _ := net.SplitHostPort(s.config.Addr)
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("mailer: %w", err)
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		err = client.StartTLS(&tls.Config{ServerName: host})
+		if err != nil {
+			return fmt.Errorf("mailer: %w", err)
+		}
+	}
+	if s.config.Username != "" {
+		// PlainAuth refuses to send credentials over unencrypted connections to remote hosts
+		err = client.Auth(smtp.PlainAuth("", s.config.Username, s.config.Password, host))
+		if err != nil {
+			return fmt.Errorf("mailer: %w", err)
+		}
+	}
+	if err = client.Mail(s.from.Address); err != nil {
+		return fmt.Errorf("mailer: %w", err)
+	}
+	if err = client.Rcpt(to.Address); err != nil {
+		return fmt.Errorf("mailer: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("mailer: %w", err)
+	}
+	if _, err = w.Write(message); err != nil {
+		return fmt.Errorf("mailer: %w", err)
+	}
+	if err = w.Close(); err != nil {
+		return fmt.Errorf("mailer: %w", err)
+	}
+	return client.Quit()
+}
+
+// buildMessage - RFC 5322 message. Header values are encoded, so they cannot inject headers of their own.
+func buildMessage(from, to *mail.Address, m core.Mail, date time.Time) ([]byte, error) {
+	if strings.ContainsAny(m.Subject, "\r\n") {
+		return nil, fmt.Errorf("mailer: subject must be a single line")
+	}
+	var b strings.Builder
+	headers := []struct{ name, value string }{
+		{"From", from.String()},
+		{"To", to.String()},
+		{"Subject", mime.QEncoding.Encode("utf-8", m.Subject)},
+		{"Date", date.Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", `text/plain; charset="utf-8"`},
+		{"Content-Transfer-Encoding", "8bit"},
+	}
+	for _, h := range headers {
+		b.WriteString(h.name + ": " + h.value + "\r\n")
+	}
+	b.WriteString("\r\n")
+	// Lines end with CRLF on the wire
+	body := strings.ReplaceAll(strings.ReplaceAll(m.Body, "\r\n", "\n"), "\n", "\r\n")
+	b.WriteString(body)
+	return []byte(b.String()), nil
+}
@@ -0,0 +1,158 @@
+package mailer_test
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/mailer"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SMTP Mailer", func() {
+	var (
+		server *fakeSMTPServer
+		smtp   *mailer.SMTPMailer
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+
+	BeforeEach(func() {
+		server = startFakeSMTPServer()
+		var err error
+		smtp, err = mailer.NewSMTPMailer(mailer.SMTPConfig{Addr: server.addr(), From: "Users <no-reply@faceit.com>"})
+		Expect(err).To(BeNil())
+		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	})
+
+	AfterEach(func() {
+		cancel()
+		server.close()
+	})
+
+	It("relays the mail to the server", func() {
+		err := smtp.Send(ctx, core.Mail{To: "harry@faceit.com", Subject: "Verify your email", Body: "Hi,\nthe token is 42"})
+		Expect(err).To(BeNil())
+
+		var received fakeMail
+		Eventually(server.mails).Should(Receive(&received))
+		Expect(received.from).To(Equal("no-reply@faceit.com"))
+		Expect(received.to).To(Equal("harry@faceit.com"))
+		Expect(received.data).To(ContainSubstring("Subject: Verify your email\r\n"))
+		Expect(received.data).To(ContainSubstring("To: <harry@faceit.com>\r\n"))
+		Expect(received.data).To(HaveSuffix("\r\n\r\nHi,\r\nthe token is 42"))
+	})
+
+	It("rejects subject spanning multiple lines", func() {
+		err := smtp.Send(ctx, core.Mail{To: "harry@faceit.com", Subject: "Hi\r\nBcc: eve@faceit.com", Body: "Hi"})
+		Expect(err).ToNot(BeNil())
+		Consistently(server.mails, 100*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("rejects invalid recipient", func() {
+		err := smtp.Send(ctx, core.Mail{To: "not an address", Subject: "Hi", Body: "Hi"})
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("rejects invalid configuration", func() {
+		_, err := mailer.NewSMTPMailer(mailer.SMTPConfig{Addr: "localhost", From: "no-reply@faceit.com"})
+		Expect(err).ToNot(BeNil())
+		_, err = mailer.NewSMTPMailer(mailer.SMTPConfig{Addr: "localhost:25", From: "nobody"})
+		Expect(err).ToNot(BeNil())
+	})
+})
+
+type fakeMail struct {
+	from, to, data string
+}
+
+// fakeSMTPServer - accepts mails of a single session at a time, without TLS and auth
+type fakeSMTPServer struct {
+	listener net.Listener
+	mails    chan fakeMail
+}
+
+func startFakeSMTPServer() *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).To(BeNil())
+	s := &fakeSMTPServer{listener: listener, mails: make(chan fakeMail, 10)}
+	go s.serve()
+	return s
+}
+
+func (s *fakeSMTPServer) addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *fakeSMTPServer) close() {
+	_ = s.listener.Close()
+}
+
+func (s *fakeSMTPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.session(textproto.NewConn(conn))
+	}
+}
+
+func (s *fakeSMTPServer) session(conn *textproto.Conn) {
+	defer func() {
+		_ = conn.Close()
+	}()
+	var mail fakeMail
+	_ = conn.PrintfLine("220 localhost ESMTP")
+	for {
+		line, err := conn.ReadLine()
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch command {
+		case "EHLO", "HELO":
+			_ = conn.PrintfLine("250 localhost")
+		case "MAIL":
+			mail.from = strings.Trim(strings.TrimPrefix(line, "MAIL FROM:"), "<>")
+			_ = conn.PrintfLine("250 OK")
+		case "RCPT":
+			mail.to = strings.Trim(strings.TrimPrefix(line, "RCPT TO:"), "<>")
+			_ = conn.PrintfLine("250 OK")
+		case "DATA":
+			_ = conn.PrintfLine("354 Go ahead")
+			data, err := readData(conn.R)
+			if err != nil {
+				return
+			}
+			mail.data = data
+			s.mails <- mail
+			_ = conn.PrintfLine("250 OK")
+		case "QUIT":
+			_ = conn.PrintfLine("221 Bye")
+			return
+		default:
+			_ = conn.PrintfLine("502 Not implemented")
+		}
+	}
+}
+
+// readData - raw message up to the terminating dot, with its line endings kept
+func readData(r *bufio.Reader) (string, error) {
+	var b strings.Builder
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		if line == ".\r\n" {
+			return strings.TrimSuffix(b.String(), "\r\n"), nil
+		}
+		b.WriteString(line)
+	}
+}
@@ -0,0 +1,77 @@
+package migrate_test
+
+import (
+	"testing/fstest"
+
+	"com.user.com/user/internal/migrate"
+	"com.user.com/user/migrations"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Load", func() {
+	var (
+		fsys       fstest.MapFS
+		migrations []migrate.Migration
+		err        error
+	)
+
+	JustBeforeEach(func() {
+		migrations, err = migrate.Load(fsys)
+	})
+
+	Context("With complete migrations", func() {
+		BeforeEach(func() {
+			fsys = fstest.MapFS{
+				"010_add_index.up.sql":      {Data: []byte("CREATE INDEX")},
+				"010_add_index.down.sql":    {Data: []byte("DROP INDEX")},
+				"002_create_users.up.sql":   {Data: []byte("CREATE TABLE")},
+				"002_create_users.down.sql": {Data: []byte("DROP TABLE")},
+				"migrations.go":             {Data: []byte("package migrations")},
+			}
+		})
+		It("orders migrations by version", func() {
+			Expect(err).To(BeNil())
+			Expect(migrations).To(Equal([]migrate.Migration{
+				{Version: 2, Name: "create_users", Up: "CREATE TABLE", Down: "DROP TABLE"},
+				{Version: 10, Name: "add_index", Up: "CREATE INDEX", Down: "DROP INDEX"},
+			}))
+		})
+	})
+
+	Context("With missing down file", func() {
+		BeforeEach(func() {
+			fsys = fstest.MapFS{
+				"001_create_users.up.sql": {Data: []byte("CREATE TABLE")},
+			}
+		})
+		It("fails", func() {
+			Expect(err).To(MatchError(ContainSubstring("must have both up and down files")))
+		})
+	})
+
+	Context("With version used twice", func() {
+		BeforeEach(func() {
+			fsys = fstest.MapFS{
+				"001_create_users.up.sql":   {Data: []byte("CREATE TABLE")},
+				"001_create_users.down.sql": {Data: []byte("DROP TABLE")},
+				"001_create_roles.up.sql":   {Data: []byte("CREATE TABLE")},
+				"001_create_roles.down.sql": {Data: []byte("DROP TABLE")},
+			}
+		})
+		It("fails", func() {
+			Expect(err).To(MatchError(ContainSubstring("version 1 is used by both")))
+		})
+	})
+})
+
+var _ = Describe("Embedded migrations", func() {
+	It("are complete and ordered", func() {
+		ms, err := migrate.Load(migrations.FS)
+		Expect(err).To(BeNil())
+		Expect(ms).ToNot(BeEmpty())
+		for i := range ms {
+			Expect(ms[i].Version).To(Equal(i + 1))
+		}
+	})
+})
@@ -0,0 +1,266 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	createMigrationsTableStmt = `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INT NOT NULL PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`
+	createLockTableStmt = `CREATE TABLE IF NOT EXISTS schema_migrations_lock (
+		id INT NOT NULL PRIMARY KEY,
+		owner VARCHAR(255) NOT NULL,
+		locked_at TIMESTAMPTZ NOT NULL
+	)`
+	appliedMigrationsStmt = `SELECT version, applied_at FROM schema_migrations ORDER BY version`
+	recordMigrationStmt   = `INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, now())`
+	forgetMigrationStmt   = `DELETE FROM schema_migrations WHERE version=$1`
+
+	// Single row lock. Locks older than staleLockAfter are considered abandoned by crashed replicas.
+	// Age of the lock is measured by the db clock only, the one locked_at is written by, so clocks of replicas
+	// do not matter.
+	acquireLockStmt     = `INSERT INTO schema_migrations_lock (id, owner, locked_at) VALUES (1, $1, now()) ON CONFLICT (id) DO NOTHING`
+	releaseLockStmt     = `DELETE FROM schema_migrations_lock WHERE id=1 AND owner=$1`
+	removeStaleLockStmt = `DELETE FROM schema_migrations_lock WHERE id=1 AND locked_at < now() - $1::INT8 * INTERVAL '1 second'`
+
+	// staleLockAfter - the lock has no heartbeat, so its age is all there is to tell a crashed holder apart.
+	// Holders give up after maxLockHold, whatever their context allows, hence a live lock is never taken over.
+	staleLockAfter    = 10 * time.Minute
+	maxLockHold       = staleLockAfter / 2
+	lockRetryInterval = time.Second
+)
+
+// MigrationStatus - migration along with the time it has been applied. Zero AppliedAt means pending.
+type MigrationStatus struct {
+	Migration
+	AppliedAt time.Time
+}
+
+func (s MigrationStatus) Applied() bool {
+	return !s.AppliedAt.IsZero()
+}
+
+// Migrator - applies and reverts migrations. Every migration runs in its own transaction and all
+// operations hold a lock in the db, so concurrent replicas don't race.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+	owner      string
+}
+
+func NewMigrator(db *sql.DB, migrations []Migration) *Migrator {
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+		owner:      uuid.New().String(),
+	}
+}
+
+// Up - applies all pending migrations
+func (m *Migrator) Up(ctx context.Context) error {
+	if len(m.migrations) == 0 {
+		return nil
+	}
+	return m.To(ctx, m.migrations[len(m.migrations)-1].Version)
+}
+
+// Down - reverts the latest applied migration
+func (m *Migrator) Down(ctx context.Context) error {
+	return m.withLock(ctx, func(ctx context.Context) error {
+		statuses, err := m.status(ctx)
+		if err != nil {
+			return err
+		}
+		for i := len(statuses) - 1; i >= 0; i-- {
+			if statuses[i].Applied() {
+				return m.revert(ctx, statuses[i].Migration)
+			}
+		}
+		return nil
+	})
+}
+
+// To - applies or reverts migrations until the schema is at given version. Version 0 reverts everything.
+func (m *Migrator) To(ctx context.Context, version int) error {
+	if version != 0 && !m.exists(version) {
+		return fmt.Errorf("migrate: unknown version %d", version)
+	}
+	return m.withLock(ctx, func(ctx context.Context) error {
+		statuses, err := m.status(ctx)
+		if err != nil {
+			return err
+		}
+		// Revert newer migrations starting from the latest one
+		for i := len(statuses) - 1; i >= 0; i-- {
+			if statuses[i].Version > version && statuses[i].Applied() {
+				if err := m.revert(ctx, statuses[i].Migration); err != nil {
+					return err
+				}
+			}
+		}
+		for _, s := range statuses {
+			if s.Version <= version && !s.Applied() {
+				if err := m.apply(ctx, s.Migration); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Status - all known migrations in order, along with the time they have been applied
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	if err := m.init(ctx); err != nil {
+		return nil, err
+	}
+	return m.status(ctx)
+}
+
+func (m *Migrator) status(ctx context.Context) ([]MigrationStatus, error) {
+	rows, err := m.db.QueryContext(ctx, appliedMigrationsStmt)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var (
+			version   int
+			appliedAt time.Time
+		)
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		statuses = append(statuses, MigrationStatus{
+			Migration: migration,
+			AppliedAt: applied[migration.Version],
+		})
+	}
+	return statuses, nil
+}
+
+func (m *Migrator) apply(ctx context.Context, migration Migration) error {
+	logrus.WithContext(ctx).
+		WithField("version", migration.Version).
+		WithField("name", migration.Name).
+		Info("migrate: applying migration")
+	return m.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+			return fmt.Errorf("migrate: failed to apply %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		_, err := tx.ExecContext(ctx, recordMigrationStmt, migration.Version, migration.Name)
+		return err
+	})
+}
+
+func (m *Migrator) revert(ctx context.Context, migration Migration) error {
+	logrus.WithContext(ctx).
+		WithField("version", migration.Version).
+		WithField("name", migration.Name).
+		Info("migrate: reverting migration")
+	return m.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+			return fmt.Errorf("migrate: failed to revert %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		_, err := tx.ExecContext(ctx, forgetMigrationStmt, migration.Version)
+		return err
+	})
+}
+
+func (m *Migrator) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (m *Migrator) init(ctx context.Context) error {
+	if _, err := m.db.ExecContext(ctx, createMigrationsTableStmt); err != nil {
+		return err
+	}
+	_, err := m.db.ExecContext(ctx, createLockTableStmt)
+	return err
+}
+
+// withLock - runs fn while holding the migration lock. Waits for other replicas to finish their migrations.
+// Context of fn expires after maxLockHold at the latest, before the lock becomes stale.
+func (m *Migrator) withLock(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := m.init(ctx); err != nil {
+		return err
+	}
+	if err := m.acquireLock(ctx); err != nil {
+		return err
+	}
+	defer func() {
+		// Lock has to be released even if ctx has been cancelled in the meantime
+		_, err := m.db.ExecContext(context.Background(), releaseLockStmt, m.owner)
+		if err != nil {
+			logrus.WithError(err).Error("migrate: failed to release migration lock")
+		}
+	}()
+	ctx, cancel := context.WithTimeout(ctx, maxLockHold)
+	defer cancel()
+	return fn(ctx)
+}
+
+func (m *Migrator) acquireLock(ctx context.Context) error {
+	for {
+		if _, err := m.db.ExecContext(ctx, removeStaleLockStmt, int64(staleLockAfter.Seconds())); err != nil {
+			return err
+		}
+		res, err := m.db.ExecContext(ctx, acquireLockStmt, m.owner)
+		if err != nil {
+			return err
+		}
+		acquired, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if acquired == 1 {
+			return nil
+		}
+
+		logrus.WithContext(ctx).Info("migrate: waiting for migration lock")
+		select {
+		case <-ctx.Done():
+			return errors.New("migrate: timed out waiting for migration lock")
+		case <-time.After(lockRetryInterval):
+		}
+	}
+}
+
+func (m *Migrator) exists(version int) bool {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return true
+		}
+	}
+	return false
+}
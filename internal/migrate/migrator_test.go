@@ -0,0 +1,194 @@
+package migrate_test
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"com.user.com/user/internal/migrate"
+	servicemigrations "com.user.com/user/migrations"
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// Migrator specs run against TEST_DB_CONNECT_STRING, e.g. the CockroachDB of docker-compose. Every spec gets a
+// database of its own, so it neither sees migrations of the service nor of other runs.
+var _ = Describe("Migrator", func() {
+	var (
+		admin      *sql.DB
+		db         *sql.DB
+		database   string
+		ctx        context.Context
+		migrations []migrate.Migration
+	)
+
+	BeforeEach(func() {
+		connectString := os.Getenv("TEST_DB_CONNECT_STRING")
+		if connectString == "" {
+			Skip("TEST_DB_CONNECT_STRING is not set")
+		}
+		ctx = context.Background()
+		var err error
+		admin, err = sql.Open("postgres", connectString)
+		Expect(err).To(BeNil())
+		database = "migrate_test_" + strings.ReplaceAll(uuid.New().String(), "-", "")
+		_, err = admin.ExecContext(ctx, "CREATE DATABASE "+database)
+		Expect(err).To(BeNil())
+
+		dbURL, err := url.Parse(connectString)
+		Expect(err).To(BeNil())
+		dbURL.Path = "/" + database
+		db, err = sql.Open("postgres", dbURL.String())
+		Expect(err).To(BeNil())
+
+		// Every migration fails if it runs twice, the second one fails unless the first one has run before
+		migrations = []migrate.Migration{
+			{Version: 1, Name: "create_houses", Up: "CREATE TABLE houses (id INT PRIMARY KEY)", Down: "DROP TABLE houses"},
+			{Version: 2, Name: "create_wizards", Up: "CREATE TABLE wizards (id INT PRIMARY KEY, house_id INT REFERENCES houses (id))", Down: "DROP TABLE wizards"},
+		}
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			Expect(db.Close()).To(Succeed())
+		}
+		if admin != nil {
+			_, err := admin.ExecContext(ctx, "DROP DATABASE IF EXISTS "+database+" CASCADE")
+			Expect(err).To(BeNil())
+			Expect(admin.Close()).To(Succeed())
+		}
+	})
+
+	appliedVersions := func(migrator *migrate.Migrator) []int {
+		statuses, err := migrator.Status(ctx)
+		Expect(err).To(BeNil())
+		var versions []int
+		for _, status := range statuses {
+			if status.Applied() {
+				versions = append(versions, status.Version)
+			}
+		}
+		return versions
+	}
+	lockOwners := func() int {
+		var owners int
+		Expect(db.QueryRowContext(ctx, `SELECT count(*) FROM schema_migrations_lock`).Scan(&owners)).To(Succeed())
+		return owners
+	}
+
+	It("applies migrations in order and releases the lock", func() {
+		migrator := migrate.NewMigrator(db, migrations)
+		Expect(migrator.Up(ctx)).To(Succeed())
+		Expect(appliedVersions(migrator)).To(Equal([]int{1, 2}))
+		_, err := db.ExecContext(ctx, `INSERT INTO houses (id) VALUES (1); INSERT INTO wizards (id, house_id) VALUES (1, 1)`)
+		Expect(err).To(BeNil())
+		Expect(lockOwners()).To(Equal(0))
+	})
+
+	It("skips migrations which have been applied", func() {
+		Expect(migrate.NewMigrator(db, migrations[:1]).Up(ctx)).To(Succeed())
+		migrator := migrate.NewMigrator(db, migrations)
+		Expect(migrator.Up(ctx)).To(Succeed())
+		Expect(migrator.Up(ctx)).To(Succeed())
+		Expect(appliedVersions(migrator)).To(Equal([]int{1, 2}))
+	})
+
+	It("reverts migrations from the latest one", func() {
+		migrator := migrate.NewMigrator(db, migrations)
+		Expect(migrator.Up(ctx)).To(Succeed())
+		Expect(migrator.Down(ctx)).To(Succeed())
+		Expect(appliedVersions(migrator)).To(Equal([]int{1}))
+		Expect(migrator.To(ctx, 0)).To(Succeed())
+		Expect(appliedVersions(migrator)).To(BeEmpty())
+	})
+
+	It("keeps the schema of a failed migration unchanged", func() {
+		broken := append(migrations, migrate.Migration{Version: 3, Name: "broken", Up: "CREATE TABLE spells (id INT PRIMARY KEY); SELECT * FROM missing", Down: "DROP TABLE spells"})
+		migrator := migrate.NewMigrator(db, broken)
+		Expect(migrator.Up(ctx)).To(MatchError(ContainSubstring("failed to apply 3_broken")))
+		Expect(appliedVersions(migrator)).To(Equal([]int{1, 2}))
+		Expect(lockOwners()).To(Equal(0))
+	})
+
+	Context("When another replica holds the lock", func() {
+		var migrator *migrate.Migrator
+
+		BeforeEach(func() {
+			migrator = migrate.NewMigrator(db, migrations)
+			_, err := migrator.Status(ctx)
+			Expect(err).To(BeNil())
+		})
+
+		It("waits for the lock and gives up with its context", func() {
+			_, err := db.ExecContext(ctx, `INSERT INTO schema_migrations_lock (id, owner, locked_at) VALUES (1, 'other', now())`)
+			Expect(err).To(BeNil())
+			waitCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+			defer cancel()
+			Expect(migrator.Up(waitCtx)).To(MatchError(ContainSubstring("timed out waiting for migration lock")))
+			Expect(appliedVersions(migrator)).To(BeEmpty())
+			Expect(lockOwners()).To(Equal(1))
+		})
+
+		It("takes over the lock once it is stale", func() {
+			_, err := db.ExecContext(ctx, `INSERT INTO schema_migrations_lock (id, owner, locked_at) VALUES (1, 'crashed', now() - INTERVAL '1 hour')`)
+			Expect(err).To(BeNil())
+			Expect(migrator.Up(ctx)).To(Succeed())
+			Expect(appliedVersions(migrator)).To(Equal([]int{1, 2}))
+			Expect(lockOwners()).To(Equal(0))
+		})
+
+		It("runs once the lock is released", func() {
+			_, err := db.ExecContext(ctx, `INSERT INTO schema_migrations_lock (id, owner, locked_at) VALUES (1, 'other', now())`)
+			Expect(err).To(BeNil())
+			go func() {
+				defer GinkgoRecover()
+				time.Sleep(1500 * time.Millisecond)
+				_, err := db.ExecContext(context.Background(), `DELETE FROM schema_migrations_lock WHERE owner='other'`)
+				Expect(err).To(BeNil())
+			}()
+			Expect(migrator.Up(ctx)).To(Succeed())
+			Expect(appliedVersions(migrator)).To(Equal([]int{1, 2}))
+		})
+	})
+
+	Context("With migrations of the service", func() {
+		var migrator *migrate.Migrator
+
+		BeforeEach(func() {
+			service, err := migrate.Load(servicemigrations.FS)
+			Expect(err).To(BeNil())
+			migrator = migrate.NewMigrator(db, service)
+			Expect(migrator.To(ctx, 1)).To(Succeed())
+		})
+
+		insertUser := func(email, nickname string) {
+			_, err := db.ExecContext(ctx, `INSERT INTO users (id, first_name, last_name, nickname, password, email, country, created_at, updated_at)
+				VALUES ($1, 'Harry', 'Potter', $2, 'hash', $3, 'UK', now(), now())`, uuid.New(), nickname, email)
+			Expect(err).To(BeNil())
+		}
+
+		It("refuses unique emails and nicknames while users differ only by case", func() {
+			insertUser("harry@faceit.com", "hp")
+			insertUser("Harry@faceit.com", "HP")
+			insertUser("ron@faceit.com", "Ron")
+			Expect(migrator.Up(ctx)).To(MatchError(ContainSubstring("users hold 1 emails and 1 nicknames differing only by case")))
+			Expect(appliedVersions(migrator)).To(Equal([]int{1}))
+		})
+
+		It("applies all of them on distinct users", func() {
+			insertUser("harry@faceit.com", "hp")
+			insertUser("ron@faceit.com", "Ron")
+			Expect(migrator.Up(ctx)).To(Succeed())
+			Expect(migrator.To(ctx, 0)).To(Succeed())
+		})
+	})
+
+	It("rejects unknown version", func() {
+		Expect(migrate.NewMigrator(db, migrations).To(ctx, 3)).To(MatchError("migrate: unknown version 3"))
+	})
+})
@@ -0,0 +1,252 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"github.com/sirupsen/logrus"
+)
+
+//go:generate ~/go/bin/counterfeiter  . Notifier
+
+// Notifier - sink events are fanned out to
+type Notifier interface {
+	Notify(ctx context.Context, event core.Event) error
+}
+
+// Policy - how failure of a sink affects the whole notification
+type Policy string
+
+const (
+	// PolicyRequired - failure of the sink fails the notification, so it is retried
+	PolicyRequired Policy = "required"
+	// PolicyBestEffort - failure of the sink is only logged
+	PolicyBestEffort Policy = "best_effort"
+	// PolicyAsync - event is queued and sent in background. Events are dropped once the queue is full.
+	PolicyAsync Policy = "async"
+)
+
+const defaultQueueSize = 1000
+
+// ParsePolicy - parses the policy name. Empty name means PolicyRequired.
+func ParsePolicy(name string) (Policy, error) {
+	switch Policy(name) {
+	case "", PolicyRequired:
+		return PolicyRequired, nil
+	case PolicyBestEffort, PolicyAsync:
+		return Policy(name), nil
+	default:
+		return "", fmt.Errorf("notifier: unsupported policy %q", name)
+	}
+}
+
+// Sink - notifier along with the way the composite treats it
+type Sink struct {
+	// Name - identifies the sink in logs, errors and metrics
+	Name     string
+	Notifier Notifier
+	Policy   Policy
+	// Timeout - of a single notification, zero means no timeout other than the one of the caller
+	Timeout time.Duration
+	// QueueSize - capacity of the queue of async sink, defaults to 1000
+	QueueSize int
+}
+
+// sinkMetrics - counters of every sink are published under notifier_sinks expvar, keyed by sink name
+var sinkMetrics = expvar.NewMap("notifier_sinks")
+
+type sink struct {
+	Sink
+	queue chan core.Event
+
+	sent      expvar.Int
+	failed    expvar.Int
+	timedOut  expvar.Int
+	dropped   expvar.Int
+	latencyMS expvar.Int
+}
+
+func newSink(config Sink) *sink {
+	s := &sink{Sink: config}
+	metrics := new(expvar.Map).Init()
+	metrics.Set("sent", &s.sent)
+	metrics.Set("failed", &s.failed)
+	metrics.Set("timed_out", &s.timedOut)
+	metrics.Set("dropped", &s.dropped)
+	// latency_ms - total time spent notifying the sink, divide it by sent + failed for the average
+	metrics.Set("latency_ms", &s.latencyMS)
+	sinkMetrics.Set(config.Name, metrics)
+	return s
+}
+
+// Composite - fans out events to its sinks concurrently. Notify fails only if any of the required sinks fails.
+type Composite struct {
+	sinks   []*sink
+	workers sync.WaitGroup
+	// mu - guards queues of async sinks against being closed while events are queued
+	mu     sync.RWMutex
+	closed bool
+}
+
+func NewComposite(sinks ...Sink) *Composite {
+	c := &Composite{}
+	for _, config := range sinks {
+		s := newSink(config)
+		if s.Policy == PolicyAsync {
+			queueSize := s.QueueSize
+			if queueSize <= 0 {
+				queueSize = defaultQueueSize
+			}
+			s.queue = make(chan core.Event, queueSize)
+			c.workers.Add(1)
+			go c.work(s)
+		}
+		c.sinks = append(c.sinks, s)
+	}
+	return c
+}
+
+// Notify - queues the event for async sinks and waits for the rest of them
+func (c *Composite) Notify(ctx context.Context, event core.Event) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	errs := make([]error, len(c.sinks))
+	var wg sync.WaitGroup
+	for i, s := range c.sinks {
+		if s.Policy == PolicyAsync {
+			s.enqueue(ctx, event, c.closed)
+			continue
+		}
+		wg.Add(1)
+		go func(i int, s *sink) {
+			defer wg.Done()
+			err := s.notify(ctx, event)
+			if err == nil {
+				return
+			}
+			if s.Policy == PolicyRequired {
+				errs[i] = fmt.Errorf("%s: %w", s.Name, err)
+				return
+			}
+			logrus.WithContext(ctx).
+				WithError(err).
+				WithField("sink", s.Name).
+				WithField("event_id", event.ID).
+				Warn("notifier: best effort sink failed")
+		}(i, s)
+	}
+	wg.Wait()
+
+	var failed []error
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return &SinkError{Errors: failed}
+}
+
+// Shutdown - waits until async sinks send the queued events or ctx is done. Async sinks drop events
+// notified afterwards.
+func (c *Composite) Shutdown(ctx context.Context) error {
+	c.mu.Lock()
+	if !c.closed {
+		c.closed = true
+		for _, s := range c.sinks {
+			if s.queue != nil {
+				close(s.queue)
+			}
+		}
+	}
+	c.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		c.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *Composite) work(s *sink) {
+	defer c.workers.Done()
+	for event := range s.queue {
+		// Caller is long gone, hence the event is sent with the sink timeout only
+		err := s.notify(context.Background(), event)
+		if err != nil {
+			logrus.WithError(err).
+				WithField("sink", s.Name).
+				WithField("event_id", event.ID).
+				Warn("notifier: async sink failed")
+		}
+	}
+}
+
+func (s *sink) enqueue(ctx context.Context, event core.Event, closed bool) {
+	if closed {
+		s.dropped.Add(1)
+		return
+	}
+	select {
+	case s.queue <- event:
+	default:
+		s.dropped.Add(1)
+		logrus.WithContext(ctx).
+			WithField("sink", s.Name).
+			WithField("event_id", event.ID).
+			Warn("notifier: async sink queue is full, event dropped")
+	}
+}
+
+func (s *sink) notify(ctx context.Context, event core.Event) error {
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	err := s.Notifier.Notify(ctx, event)
+	s.latencyMS.Add(time.Since(start).Milliseconds())
+	if err != nil {
+		s.failed.Add(1)
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			s.timedOut.Add(1)
+		}
+		return err
+	}
+	s.sent.Add(1)
+	return nil
+}
+
+// SinkError - failures of required sinks
+type SinkError struct {
+	Errors []error
+}
+
+func (e *SinkError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+	return "notifier: required sinks failed: " + strings.Join(messages, "; ")
+}
+
+// Unwrap - the first failure, so errors.Is and errors.As see at least that one
+func (e *SinkError) Unwrap() error {
+	return e.Errors[0]
+}
@@ -0,0 +1,134 @@
+package notifier_test
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/notifier"
+	"com.user.com/user/internal/notifier/notifierfakes"
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Composite Notifier", func() {
+	var (
+		required   *notifierfakes.FakeNotifier
+		bestEffort *notifierfakes.FakeNotifier
+		async      *notifierfakes.FakeNotifier
+		composite  *notifier.Composite
+		event      core.Event
+		ctx        context.Context
+		err        error
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		required = &notifierfakes.FakeNotifier{}
+		bestEffort = &notifierfakes.FakeNotifier{}
+		async = &notifierfakes.FakeNotifier{}
+		event = core.NewUserCreatedEvent(core.SystemActor, core.User{ID: uuid.New()})
+	})
+
+	JustBeforeEach(func() {
+		composite = notifier.NewComposite(
+			notifier.Sink{Name: "test-required", Notifier: required, Policy: notifier.PolicyRequired, Timeout: 50 * time.Millisecond},
+			notifier.Sink{Name: "test-best-effort", Notifier: bestEffort, Policy: notifier.PolicyBestEffort},
+			notifier.Sink{Name: "test-async", Notifier: async, Policy: notifier.PolicyAsync, QueueSize: 1},
+		)
+		err = composite.Notify(ctx, event)
+	})
+
+	It("notifies every sink", func() {
+		Expect(err).To(BeNil())
+		Expect(composite.Shutdown(ctx)).To(Succeed())
+		for _, sink := range []*notifierfakes.FakeNotifier{required, bestEffort, async} {
+			Expect(sink.NotifyCallCount()).To(Equal(1))
+			_, notified := sink.NotifyArgsForCall(0)
+			Expect(notified.ID).To(Equal(event.ID))
+		}
+	})
+
+	Context("When best effort and async sinks fail", func() {
+		BeforeEach(func() {
+			bestEffort.NotifyReturns(errors.New("best-effort-error"))
+			async.NotifyReturns(errors.New("async-error"))
+		})
+		It("succeeds", func() {
+			Expect(err).To(BeNil())
+			Expect(composite.Shutdown(ctx)).To(Succeed())
+			Expect(sinkMetric("test-best-effort", "failed")).To(BeNumerically(">=", 1))
+		})
+	})
+
+	Context("When required sink fails", func() {
+		BeforeEach(func() {
+			required.NotifyReturns(errors.New("required-error"))
+		})
+		It("fails with error of the sink", func() {
+			var sinkErr *notifier.SinkError
+			Expect(errors.As(err, &sinkErr)).To(BeTrue())
+			Expect(err).To(MatchError(ContainSubstring("test-required: required-error")))
+			Expect(bestEffort.NotifyCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("When required sink times out", func() {
+		BeforeEach(func() {
+			required.NotifyStub = func(ctx context.Context, _ core.Event) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}
+		})
+		It("fails with deadline exceeded", func() {
+			Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+			Expect(sinkMetric("test-required", "timed_out")).To(BeNumerically(">=", 1))
+		})
+	})
+
+	Context("When queue of async sink is full", func() {
+		var release chan struct{}
+		BeforeEach(func() {
+			release = make(chan struct{})
+			async.NotifyStub = func(context.Context, core.Event) error {
+				<-release
+				return nil
+			}
+		})
+		It("drops the event rather than blocking", func() {
+			// First event is taken by the worker, second one fills the queue
+			Eventually(async.NotifyCallCount).Should(Equal(1))
+			Expect(composite.Notify(ctx, event)).To(Succeed())
+			Expect(composite.Notify(ctx, event)).To(Succeed())
+			Expect(sinkMetric("test-async", "dropped")).To(BeNumerically(">=", 1))
+
+			close(release)
+			Expect(composite.Shutdown(ctx)).To(Succeed())
+			Expect(async.NotifyCallCount()).To(Equal(2))
+		})
+	})
+
+	It("drops events notified after shutdown", func() {
+		Expect(composite.Shutdown(ctx)).To(Succeed())
+		Expect(composite.Notify(ctx, event)).To(Succeed())
+		Expect(async.NotifyCallCount()).To(Equal(1))
+	})
+})
+
+var _ = Describe("Parse Policy", func() {
+	It("defaults to required", func() {
+		Expect(notifier.ParsePolicy("")).To(Equal(notifier.PolicyRequired))
+	})
+	It("fails on unknown policy", func() {
+		_, err := notifier.ParsePolicy("sometimes")
+		Expect(err).ToNot(BeNil())
+	})
+})
+
+func sinkMetric(sink, name string) int64 {
+	metrics := expvar.Get("notifier_sinks").(*expvar.Map).Get(sink).(*expvar.Map)
+	return metrics.Get(name).(*expvar.Int).Value()
+}
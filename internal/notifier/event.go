@@ -0,0 +1,187 @@
+package notifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"github.com/google/uuid"
+)
+
+// Format - how events are serialized into message bodies
+type Format string
+
+const (
+	// FormatJSON - plain JSON envelope, see eventEnvelope
+	FormatJSON Format = "json"
+	// FormatCloudEvents - CloudEvents 1.0 in structured content mode
+	FormatCloudEvents Format = "cloudevents"
+)
+
+// Message attributes. They allow subscribers to filter events without decoding message bodies.
+const (
+	AttributeEventType     = "event_type"
+	AttributeEventID       = "event_id"
+	AttributeSchemaVersion = "schema_version"
+	AttributeUserID        = "user_id"
+	AttributeContentType   = "content_type"
+)
+
+const (
+	jsonContentType        = "application/json"
+	cloudEventsContentType = "application/cloudevents+json"
+	cloudEventsSpecVersion = "1.0"
+	cloudEventsSource      = "/user-service"
+)
+
+func ParseFormat(raw string) (Format, error) {
+	switch Format(raw) {
+	case "", FormatJSON:
+		return FormatJSON, nil
+	case FormatCloudEvents:
+		return FormatCloudEvents, nil
+	default:
+		return "", fmt.Errorf("notifier: unknown event format %q", raw)
+	}
+}
+
+type actorPayload struct {
+	ID   string `json:"id,omitempty"`
+	Type string `json:"type"`
+}
+
+type userPayload struct {
+	ID        uuid.UUID `json:"id"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Nickname  string    `json:"nickname"`
+	Email     string    `json:"email"`
+	Country   string    `json:"country"`
+	// EmailVerifiedAt - nil while the email is not verified
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+type changePayload struct {
+	Field    string `json:"field"`
+	Before   string `json:"before,omitempty"`
+	After    string `json:"after,omitempty"`
+	Redacted bool   `json:"redacted,omitempty"`
+}
+
+// eventData - user specific part of the event, shared by all formats
+type eventData struct {
+	UserID         uuid.UUID       `json:"user_id"`
+	Actor          actorPayload    `json:"actor"`
+	Before         *userPayload    `json:"before,omitempty"`
+	After          *userPayload    `json:"after,omitempty"`
+	Changes        []changePayload `json:"changes,omitempty"`
+	RedactedFields []string        `json:"redacted_fields,omitempty"`
+}
+
+type eventEnvelope struct {
+	ID            uuid.UUID `json:"id"`
+	Type          string    `json:"type"`
+	SchemaVersion int       `json:"schema_version"`
+	OccurredAt    time.Time `json:"occurred_at"`
+	Data          eventData `json:"data"`
+}
+
+type cloudEvent struct {
+	SpecVersion     string    `json:"specversion"`
+	ID              uuid.UUID `json:"id"`
+	Source          string    `json:"source"`
+	Type            string    `json:"type"`
+	Subject         string    `json:"subject"`
+	Time            time.Time `json:"time"`
+	DataContentType string    `json:"datacontenttype"`
+	DataSchema      string    `json:"dataschema"`
+	Data            eventData `json:"data"`
+}
+
+// EncodeEvent - serializes the event in given format and returns message attributes describing it
+func EncodeEvent(event core.Event, format Format) (body []byte, attributes map[string]string, err error) {
+	attributes = map[string]string{
+		AttributeEventType:     string(event.Type),
+		AttributeEventID:       event.ID.String(),
+		AttributeSchemaVersion: strconv.Itoa(event.SchemaVersion),
+		AttributeUserID:        event.UserID.String(),
+	}
+	data := newEventData(event)
+
+	switch format {
+	case FormatCloudEvents:
+		attributes[AttributeContentType] = cloudEventsContentType
+		body, err = json.Marshal(cloudEvent{
+			SpecVersion:     cloudEventsSpecVersion,
+			ID:              event.ID,
+			Source:          cloudEventsSource,
+			Type:            string(event.Type),
+			Subject:         event.UserID.String(),
+			Time:            event.OccurredAt,
+			DataContentType: jsonContentType,
+			DataSchema:      fmt.Sprintf("%s/schemas/user-event/v%d", cloudEventsSource, event.SchemaVersion),
+			Data:            data,
+		})
+	default:
+		attributes[AttributeContentType] = jsonContentType
+		body, err = json.Marshal(eventEnvelope{
+			ID:            event.ID,
+			Type:          string(event.Type),
+			SchemaVersion: event.SchemaVersion,
+			OccurredAt:    event.OccurredAt,
+			Data:          data,
+		})
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return body, attributes, nil
+}
+
+func newEventData(event core.Event) eventData {
+	data := eventData{
+		UserID: event.UserID,
+		Actor: actorPayload{
+			ID:   event.Actor.ID,
+			Type: string(event.Actor.Type),
+		},
+		Before: newUserPayload(event.Before),
+		After:  newUserPayload(event.After),
+	}
+	for _, change := range event.Changes {
+		data.Changes = append(data.Changes, changePayload{
+			Field:    string(change.Field),
+			Before:   change.Before,
+			After:    change.After,
+			Redacted: change.Redacted,
+		})
+	}
+	for _, field := range event.RedactedFields {
+		data.RedactedFields = append(data.RedactedFields, string(field))
+	}
+	return data
+}
+
+func newUserPayload(snapshot *core.UserSnapshot) *userPayload {
+	if snapshot == nil {
+		return nil
+	}
+	payload := &userPayload{
+		ID:        snapshot.ID,
+		FirstName: snapshot.FirstName,
+		LastName:  snapshot.LastName,
+		Nickname:  snapshot.Nickname,
+		Email:     snapshot.Email,
+		Country:   snapshot.Country,
+		CreatedAt: snapshot.CreatedAt,
+		UpdatedAt: snapshot.UpdatedAt,
+	}
+	if !snapshot.EmailVerifiedAt.IsZero() {
+		payload.EmailVerifiedAt = &snapshot.EmailVerifiedAt
+	}
+	return payload
+}
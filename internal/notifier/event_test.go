@@ -0,0 +1,68 @@
+package notifier_test
+
+import (
+	"encoding/json"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/notifier"
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Event encoding", func() {
+	var (
+		event      core.Event
+		format     notifier.Format
+		body       map[string]interface{}
+		attributes map[string]string
+		err        error
+	)
+
+	BeforeEach(func() {
+		before := core.User{ID: uuid.New(), Nickname: "jdoe", Password: "old-hash"}
+		after := before
+		after.Nickname = "johnny"
+		after.Password = "new-hash"
+		event = core.NewUserUpdatedEvent(core.Actor{ID: "admin", Type: core.ActorTypeUser}, before, after)
+		format = notifier.FormatJSON
+	})
+
+	JustBeforeEach(func() {
+		var raw []byte
+		raw, attributes, err = notifier.EncodeEvent(event, format)
+		Expect(err).To(BeNil())
+		body = nil
+		Expect(json.Unmarshal(raw, &body)).To(Succeed())
+	})
+
+	It("describes the event in message attributes", func() {
+		Expect(attributes).To(HaveKeyWithValue(notifier.AttributeEventType, "user.updated"))
+		Expect(attributes).To(HaveKeyWithValue(notifier.AttributeEventID, event.ID.String()))
+		Expect(attributes).To(HaveKeyWithValue(notifier.AttributeSchemaVersion, "1"))
+		Expect(attributes).To(HaveKeyWithValue(notifier.AttributeContentType, "application/json"))
+	})
+	It("never exposes passwords", func() {
+		data := body["data"].(map[string]interface{})
+		Expect(data["before"]).ToNot(HaveKey("password"))
+		Expect(data["after"]).ToNot(HaveKey("password"))
+		Expect(data["changes"]).To(ContainElement(map[string]interface{}{"field": "password", "redacted": true}))
+		Expect(data["redacted_fields"]).To(ConsistOf("password"))
+	})
+	It("carries the actor", func() {
+		data := body["data"].(map[string]interface{})
+		Expect(data["actor"]).To(Equal(map[string]interface{}{"id": "admin", "type": "user"}))
+	})
+
+	Context("In CloudEvents format", func() {
+		BeforeEach(func() {
+			format = notifier.FormatCloudEvents
+		})
+		It("produces structured CloudEvent", func() {
+			Expect(body["specversion"]).To(Equal("1.0"))
+			Expect(body["type"]).To(Equal("user.updated"))
+			Expect(body["subject"]).To(Equal(event.UserID.String()))
+			Expect(attributes).To(HaveKeyWithValue(notifier.AttributeContentType, "application/cloudevents+json"))
+		})
+	})
+})
@@ -0,0 +1,39 @@
+package notifier
+
+import (
+	"context"
+
+	"com.user.com/user/internal/core"
+	"github.com/sirupsen/logrus"
+)
+
+// LogNotifier - logs events, handy for local development and auditing
+type LogNotifier struct {
+	level logrus.Level
+}
+
+func NewLogNotifier(level logrus.Level) *LogNotifier {
+	return &LogNotifier{
+		level: level,
+	}
+}
+
+func (l *LogNotifier) Notify(ctx context.Context, event core.Event) error {
+	logrus.WithContext(ctx).
+		WithField("event_id", event.ID).
+		WithField("event_type", event.Type).
+		WithField("user_id", event.UserID).
+		WithField("actor", event.Actor.ID).
+		WithField("changes", len(event.Changes)).
+		Log(l.level, "user event")
+	return nil
+}
+
+// Nop - discards events. It takes place of notifier when notifications are disabled.
+var Nop Notifier = nopNotifier{}
+
+type nopNotifier struct{}
+
+func (nopNotifier) Notify(context.Context, core.Event) error {
+	return nil
+}
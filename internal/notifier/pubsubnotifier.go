@@ -2,23 +2,55 @@ package notifier
 
 import (
 	"context"
+	"fmt"
 
+	"com.user.com/user/internal/core"
 	"gocloud.dev/pubsub"
+
+	// Drivers of supported brokers, see OpenPubSubNotifier
+	_ "gocloud.dev/pubsub/gcppubsub"
+	_ "gocloud.dev/pubsub/kafkapubsub"
+	_ "gocloud.dev/pubsub/mempubsub"
+	_ "gocloud.dev/pubsub/natspubsub"
+	_ "gocloud.dev/pubsub/rabbitpubsub"
 )
 
 type PubSubNotifier struct {
-	topic pubsub.Topic
+	topic  *pubsub.Topic
+	format Format
 }
 
-func NewPubSubNotifier(topic pubsub.Topic) *PubSubNotifier {
+func NewPubSubNotifier(topic *pubsub.Topic, format Format) *PubSubNotifier {
 	return &PubSubNotifier{
-		topic: topic,
+		topic:  topic,
+		format: format,
+	}
+}
+
+// OpenPubSubNotifier - opens the topic by its URL, so the broker is picked by URL scheme:
+// gcppubsub://projects/<project>/topics/<topic>, kafka://<topic>, nats://<subject>, rabbit://<exchange> or mem://<topic>.
+// Brokers are located through driver specific env variables, e.g. KAFKA_BROKERS, NATS_SERVER_URL or RABBIT_SERVER_URL.
+func OpenPubSubNotifier(ctx context.Context, topicURL string, format Format) (*PubSubNotifier, error) {
+	topic, err := pubsub.OpenTopic(ctx, topicURL)
+	if err != nil {
+		return nil, fmt.Errorf("notifier: failed to open topic %q: %w", topicURL, err)
 	}
+	return NewPubSubNotifier(topic, format), nil
 }
 
-func (p *PubSubNotifier) NotifySubscriber(ctx context.Context, msg string) error {
-	err := p.topic.Send(ctx, &pubsub.Message{
-		Body: []byte(msg),
+// Notify - publishes the event. Event type and id are sent as message attributes as well.
+func (p *PubSubNotifier) Notify(ctx context.Context, event core.Event) error {
+	body, attributes, err := EncodeEvent(event, p.format)
+	if err != nil {
+		return err
+	}
+	return p.topic.Send(ctx, &pubsub.Message{
+		Body:     body,
+		Metadata: attributes,
 	})
-	return err
+}
+
+// Shutdown - flushes pending messages and closes the topic
+func (p *PubSubNotifier) Shutdown(ctx context.Context) error {
+	return p.topic.Shutdown(ctx)
 }
@@ -0,0 +1,45 @@
+package notifier_test
+
+import (
+	"context"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/notifier"
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gocloud.dev/pubsub"
+)
+
+var _ = Describe("PubSub Notifier", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("publishes events to the topic given by URL", func() {
+		pubsubNotifier, err := notifier.OpenPubSubNotifier(ctx, "mem://user-events", notifier.FormatJSON)
+		Expect(err).To(BeNil())
+		defer pubsubNotifier.Shutdown(ctx)
+		subscription, err := pubsub.OpenSubscription(ctx, "mem://user-events")
+		Expect(err).To(BeNil())
+		defer subscription.Shutdown(ctx)
+
+		event := core.NewUserCreatedEvent(core.SystemActor, core.User{ID: uuid.New()})
+		Expect(pubsubNotifier.Notify(ctx, event)).To(Succeed())
+
+		receiveCtx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		msg, err := subscription.Receive(receiveCtx)
+		Expect(err).To(BeNil())
+		msg.Ack()
+		Expect(msg.Metadata).To(HaveKeyWithValue(notifier.AttributeEventID, event.ID.String()))
+	})
+
+	It("fails on unsupported URL scheme", func() {
+		_, err := notifier.OpenPubSubNotifier(ctx, "unknown://user-events", notifier.FormatJSON)
+		Expect(err).ToNot(BeNil())
+	})
+})
@@ -0,0 +1,55 @@
+package password
+
+import (
+	"errors"
+)
+
+var (
+	ErrMalformedHash       = errors.New("password hash is malformed")
+	ErrIncompatibleVersion = errors.New("password hash version is incompatible")
+	ErrUnknownAlgorithm    = errors.New("password hash algorithm is unknown")
+)
+
+// Algorithm - single hashing algorithm which is able to recognise its own hashes.
+type Algorithm interface {
+	Hash(password string) (string, error)
+	Verify(password, encodedHash string) (bool, error)
+	NeedsRehash(encodedHash string) bool
+	Identifies(encodedHash string) bool
+}
+
+// Hasher - hashes new passwords with the primary algorithm and verifies hashes produced by
+// any of the configured algorithms. That allows switching algorithms (or their cost) without
+// invalidating stored passwords - they get rehashed on the next successful login.
+type Hasher struct {
+	primary    Algorithm
+	algorithms []Algorithm
+}
+
+func NewHasher(primary Algorithm, fallbacks ...Algorithm) *Hasher {
+	return &Hasher{
+		primary:    primary,
+		algorithms: append([]Algorithm{primary}, fallbacks...),
+	}
+}
+
+func (h *Hasher) Hash(password string) (string, error) {
+	return h.primary.Hash(password)
+}
+
+func (h *Hasher) Verify(password, encodedHash string) (bool, error) {
+	for _, a := range h.algorithms {
+		if a.Identifies(encodedHash) {
+			return a.Verify(password, encodedHash)
+		}
+	}
+	return false, ErrUnknownAlgorithm
+}
+
+// NeedsRehash - true when encodedHash is produced by fallback algorithm or with outdated parameters.
+func (h *Hasher) NeedsRehash(encodedHash string) bool {
+	if !h.primary.Identifies(encodedHash) {
+		return true
+	}
+	return h.primary.NeedsRehash(encodedHash)
+}
@@ -0,0 +1,124 @@
+package password_test
+
+import (
+	"strings"
+
+	"com.user.com/user/internal/password"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var _ = Describe("Password Hasher", func() {
+	var (
+		argon2idParams password.Argon2idParams
+		hasher         *password.Hasher
+	)
+
+	BeforeEach(func() {
+		// Cheap parameters keep the suite fast
+		argon2idParams = password.Argon2idParams{
+			Memory:      1024,
+			Iterations:  1,
+			Parallelism: 1,
+			SaltLength:  16,
+			KeyLength:   32,
+		}
+		hasher = password.NewHasher(
+			password.NewArgon2idHasher(argon2idParams),
+			password.NewBcryptHasher(bcrypt.MinCost),
+		)
+	})
+
+	Context("Hash", func() {
+		It("encodes algorithm and parameters in the hash", func() {
+			hash, err := hasher.Hash("secret")
+			Expect(err).To(BeNil())
+			Expect(hash).To(HavePrefix("$argon2id$v=19$m=1024,t=1,p=1$"))
+			Expect(hash).ToNot(ContainSubstring("secret"))
+		})
+		It("salts every hash", func() {
+			first, _ := hasher.Hash("secret")
+			second, _ := hasher.Hash("secret")
+			Expect(first).ToNot(Equal(second))
+		})
+	})
+
+	Context("Verify", func() {
+		It("accepts the right password", func() {
+			hash, _ := hasher.Hash("secret")
+			ok, err := hasher.Verify("secret", hash)
+			Expect(err).To(BeNil())
+			Expect(ok).To(BeTrue())
+		})
+		It("rejects a wrong password", func() {
+			hash, _ := hasher.Hash("secret")
+			ok, err := hasher.Verify("not-secret", hash)
+			Expect(err).To(BeNil())
+			Expect(ok).To(BeFalse())
+		})
+		It("verifies hashes of fallback algorithms", func() {
+			hash, _ := password.NewBcryptHasher(bcrypt.MinCost).Hash("secret")
+			ok, err := hasher.Verify("secret", hash)
+			Expect(err).To(BeNil())
+			Expect(ok).To(BeTrue())
+		})
+		It("fails on unknown algorithms", func() {
+			_, err := hasher.Verify("secret", "plaintext")
+			Expect(err).To(Equal(password.ErrUnknownAlgorithm))
+		})
+		It("fails on malformed hashes", func() {
+			hash, _ := hasher.Hash("secret")
+			_, err := hasher.Verify("secret", strings.TrimSuffix(hash, hash[strings.LastIndex(hash, "$"):]))
+			Expect(err).To(Equal(password.ErrMalformedHash))
+		})
+	})
+
+	Context("With plaintext fallback", func() {
+		BeforeEach(func() {
+			hasher = password.NewHasher(
+				password.NewArgon2idHasher(argon2idParams),
+				password.NewBcryptHasher(bcrypt.MinCost),
+				password.NewPlaintextHasher(),
+			)
+		})
+		It("verifies passwords stored before hashing", func() {
+			ok, err := hasher.Verify("secret", "secret")
+			Expect(err).To(BeNil())
+			Expect(ok).To(BeTrue())
+			ok, err = hasher.Verify("not-secret", "secret")
+			Expect(err).To(BeNil())
+			Expect(ok).To(BeFalse())
+		})
+		It("still verifies hashes by their own algorithms", func() {
+			hash, _ := hasher.Hash("secret")
+			ok, err := hasher.Verify(hash, hash)
+			Expect(err).To(BeNil())
+			Expect(ok).To(BeFalse())
+		})
+		It("needs rehash of plaintext passwords", func() {
+			Expect(hasher.NeedsRehash("secret")).To(BeTrue())
+		})
+	})
+
+	Context("NeedsRehash", func() {
+		It("is false for hashes produced with current settings", func() {
+			hash, _ := hasher.Hash("secret")
+			Expect(hasher.NeedsRehash(hash)).To(BeFalse())
+		})
+		It("is true when argon2id parameters have changed", func() {
+			hash, _ := hasher.Hash("secret")
+			argon2idParams.Iterations = 2
+			stronger := password.NewHasher(password.NewArgon2idHasher(argon2idParams))
+			Expect(stronger.NeedsRehash(hash)).To(BeTrue())
+		})
+		It("is true for hashes of fallback algorithms", func() {
+			hash, _ := password.NewBcryptHasher(bcrypt.MinCost).Hash("secret")
+			Expect(hasher.NeedsRehash(hash)).To(BeTrue())
+		})
+		It("is true when bcrypt cost has changed", func() {
+			hash, _ := password.NewBcryptHasher(bcrypt.MinCost).Hash("secret")
+			Expect(password.NewBcryptHasher(bcrypt.MinCost + 1).NeedsRehash(hash)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,34 @@
+package password
+
+import (
+	"crypto/subtle"
+	"errors"
+)
+
+var errPlaintextHash = errors.New("plaintext passwords are verified only, never stored")
+
+// PlaintextHasher - verifies passwords stored before hashing has been introduced. It identifies whatever other
+// algorithms do not, hence it goes last among fallbacks. Such passwords always need rehash, so they are replaced
+// by hashes on the next successful login.
+type PlaintextHasher struct{}
+
+func NewPlaintextHasher() *PlaintextHasher {
+	return &PlaintextHasher{}
+}
+
+func (p *PlaintextHasher) Hash(string) (string, error) {
+	return "", errPlaintextHash
+}
+
+func (p *PlaintextHasher) Verify(password, encodedHash string) (bool, error) {
+	return subtle.ConstantTimeCompare([]byte(password), []byte(encodedHash)) == 1, nil
+}
+
+func (p *PlaintextHasher) NeedsRehash(string) bool {
+	return true
+}
+
+// Identifies - any non empty value, users have never been stored without a password
+func (p *PlaintextHasher) Identifies(encodedHash string) bool {
+	return encodedHash != ""
+}
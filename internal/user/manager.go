@@ -5,6 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"com.user.com/user/internal/core"
 	"github.com/google/uuid"
@@ -12,94 +15,453 @@ import (
 )
 
 //go:generate ~/go/bin/counterfeiter  . UserStore
+//go:generate ~/go/bin/counterfeiter  . PasswordHasher
+//go:generate ~/go/bin/counterfeiter  . Notifier
 
 type UserStore interface {
-	SaveUser(ctx context.Context, user core.User) error
-	UpdateUser(ctx context.Context, user core.User) error
-	DeleteUser(ctx context.Context, id uuid.UUID) error
-	GetAllUsers(ctx context.Context, filter core.UserFilter) (users []*core.User, previousPage, nextPage string, total int, err error)
+	// Write methods store the event built by newEvent along with the user, in the same transaction.
+	// Nil newEvent stores no event.
+	SaveUser(ctx context.Context, user core.User, newEvent core.EventBuilder) (core.User, error)
+	UpdateUser(ctx context.Context, user core.User, version time.Time, newEvent core.EventBuilder) (core.User, error)
+	UpdateUserFields(ctx context.Context, user core.User, fields []core.UserField, version time.Time, newEvent core.EventBuilder) (core.User, error)
+	// SaveEmailVerificationToken - stores the token and uses up all other tokens of its user
+	SaveEmailVerificationToken(ctx context.Context, token core.EmailVerificationToken) error
+	// VerifyEmail - uses up the token with such hash and marks the email of the user as verified, as long as neither
+	// the user nor its email have changed since given version. Returns core.NotFoundError if there is no unused and
+	// unexpired token of the user and its email with such hash.
+	VerifyEmail(ctx context.Context, user core.User, tokenHash string, version time.Time, newEvent core.EventBuilder) (core.User, error)
+	// ResetPassword - uses up the password reset token with such hash and all other ones of the user, replaces the
+	// password and revokes all refresh tokens of the user, as long as it has not changed since given version. Returns
+	// core.NotFoundError if there is no unused and unexpired token of the user with such hash.
+	ResetPassword(ctx context.Context, user core.User, tokenHash string, version time.Time, newEvent core.EventBuilder) (core.User, error)
+	DeleteUser(ctx context.Context, id uuid.UUID, version time.Time, newEvent core.EventBuilder) error
+	GetUserByID(ctx context.Context, id uuid.UUID) (core.User, error)
+	GetUserByEmail(ctx context.Context, email string) (core.User, error)
+	GetAllUsers(ctx context.Context, filter core.UserFilter) (core.UserPage, error)
 }
 
+// PasswordHasher - one way password hashing. Encoded hashes carry the algorithm and its
+// parameters, so NeedsRehash can tell whether a stored hash is produced with outdated settings.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(password, encodedHash string) (bool, error)
+	NeedsRehash(encodedHash string) bool
+}
+
+// Notifier - publishes domain events to subscribers
 type Notifier interface {
-	NotifySubscriber(ctx context.Context, msg string) error
+	Notify(ctx context.Context, event core.Event) error
 }
 
+var (
+	errInvalidEmail       = &core.ValidationError{Field: "email", Message: "invalid email"}
+	errInvalidCredentials = &core.UnauthorizedError{Message: "invalid email or password"}
+)
+
+// Bounds of users filter, longer search terms and lists are expensive to match, as well as larger pages
+const (
+	maxQueryLength  = 100
+	maxFilterIDs    = 100
+	maxFilterValues = 50
+	defaultLimit    = 100
+	maxLimit        = 500
+)
+
 type Manager struct {
-	userStore    UserStore
-	notifier     Notifier
-	shouldNotify bool
+	userStore      UserStore
+	passwordHasher PasswordHasher
+	emailVerifier  EmailVerifier
 }
 
-func NewManager(userStore UserStore, notifier Notifier, shouldNotify bool) *Manager {
+// NewManager - events of user changes are always stored in the outbox, they are published by Relay.
+// Relay with notifier.Nop discards them.
+func NewManager(userStore UserStore, passwordHasher PasswordHasher, emailVerifier EmailVerifier) *Manager {
 	return &Manager{
-		userStore:    userStore,
-		notifier:     notifier,
-		shouldNotify: shouldNotify,
+		userStore:      userStore,
+		passwordHasher: passwordHasher,
+		emailVerifier:  emailVerifier,
 	}
 }
 
-func (m *Manager) CreateUser(ctx context.Context, user core.User) error {
+// CreateUser - returns persisted user, including its generated ID and timestamps. Anyone may sign up, services
+// need the write scope. Created users are regular ones. The user is mailed a token verifying its email.
+func (m *Manager) CreateUser(ctx context.Context, actor core.Actor, user core.User) (core.User, error) {
+	if err := authorizeCreate(actor); err != nil {
+		return core.User{}, err
+	}
 	user.ID = uuid.New()
+	user.Role = core.RoleUser
+	user.EmailVerifiedAt = time.Time{}
 	if !m.isEmailValid(user.Email) {
-		return errors.New("invalid email")
+		return core.User{}, errInvalidEmail
 	}
-	err := m.userStore.SaveUser(ctx, user)
+	hash, err := m.passwordHasher.Hash(user.Password)
 	if err != nil {
-		return err
+		return core.User{}, err
 	}
-	if m.shouldNotify {
-		err = m.notifier.NotifySubscriber(ctx, fmt.Sprintf("User has been created: %v", user))
-		if err != nil {
-			logrus.WithContext(ctx).
-				WithError(err).
-				Error("user.manager: error while notifying subscribers for user creation")
-		}
+	user.Password = hash
+	created, err := m.userStore.SaveUser(ctx, user, func(stored core.User) core.Event {
+		return core.NewUserCreatedEvent(actor, stored)
+	})
+	if err != nil {
+		return core.User{}, err
 	}
-	return nil
+	m.sendVerification(ctx, created)
+	return created, nil
 }
 
-func (m *Manager) ModifyUser(ctx context.Context, user core.User) error {
+// ModifyUser - replaces user as long as it has not been modified since given version (its updated_at).
+// Zero version skips the check. Returns modified user along with its new version. Changed email has to be
+// verified again.
+func (m *Manager) ModifyUser(ctx context.Context, actor core.Actor, user core.User, version time.Time) (core.User, error) {
+	if err := authorizeManage(actor, user.ID, core.ScopeUsersWrite); err != nil {
+		return core.User{}, err
+	}
 	if !m.isEmailValid(user.Email) {
-		return errors.New("invalid email")
+		return core.User{}, errInvalidEmail
 	}
-	err := m.userStore.UpdateUser(ctx, user)
+	// Current state is needed to tell what has changed
+	current, err := m.currentUser(ctx, user.ID, version)
 	if err != nil {
-		return err
+		return core.User{}, err
 	}
-	if m.shouldNotify {
-		err = m.notifier.NotifySubscriber(ctx, fmt.Sprintf("User has been updated: %v", user))
+	// Re-sent password keeps its hash, so it is not reported as changed
+	samePassword, err := m.passwordHasher.Verify(user.Password, current.Password)
+	if err != nil || !samePassword {
+		user.Password, err = m.passwordHasher.Hash(user.Password)
 		if err != nil {
-			logrus.WithContext(ctx).
-				WithError(err).
-				Error("user.manager: error while notifying subscribers for user update")
+			return core.User{}, err
 		}
+	} else {
+		user.Password = current.Password
 	}
-	return nil
+	user.Role = current.Role
+	user.EmailVerifiedAt = verifiedAt(current, user.Email)
+
+	modified, err := m.userStore.UpdateUser(ctx, user, current.UpdatedAt, func(stored core.User) core.Event {
+		return core.NewUserUpdatedEvent(actor, current, stored)
+	})
+	if err != nil {
+		return core.User{}, err
+	}
+	if modified.Email != current.Email {
+		m.sendVerification(ctx, modified)
+	}
+	return modified, nil
 }
 
-func (m *Manager) RemoveUser(ctx context.Context, id uuid.UUID) error {
-	err := m.userStore.DeleteUser(ctx, id)
+// RemoveUser - deletes user as long as it has not been modified since given version (its updated_at).
+// Zero version skips the check.
+func (m *Manager) RemoveUser(ctx context.Context, actor core.Actor, id uuid.UUID, version time.Time) error {
+	if err := authorizeManage(actor, id, core.ScopeUsersDelete); err != nil {
+		return err
+	}
+	current, err := m.currentUser(ctx, id, version)
 	if err != nil {
 		return err
 	}
-	if m.shouldNotify {
-		err = m.notifier.NotifySubscriber(ctx, fmt.Sprintf("User has been deleted: %s", id))
+	return m.userStore.DeleteUser(ctx, id, current.UpdatedAt, func(core.User) core.Event {
+		return core.NewUserDeletedEvent(actor, current)
+	})
+}
+
+// PatchUser - loads the user, applies the patch on it and stores only the changed fields, as long as the
+// user has not been modified since given version. Zero version skips the check.
+func (m *Manager) PatchUser(ctx context.Context, actor core.Actor, id uuid.UUID, version time.Time, patch core.UserPatch) (core.User, error) {
+	if err := authorizeManage(actor, id, core.ScopeUsersWrite); err != nil {
+		return core.User{}, err
+	}
+	current, err := m.currentUser(ctx, id, version)
+	if err != nil {
+		return core.User{}, err
+	}
+
+	patched, err := patch.Apply(current)
+	if err != nil {
+		return core.User{}, err
+	}
+	// Identity, role and timestamps are not subject of patching
+	patched.ID = current.ID
+	patched.Role = current.Role
+	patched.EmailVerifiedAt = verifiedAt(current, patched.Email)
+	patched.CreatedAt = current.CreatedAt
+	patched.UpdatedAt = current.UpdatedAt
+
+	fields := core.ChangedFields(current, patched)
+	if len(fields) == 0 {
+		return current, nil
+	}
+	err = m.validateUser(patched)
+	if err != nil {
+		return core.User{}, err
+	}
+	if patched.Password != current.Password {
+		patched.Password, err = m.passwordHasher.Hash(patched.Password)
 		if err != nil {
-			logrus.WithContext(ctx).
-				WithError(err).
-				Error("user.manager: error while notifying subscribers for user deletion")
+			return core.User{}, err
 		}
 	}
-	return nil
+
+	// Current version guards against modifications which happened after the user has been loaded
+	stored, err := m.userStore.UpdateUserFields(ctx, patched, fields, current.UpdatedAt, func(stored core.User) core.Event {
+		return core.NewUserUpdatedEvent(actor, current, stored)
+	})
+	if err != nil {
+		return core.User{}, err
+	}
+	if stored.Email != current.Email {
+		m.sendVerification(ctx, stored)
+	}
+	return stored, nil
 }
 
-func (m *Manager) GetAllUsers(ctx context.Context, filter core.UserFilter) (users []*core.User, previousPage, nextPage string, total int, err error) {
+// ResetPassword - replaces the password of the user, who has proven its identity by the password reset token with
+// such hash. Token is used up and all sessions of the user are ended along with the password being replaced.
+// Stores user password changed event rather than user updated one. Returns core.NotFoundError if the token is not
+// usable.
+func (m *Manager) ResetPassword(ctx context.Context, actor core.Actor, id uuid.UUID, tokenHash, password string) (core.User, error) {
+	if err := authorizeManage(actor, id, core.ScopeUsersWrite); err != nil {
+		return core.User{}, err
+	}
+	if password == "" {
+		return core.User{}, &core.ValidationError{Field: string(core.UserFieldPassword), Message: "password is required"}
+	}
+	current, err := m.userStore.GetUserByID(ctx, id)
+	if err != nil {
+		return core.User{}, err
+	}
+	reset := current
+	reset.Password, err = m.passwordHasher.Hash(password)
+	if err != nil {
+		return core.User{}, err
+	}
+	return m.userStore.ResetPassword(ctx, reset, tokenHash, current.UpdatedAt, func(stored core.User) core.Event {
+		return core.NewUserPasswordChangedEvent(actor, current, stored)
+	})
+}
+
+// VerifyEmail - confirms the email of the user by the token it has been mailed. Anyone holding the token may
+// confirm it, the token itself proves the ownership. Token works once and only the token mailed last works.
+// Returns core.ConflictError if the email has already been verified.
+func (m *Manager) VerifyEmail(ctx context.Context, actor core.Actor, id uuid.UUID, token string) (core.User, error) {
+	current, err := m.userStore.GetUserByID(ctx, id)
+	if err != nil {
+		return core.User{}, err
+	}
+	if current.EmailVerified() {
+		return core.User{}, &core.ConflictError{Message: "email has already been verified"}
+	}
+	verified, err := m.userStore.VerifyEmail(ctx, current, hashVerificationToken(token), current.UpdatedAt, func(stored core.User) core.Event {
+		return core.NewUserUpdatedEvent(actor, current, stored)
+	})
+	var notFoundErr *core.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		// Either the token is not usable or the user has been deleted in the meantime
+		return core.User{}, errInvalidVerificationToken
+	}
+	if err != nil {
+		return core.User{}, err
+	}
+	return verified, nil
+}
+
+// Authenticate - returns the user with given email and password. Unknown email and wrong password are reported the
+// same way, as core.UnauthorizedError. So is a stored hash which cannot be verified, e.g. of an unknown algorithm,
+// which is logged. Password hashed with outdated settings is rehashed.
+func (m *Manager) Authenticate(ctx context.Context, email, password string) (core.User, error) {
+	current, err := m.userStore.GetUserByEmail(ctx, email)
+	var notFoundErr *core.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		// Hashing takes about as long as verification, so response time does not tell whether the email exists
+		_, _ = m.passwordHasher.Hash(password)
+		return core.User{}, errInvalidCredentials
+	}
+	if err != nil {
+		return core.User{}, err
+	}
+	valid, err := m.passwordHasher.Verify(password, current.Password)
+	if err != nil {
+		logrus.WithContext(ctx).
+			WithError(err).
+			WithField("user_id", current.ID).
+			Error("failed to verify password hash")
+		return core.User{}, errInvalidCredentials
+	}
+	if !valid {
+		return core.User{}, errInvalidCredentials
+	}
+	if !m.passwordHasher.NeedsRehash(current.Password) {
+		return current, nil
+	}
+
+	// Rehashing is not a change of the user, hence no event. Login does not depend on it.
+	rehashed := current
+	rehashed.Password, err = m.passwordHasher.Hash(password)
+	if err == nil {
+		rehashed, err = m.userStore.UpdateUserFields(ctx, rehashed, []core.UserField{core.UserFieldPassword}, current.UpdatedAt, nil)
+	}
+	if err != nil {
+		logrus.WithContext(ctx).
+			WithError(err).
+			WithField("user_id", current.ID).
+			Warn("failed to rehash password")
+		return current, nil
+	}
+	return rehashed, nil
+}
+
+// GetUserByID - returns core.NotFoundError if there is no such user
+func (m *Manager) GetUserByID(ctx context.Context, actor core.Actor, id uuid.UUID) (core.User, error) {
+	if err := authorizeRead(actor); err != nil {
+		return core.User{}, err
+	}
+	return m.userStore.GetUserByID(ctx, id)
+}
+
+// GetUserByEmail - returns core.NotFoundError if there is no such user
+func (m *Manager) GetUserByEmail(ctx context.Context, actor core.Actor, email string) (core.User, error) {
+	if err := authorizeRead(actor); err != nil {
+		return core.User{}, err
+	}
+	return m.userStore.GetUserByEmail(ctx, email)
+}
+
+func (m *Manager) GetAllUsers(ctx context.Context, actor core.Actor, filter core.UserFilter) (core.UserPage, error) {
+	if err := authorizeRead(actor); err != nil {
+		return core.UserPage{}, err
+	}
 	if filter.PreviousPage != "" && filter.NextPage != "" {
-		return nil, "", "", 0, errors.New("either next or previous page should be provided")
+		return core.UserPage{}, &core.ValidationError{Message: "either next or previous page should be provided"}
+	}
+	filter.Query = strings.TrimSpace(filter.Query)
+	if filter.Count == "" {
+		filter.Count = core.CountExact
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultLimit
+	}
+	if err := validateFilter(filter); err != nil {
+		return core.UserPage{}, err
 	}
 	return m.userStore.GetAllUsers(ctx, filter)
 }
 
+// sendVerification - stores a new verification token, which uses up the former ones of the user, and mails it.
+// The user has been stored by then, so failure does not fail the request, it is only logged.
+func (m *Manager) sendVerification(ctx context.Context, user core.User) {
+	token, stored, err := m.emailVerifier.NewToken(user)
+	if err == nil {
+		err = m.userStore.SaveEmailVerificationToken(ctx, stored)
+	}
+	if err == nil {
+		err = m.emailVerifier.SendVerification(ctx, user, token)
+	}
+	if err != nil {
+		logrus.WithContext(ctx).
+			WithError(err).
+			WithField("user_id", user.ID).
+			Warn("failed to send email verification")
+	}
+}
+
+// verifiedAt - verification of the current email is kept, any other email is not verified
+func verifiedAt(current core.User, email string) time.Time {
+	if email != current.Email {
+		return time.Time{}
+	}
+	return current.EmailVerifiedAt
+}
+
+// currentUser - loads the user and checks it has not been modified since given version.
+// Zero version skips the check.
+func (m *Manager) currentUser(ctx context.Context, id uuid.UUID, version time.Time) (core.User, error) {
+	current, err := m.userStore.GetUserByID(ctx, id)
+	if err != nil {
+		return core.User{}, err
+	}
+	if !version.IsZero() && !current.UpdatedAt.Equal(version) {
+		return core.User{}, &core.PreconditionFailedError{Message: "user has been modified in the meantime"}
+	}
+	return current, nil
+}
+
+func validateFilter(filter core.UserFilter) error {
+	if utf8.RuneCountInString(filter.Query) > maxQueryLength {
+		return &core.ValidationError{Field: "q", Message: fmt.Sprintf("search term must be at most %d characters long", maxQueryLength)}
+	}
+	if filter.Limit < 1 || filter.Limit > maxLimit {
+		return &core.ValidationError{Field: "limit", Message: fmt.Sprintf("limit must be between 1 and %d", maxLimit)}
+	}
+	if !filter.Count.Valid() {
+		return &core.ValidationError{Field: "count", Message: fmt.Sprintf("unknown count mode %q", filter.Count)}
+	}
+	if len(filter.IDs) > maxFilterIDs {
+		return &core.ValidationError{Field: "ids", Message: fmt.Sprintf("at most %d ids can be looked up at once", maxFilterIDs)}
+	}
+	conditions := []struct {
+		field     string
+		condition core.StringCondition
+	}{
+		{"country", filter.Country},
+		{"first_name", filter.FirstName},
+		{"last_name", filter.LastName},
+		{"nickname", filter.Nickname},
+		{"email_domain", filter.EmailDomain},
+	}
+	for _, c := range conditions {
+		if len(c.condition.In)+len(c.condition.NotIn) > maxFilterValues {
+			return &core.ValidationError{Field: c.field, Message: fmt.Sprintf("at most %d values can be matched", maxFilterValues)}
+		}
+	}
+	sorted := make(map[core.UserSortField]bool, len(filter.Sort))
+	for _, by := range filter.Sort {
+		if !by.Field.Valid() {
+			return &core.ValidationError{Field: "sort", Message: fmt.Sprintf("users cannot be sorted by %q", by.Field)}
+		}
+		if sorted[by.Field] {
+			return &core.ValidationError{Field: "sort", Message: fmt.Sprintf("users are sorted by %q more than once", by.Field)}
+		}
+		sorted[by.Field] = true
+	}
+	ranges := []struct {
+		field     string
+		timeRange core.TimeRange
+	}{
+		{"created_at", filter.CreatedAt},
+		{"updated_at", filter.UpdatedAt},
+	}
+	for _, r := range ranges {
+		if r.timeRange.IsEmpty() {
+			return &core.ValidationError{Field: r.field, Message: "lower bound of the range must precede its upper bound"}
+		}
+	}
+	return nil
+}
+
+// validateUser - checks the whole user, since a patch may touch any of its fields
+func (m *Manager) validateUser(user core.User) error {
+	required := []struct {
+		field core.UserField
+		value string
+	}{
+		{core.UserFieldFirstName, user.FirstName},
+		{core.UserFieldLastName, user.LastName},
+		{core.UserFieldNickname, user.Nickname},
+		{core.UserFieldPassword, user.Password},
+		{core.UserFieldEmail, user.Email},
+		{core.UserFieldCountry, user.Country},
+	}
+	for _, r := range required {
+		if r.value == "" {
+			return &core.ValidationError{Field: string(r.field), Message: fmt.Sprintf("%s is required", r.field)}
+		}
+	}
+	if !m.isEmailValid(user.Email) {
+		return errInvalidEmail
+	}
+	return nil
+}
+
 func (m *Manager) isEmailValid(e string) bool {
 	emailRegex := regexp.MustCompile(`^[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,4}$`)
 	return emailRegex.MatchString(e)
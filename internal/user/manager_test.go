@@ -2,37 +2,79 @@ package user_test
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"strings"
+	"time"
 
 	"com.user.com/user/internal/core"
 	"com.user.com/user/internal/user"
 	"com.user.com/user/internal/user/userfakes"
 	"github.com/google/uuid"
 	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 )
 
 var _ = Describe("User Manager", func() {
 	var (
-		userStore *userfakes.FakeUserStore
-		manager   user.Manager
-		ctx       context.Context
+		userStore      *userfakes.FakeUserStore
+		passwordHasher *userfakes.FakePasswordHasher
+		emailVerifier  *userfakes.FakeEmailVerifier
+		manager        user.Manager
+		actor          core.Actor
+		ctx            context.Context
 	)
 
 	BeforeEach(func() {
 		userStore = &userfakes.FakeUserStore{}
-		manager = *user.NewManager(userStore)
+		passwordHasher = &userfakes.FakePasswordHasher{}
+		passwordHasher.HashReturns("hashed-password", nil)
+		emailVerifier = &userfakes.FakeEmailVerifier{}
+		emailVerifier.NewTokenReturns("mailed-token", core.EmailVerificationToken{TokenHash: "hashed-token"}, nil)
+		actor = core.Actor{ID: uuid.New().String(), Type: core.ActorTypeUser, Role: core.RoleAdmin}
 		ctx = context.Background()
 	})
 
+	JustBeforeEach(func() {
+		manager = *user.NewManager(userStore, passwordHasher, emailVerifier)
+	})
+
 	Context("Create User", func() {
 		var (
-			user core.User
-			err  error
+			user        core.User
+			createdUser core.User
+			err         error
 		)
 
+		BeforeEach(func() {
+			actor = core.Actor{Type: core.ActorTypeAnonymous}
+		})
 		JustBeforeEach(func() {
-			err = manager.CreateUser(ctx, user)
+			createdUser, err = manager.CreateUser(ctx, actor, user)
+		})
+
+		Context("When actor is a service without write scope", func() {
+			BeforeEach(func() {
+				actor = core.Actor{ID: uuid.New().String(), Type: core.ActorTypeService, Scopes: []core.Scope{core.ScopeUsersRead}}
+				user = core.User{Email: "test@faceit.com", Password: "plaintext"}
+			})
+			It("fails with forbidden error", func() {
+				Expect(err).To(BeAssignableToTypeOf(&core.ForbiddenError{}))
+				Expect(userStore.SaveUserCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("When actor is a service with write scope", func() {
+			BeforeEach(func() {
+				actor = core.Actor{ID: uuid.New().String(), Type: core.ActorTypeService, Scopes: []core.Scope{core.ScopeUsersWrite}}
+				user = core.User{Email: "test@faceit.com", Password: "plaintext"}
+			})
+			It("creates the user", func() {
+				Expect(err).To(BeNil())
+				Expect(userStore.SaveUserCallCount()).To(Equal(1))
+			})
 		})
 
 		Context("With invalid email", func() {
@@ -45,38 +87,159 @@ var _ = Describe("User Manager", func() {
 				Expect(err).ToNot(BeNil())
 				Expect(err.Error()).To(Equal("invalid email"))
 			})
+			It("reports a validation error of email field", func() {
+				var validationErr *core.ValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				Expect(validationErr.Field).To(Equal("email"))
+			})
 		})
 
 		Context("With valid mail", func() {
 			BeforeEach(func() {
 				user = core.User{
-					Email: "test@faceit.com",
+					Email:    "test@faceit.com",
+					Password: "plaintext",
 				}
 			})
+			Context("When password hashing fails", func() {
+				BeforeEach(func() {
+					passwordHasher.HashReturns("", errors.New("hash-error"))
+				})
+				It("fails to create user without touching the store", func() {
+					Expect(err).ToNot(BeNil())
+					Expect(err.Error()).To(Equal("hash-error"))
+					Expect(userStore.SaveUserCallCount()).To(Equal(0))
+				})
+			})
 			Context("When store returns an error", func() {
 				BeforeEach(func() {
-					userStore.SaveUserReturns(errors.New("test-error"))
+					userStore.SaveUserReturns(core.User{}, errors.New("test-error"))
 				})
 				It("fails to create user due error in db", func() {
 					Expect(err).ToNot(BeNil())
 					Expect(err.Error()).To(Equal("test-error"))
 				})
 			})
+			Context("When user with such email already exists", func() {
+				BeforeEach(func() {
+					userStore.SaveUserReturns(core.User{}, &core.ConflictError{Field: "email", Message: "user with such email already exists"})
+				})
+				It("fails with conflict error of email field", func() {
+					var conflictErr *core.ConflictError
+					Expect(errors.As(err, &conflictErr)).To(BeTrue())
+					Expect(conflictErr.Field).To(Equal("email"))
+				})
+			})
 			Context("Successfully created user", func() {
+				var createdAt time.Time
+				BeforeEach(func() {
+					createdAt = time.Now()
+					userStore.SaveUserStub = func(_ context.Context, u core.User, _ core.EventBuilder) (core.User, error) {
+						u.CreatedAt = createdAt
+						u.UpdatedAt = createdAt
+						return u, nil
+					}
+				})
 				It("creates user successfully", func() {
 					Expect(err).To(BeNil())
 				})
+				It("returns persisted user with generated id and timestamps", func() {
+					_, savedUser, _ := userStore.SaveUserArgsForCall(0)
+					Expect(createdUser.ID).ToNot(Equal(uuid.Nil))
+					Expect(createdUser.ID).To(Equal(savedUser.ID))
+					Expect(createdUser.CreatedAt).To(Equal(createdAt))
+					Expect(createdUser.UpdatedAt).To(Equal(createdAt))
+				})
+				It("stores regular user", func() {
+					_, savedUser, _ := userStore.SaveUserArgsForCall(0)
+					Expect(savedUser.Role).To(Equal(core.RoleUser))
+				})
+				It("stores hashed password", func() {
+					Expect(passwordHasher.HashArgsForCall(0)).To(Equal("plaintext"))
+					_, savedUser, _ := userStore.SaveUserArgsForCall(0)
+					Expect(savedUser.Password).To(Equal("hashed-password"))
+				})
+				It("stores user created event along with the user", func() {
+					_, _, newEvent := userStore.SaveUserArgsForCall(0)
+					event := newEvent(createdUser)
+					Expect(event.Type).To(Equal(core.EventUserCreated))
+					Expect(event.UserID).To(Equal(createdUser.ID))
+					Expect(event.Before).To(BeNil())
+					Expect(event.After.Email).To(Equal("test@faceit.com"))
+					Expect(event.After.CreatedAt).To(Equal(createdAt))
+					Expect(event.Actor.Type).To(Equal(core.ActorTypeAnonymous))
+				})
+				It("mails verification of the email to the user", func() {
+					Expect(emailVerifier.SendVerificationCallCount()).To(Equal(1))
+					_, mailed, token := emailVerifier.SendVerificationArgsForCall(0)
+					Expect(mailed.ID).To(Equal(createdUser.ID))
+					Expect(mailed.EmailVerified()).To(BeFalse())
+					Expect(token).To(Equal("mailed-token"))
+				})
+				It("stores the mailed token", func() {
+					Expect(emailVerifier.NewTokenArgsForCall(0).ID).To(Equal(createdUser.ID))
+					Expect(userStore.SaveEmailVerificationTokenCallCount()).To(Equal(1))
+					_, stored := userStore.SaveEmailVerificationTokenArgsForCall(0)
+					Expect(stored.TokenHash).To(Equal("hashed-token"))
+				})
+				Context("When verification token cannot be stored", func() {
+					BeforeEach(func() {
+						userStore.SaveEmailVerificationTokenReturns(errors.New("test-error"))
+					})
+					It("creates user anyway and mails nothing", func() {
+						Expect(err).To(BeNil())
+						Expect(createdUser.ID).ToNot(Equal(uuid.Nil))
+						Expect(emailVerifier.SendVerificationCallCount()).To(Equal(0))
+					})
+				})
+				Context("When verification cannot be mailed", func() {
+					BeforeEach(func() {
+						emailVerifier.SendVerificationReturns(errors.New("smtp-error"))
+					})
+					It("creates user anyway", func() {
+						Expect(err).To(BeNil())
+						Expect(createdUser.ID).ToNot(Equal(uuid.Nil))
+					})
+				})
+			})
+			Context("When store fails", func() {
+				BeforeEach(func() {
+					userStore.SaveUserReturns(core.User{}, errors.New("test-error"))
+				})
+				It("mails nothing", func() {
+					Expect(emailVerifier.SendVerificationCallCount()).To(Equal(0))
+				})
 			})
 		})
 	})
 	Context("Modify User", func() {
 		var (
-			user core.User
-			err  error
+			user    core.User
+			current core.User
+			version time.Time
+			err     error
 		)
 
+		BeforeEach(func() {
+			version = time.Now()
+			current = core.User{
+				ID:        uuid.New(),
+				Nickname:  "jdoe",
+				Password:  "stored-hash",
+				Email:     "test@faceit.com",
+				Role:      core.RoleAdmin,
+				UpdatedAt: version,
+				// Verified a while ago
+				EmailVerifiedAt: version.Add(-time.Hour),
+			}
+			userStore.GetUserByIDReturns(current, nil)
+			userStore.UpdateUserStub = func(_ context.Context, u core.User, _ time.Time, _ core.EventBuilder) (core.User, error) {
+				return u, nil
+			}
+		})
+
 		JustBeforeEach(func() {
-			err = manager.ModifyUser(ctx, user)
+			_, err = manager.ModifyUser(ctx, actor, user, version)
 		})
 
 		Context("With invalid email", func() {
@@ -94,41 +257,279 @@ var _ = Describe("User Manager", func() {
 		Context("With valid mail", func() {
 			BeforeEach(func() {
 				user = core.User{
-					Email: "test@faceit.com",
+					ID:       current.ID,
+					Nickname: "johnny",
+					Password: "new-password",
+					Email:    "test@faceit.com",
 				}
 			})
+			Context("When user does not exist", func() {
+				BeforeEach(func() {
+					userStore.GetUserByIDReturns(core.User{}, &core.NotFoundError{Entity: "user"})
+				})
+				It("fails with not found error", func() {
+					Expect(err).To(BeAssignableToTypeOf(&core.NotFoundError{}))
+					Expect(userStore.UpdateUserCallCount()).To(Equal(0))
+				})
+			})
+			Context("When user has been modified since given version", func() {
+				BeforeEach(func() {
+					version = version.Add(-time.Second)
+				})
+				It("fails with precondition failed error", func() {
+					Expect(err).To(BeAssignableToTypeOf(&core.PreconditionFailedError{}))
+					Expect(userStore.UpdateUserCallCount()).To(Equal(0))
+				})
+			})
 			Context("When store returns an error", func() {
 				BeforeEach(func() {
-					userStore.UpdateUserReturns(errors.New("test-error"))
+					userStore.UpdateUserStub = nil
+					userStore.UpdateUserReturns(core.User{}, errors.New("test-error"))
 				})
 				It("fails to modify user due error in db", func() {
 					Expect(err).ToNot(BeNil())
 					Expect(err.Error()).To(Equal("test-error"))
 				})
 			})
+			Context("When user with such nickname already exists", func() {
+				BeforeEach(func() {
+					userStore.UpdateUserStub = nil
+					userStore.UpdateUserReturns(core.User{}, &core.ConflictError{Field: "nickname", Message: "user with such nickname already exists"})
+				})
+				It("fails with conflict error of nickname field", func() {
+					var conflictErr *core.ConflictError
+					Expect(errors.As(err, &conflictErr)).To(BeTrue())
+					Expect(conflictErr.Field).To(Equal("nickname"))
+				})
+			})
+			Context("When user has been modified in the meantime", func() {
+				BeforeEach(func() {
+					userStore.UpdateUserStub = nil
+					userStore.UpdateUserReturns(core.User{}, &core.PreconditionFailedError{Message: "stale"})
+				})
+				It("fails with precondition failed error", func() {
+					Expect(err).To(BeAssignableToTypeOf(&core.PreconditionFailedError{}))
+				})
+			})
 			Context("Successfully modified user", func() {
 				It("modifies user successfully", func() {
 					Expect(err).To(BeNil())
 				})
+				It("stores hashed password", func() {
+					_, updatedUser, _, _ := userStore.UpdateUserArgsForCall(0)
+					Expect(updatedUser.Password).To(Equal("hashed-password"))
+				})
+				It("passes expected version to the store", func() {
+					_, _, expectedVersion, _ := userStore.UpdateUserArgsForCall(0)
+					Expect(expectedVersion).To(Equal(version))
+				})
+				It("keeps the role of the user", func() {
+					_, updatedUser, _, _ := userStore.UpdateUserArgsForCall(0)
+					Expect(updatedUser.Role).To(Equal(core.RoleAdmin))
+				})
+				It("keeps verification of unchanged email", func() {
+					_, updatedUser, _, _ := userStore.UpdateUserArgsForCall(0)
+					Expect(updatedUser.EmailVerifiedAt).To(Equal(current.EmailVerifiedAt))
+					Expect(emailVerifier.SendVerificationCallCount()).To(Equal(0))
+				})
+				It("stores user updated event with redacted password change", func() {
+					_, updatedUser, _, newEvent := userStore.UpdateUserArgsForCall(0)
+					event := newEvent(updatedUser)
+					Expect(event.Type).To(Equal(core.EventUserUpdated))
+					Expect(event.Before.Nickname).To(Equal("jdoe"))
+					Expect(event.After.Nickname).To(Equal("johnny"))
+					Expect(event.Changes).To(ConsistOf(
+						core.FieldChange{Field: core.UserFieldNickname, Before: "jdoe", After: "johnny"},
+						core.FieldChange{Field: core.UserFieldPassword, Redacted: true},
+					))
+				})
+			})
+			Context("When email changes", func() {
+				BeforeEach(func() {
+					user.Email = "new@faceit.com"
+				})
+				It("stores unverified email and mails its verification", func() {
+					Expect(err).To(BeNil())
+					_, updatedUser, _, _ := userStore.UpdateUserArgsForCall(0)
+					Expect(updatedUser.EmailVerified()).To(BeFalse())
+					Expect(emailVerifier.SendVerificationCallCount()).To(Equal(1))
+					_, mailed, _ := emailVerifier.SendVerificationArgsForCall(0)
+					Expect(mailed.Email).To(Equal("new@faceit.com"))
+				})
+			})
+			Context("When the same password is sent again", func() {
+				BeforeEach(func() {
+					passwordHasher.VerifyReturns(true, nil)
+				})
+				It("keeps the stored hash and does not report password change", func() {
+					Expect(err).To(BeNil())
+					Expect(passwordHasher.HashCallCount()).To(Equal(0))
+					_, updatedUser, _, _ := userStore.UpdateUserArgsForCall(0)
+					Expect(updatedUser.Password).To(Equal("stored-hash"))
+					_, _, _, newEvent := userStore.UpdateUserArgsForCall(0)
+					event := newEvent(updatedUser)
+					Expect(event.Changes).To(Equal([]core.FieldChange{
+						{Field: core.UserFieldNickname, Before: "jdoe", After: "johnny"},
+					}))
+				})
 			})
 
 		})
 	})
 	Context("Get All Users", func() {
 		var (
-			filter       core.UserFilter
-			users        []*core.User
-			previousPage string
-			nextPage     string
-			total        int
-			err          error
+			filter core.UserFilter
+			page   core.UserPage
+			err    error
 		)
+		BeforeEach(func() {
+			filter = core.UserFilter{}
+		})
 		JustBeforeEach(func() {
-			users, previousPage, nextPage, total, err = manager.GetAllUsers(ctx, filter)
+			page, err = manager.GetAllUsers(ctx, actor, filter)
+		})
+		Context("When actor is anonymous", func() {
+			BeforeEach(func() {
+				actor = core.Actor{Type: core.ActorTypeAnonymous}
+			})
+			It("fails with unauthorized error", func() {
+				Expect(err).To(BeAssignableToTypeOf(&core.UnauthorizedError{}))
+				Expect(userStore.GetAllUsersCallCount()).To(Equal(0))
+			})
+		})
+		Context("When actor is a service without read scope", func() {
+			BeforeEach(func() {
+				actor = core.Actor{ID: uuid.New().String(), Type: core.ActorTypeService, Scopes: []core.Scope{core.ScopeUsersWrite}}
+			})
+			It("fails with forbidden error", func() {
+				Expect(err).To(BeAssignableToTypeOf(&core.ForbiddenError{}))
+				Expect(userStore.GetAllUsersCallCount()).To(Equal(0))
+			})
+		})
+		Context("When both next and previous pages are provided", func() {
+			BeforeEach(func() {
+				filter = core.UserFilter{NextPage: "next_page", PreviousPage: "previous_page"}
+			})
+			It("fails with validation error", func() {
+				Expect(err).To(BeAssignableToTypeOf(&core.ValidationError{}))
+				Expect(userStore.GetAllUsersCallCount()).To(Equal(0))
+			})
+		})
+		Context("When search term is too long", func() {
+			BeforeEach(func() {
+				filter = core.UserFilter{Query: strings.Repeat("a", 101)}
+			})
+			It("fails with validation error of q param", func() {
+				var validationErr *core.ValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				Expect(validationErr.Field).To(Equal("q"))
+				Expect(userStore.GetAllUsersCallCount()).To(Equal(0))
+			})
+		})
+		Context("When count mode is unknown", func() {
+			BeforeEach(func() {
+				filter = core.UserFilter{Count: "approximate"}
+			})
+			It("fails with validation error of count param", func() {
+				var validationErr *core.ValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				Expect(validationErr.Field).To(Equal("count"))
+				Expect(userStore.GetAllUsersCallCount()).To(Equal(0))
+			})
+		})
+		Context("When too many ids are looked up", func() {
+			BeforeEach(func() {
+				filter = core.UserFilter{IDs: make([]uuid.UUID, 101)}
+			})
+			It("fails with validation error of ids param", func() {
+				var validationErr *core.ValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				Expect(validationErr.Field).To(Equal("ids"))
+			})
+		})
+		Context("When too many values are matched", func() {
+			BeforeEach(func() {
+				filter = core.UserFilter{Country: core.StringCondition{
+					In:    make([]string, 30),
+					NotIn: make([]string, 30),
+				}}
+			})
+			It("fails with validation error of the field", func() {
+				var validationErr *core.ValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				Expect(validationErr.Field).To(Equal("country"))
+			})
+		})
+		table.DescribeTable("When limit is out of range",
+			func(limit int) {
+				listed := userStore.GetAllUsersCallCount()
+				_, err := manager.GetAllUsers(ctx, actor, core.UserFilter{Limit: limit})
+				var validationErr *core.ValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				Expect(validationErr.Field).To(Equal("limit"))
+				Expect(userStore.GetAllUsersCallCount()).To(Equal(listed))
+			},
+			table.Entry("negative", -1),
+			table.Entry("over max", 501),
+		)
+		Context("When users are sorted by unknown field", func() {
+			BeforeEach(func() {
+				filter = core.UserFilter{Sort: []core.UserSort{{Field: "password"}}}
+			})
+			It("fails with validation error of sort param", func() {
+				var validationErr *core.ValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				Expect(validationErr.Field).To(Equal("sort"))
+				Expect(userStore.GetAllUsersCallCount()).To(Equal(0))
+			})
+		})
+		Context("When users are sorted by the same field twice", func() {
+			BeforeEach(func() {
+				filter = core.UserFilter{Sort: []core.UserSort{
+					{Field: core.UserSortLastName},
+					{Field: core.UserSortLastName, Descending: true},
+				}}
+			})
+			It("fails with validation error of sort param", func() {
+				var validationErr *core.ValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				Expect(validationErr.Field).To(Equal("sort"))
+			})
+		})
+		Context("When time range is empty", func() {
+			BeforeEach(func() {
+				now := time.Now()
+				filter = core.UserFilter{CreatedAt: core.TimeRange{From: now, Before: now}}
+			})
+			It("fails with validation error of the field", func() {
+				var validationErr *core.ValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				Expect(validationErr.Field).To(Equal("created_at"))
+				Expect(userStore.GetAllUsersCallCount()).To(Equal(0))
+			})
+		})
+		Context("When time range is bounded by the same inclusive bounds", func() {
+			BeforeEach(func() {
+				now := time.Now()
+				filter = core.UserFilter{UpdatedAt: core.TimeRange{From: now, Until: now}}
+			})
+			It("gets users", func() {
+				Expect(err).To(BeNil())
+				Expect(userStore.GetAllUsersCallCount()).To(Equal(1))
+			})
+		})
+		Context("When search term is surrounded by spaces", func() {
+			BeforeEach(func() {
+				filter = core.UserFilter{Query: "  jon "}
+			})
+			It("searches by the trimmed term", func() {
+				_, searched := userStore.GetAllUsersArgsForCall(0)
+				Expect(searched.Query).To(Equal("jon"))
+			})
 		})
 		Context("When store returns an error", func() {
 			BeforeEach(func() {
-				userStore.GetAllUsersReturns(nil, "", "", 0, errors.New("test-error"))
+				userStore.GetAllUsersReturns(core.UserPage{}, errors.New("test-error"))
 			})
 			It("fails to get a slice of users due error in db", func() {
 				Expect(err).ToNot(BeNil())
@@ -137,23 +538,504 @@ var _ = Describe("User Manager", func() {
 		})
 		Context("When store succeeds", func() {
 			BeforeEach(func() {
-				userStore.GetAllUsersReturns([]*core.User{
-					{}, {}, {},
-				}, "previous_page", "next_page", 100, nil)
+				total := 100
+				userStore.GetAllUsersReturns(core.UserPage{
+					Users:        []*core.User{{}, {}, {}},
+					PreviousPage: "previous_page",
+					NextPage:     "next_page",
+					HasMore:      true,
+					Total:        &total,
+				}, nil)
 			})
 			It("fetch slice of users successfully", func() {
 				Expect(err).To(BeNil())
-				Expect(users).To(HaveLen(3))
-				Expect(previousPage).To(Equal("previous_page"))
-				Expect(nextPage).To(Equal("next_page"))
-				Expect(total).To(Equal(100))
+				Expect(page.Users).To(HaveLen(3))
+				Expect(page.PreviousPage).To(Equal("previous_page"))
+				Expect(page.NextPage).To(Equal("next_page"))
+				Expect(page.HasMore).To(BeTrue())
+				Expect(*page.Total).To(Equal(100))
+			})
+			It("counts users exactly by default", func() {
+				_, listed := userStore.GetAllUsersArgsForCall(0)
+				Expect(listed.Count).To(Equal(core.CountExact))
+			})
+			It("gets default page size unless limited", func() {
+				_, listed := userStore.GetAllUsersArgsForCall(0)
+				Expect(listed.Limit).To(Equal(100))
+			})
+		})
+	})
+	Context("Patch User", func() {
+		var (
+			current     core.User
+			version     time.Time
+			patch       core.UserPatch
+			patchedUser core.User
+			err         error
+		)
+
+		BeforeEach(func() {
+			version = time.Now()
+			current = core.User{
+				ID:        uuid.New(),
+				FirstName: "John",
+				LastName:  "Doe",
+				Nickname:  "jdoe",
+				Password:  "stored-hash",
+				Email:     "test@faceit.com",
+				Country:   "BG",
+				UpdatedAt: version,
+			}
+			userStore.GetUserByIDReturns(current, nil)
+			userStore.UpdateUserFieldsStub = func(_ context.Context, u core.User, _ []core.UserField, _ time.Time, _ core.EventBuilder) (core.User, error) {
+				return u, nil
+			}
+			patch = core.UserPatchFunc(func(u core.User) (core.User, error) {
+				u.Nickname = "johnny"
+				return u, nil
+			})
+		})
+
+		JustBeforeEach(func() {
+			patchedUser, err = manager.PatchUser(ctx, actor, current.ID, version, patch)
+		})
+
+		Context("When actor is another regular user", func() {
+			BeforeEach(func() {
+				actor = core.Actor{ID: uuid.New().String(), Type: core.ActorTypeUser, Role: core.RoleUser}
+			})
+			It("fails with forbidden error", func() {
+				Expect(err).To(BeAssignableToTypeOf(&core.ForbiddenError{}))
+				Expect(userStore.UpdateUserFieldsCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("When user does not exist", func() {
+			BeforeEach(func() {
+				userStore.GetUserByIDReturns(core.User{}, &core.NotFoundError{Entity: "user"})
+			})
+			It("fails with not found error", func() {
+				Expect(err).To(BeAssignableToTypeOf(&core.NotFoundError{}))
+				Expect(userStore.UpdateUserFieldsCallCount()).To(Equal(0))
+			})
+		})
+		Context("When user has been modified since given version", func() {
+			BeforeEach(func() {
+				version = version.Add(-time.Second)
+			})
+			It("fails with precondition failed error", func() {
+				Expect(err).To(BeAssignableToTypeOf(&core.PreconditionFailedError{}))
+				Expect(userStore.UpdateUserFieldsCallCount()).To(Equal(0))
+			})
+		})
+		Context("When patch cannot be applied", func() {
+			BeforeEach(func() {
+				patch = core.UserPatchFunc(func(u core.User) (core.User, error) {
+					return core.User{}, &core.ValidationError{Message: "bad patch"}
+				})
+			})
+			It("fails with the patch error", func() {
+				Expect(err).To(BeAssignableToTypeOf(&core.ValidationError{}))
+			})
+		})
+		Context("When patched user is invalid", func() {
+			BeforeEach(func() {
+				patch = core.UserPatchFunc(func(u core.User) (core.User, error) {
+					u.FirstName = ""
+					return u, nil
+				})
+			})
+			It("fails with validation error of the field", func() {
+				var validationErr *core.ValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				Expect(validationErr.Field).To(Equal("first_name"))
+				Expect(userStore.UpdateUserFieldsCallCount()).To(Equal(0))
+			})
+		})
+		Context("When patch changes nothing", func() {
+			BeforeEach(func() {
+				patch = core.UserPatchFunc(func(u core.User) (core.User, error) {
+					return u, nil
+				})
+			})
+			It("returns current user without touching the store", func() {
+				Expect(err).To(BeNil())
+				Expect(patchedUser).To(Equal(current))
+				Expect(userStore.UpdateUserFieldsCallCount()).To(Equal(0))
+			})
+		})
+		Context("When patch changes the password", func() {
+			BeforeEach(func() {
+				patch = core.UserPatchFunc(func(u core.User) (core.User, error) {
+					u.Password = "new-password"
+					return u, nil
+				})
+			})
+			It("stores hashed password", func() {
+				Expect(err).To(BeNil())
+				Expect(passwordHasher.HashArgsForCall(0)).To(Equal("new-password"))
+				_, storedUser, fields, _, _ := userStore.UpdateUserFieldsArgsForCall(0)
+				Expect(storedUser.Password).To(Equal("hashed-password"))
+				Expect(fields).To(Equal([]core.UserField{core.UserFieldPassword}))
+			})
+		})
+		Context("When patch changes the email", func() {
+			BeforeEach(func() {
+				patch = core.UserPatchFunc(func(u core.User) (core.User, error) {
+					u.Email = "new@faceit.com"
+					u.EmailVerifiedAt = time.Now()
+					return u, nil
+				})
+			})
+			It("stores unverified email and mails its verification", func() {
+				Expect(err).To(BeNil())
+				_, storedUser, fields, _, _ := userStore.UpdateUserFieldsArgsForCall(0)
+				Expect(fields).To(Equal([]core.UserField{core.UserFieldEmail}))
+				Expect(storedUser.EmailVerified()).To(BeFalse())
+				Expect(emailVerifier.SendVerificationCallCount()).To(Equal(1))
+			})
+		})
+		Context("When patch is valid", func() {
+			It("updates only changed fields guarded by current version", func() {
+				Expect(err).To(BeNil())
+				Expect(patchedUser.Nickname).To(Equal("johnny"))
+				_, storedUser, fields, storedVersion, _ := userStore.UpdateUserFieldsArgsForCall(0)
+				Expect(storedUser.ID).To(Equal(current.ID))
+				Expect(fields).To(Equal([]core.UserField{core.UserFieldNickname}))
+				Expect(storedVersion).To(Equal(current.UpdatedAt))
+				Expect(passwordHasher.HashCallCount()).To(Equal(0))
+			})
+			It("stores user updated event with the changed field", func() {
+				_, storedUser, _, _, newEvent := userStore.UpdateUserFieldsArgsForCall(0)
+				event := newEvent(storedUser)
+				Expect(event.Type).To(Equal(core.EventUserUpdated))
+				Expect(event.Changes).To(Equal([]core.FieldChange{
+					{Field: core.UserFieldNickname, Before: "jdoe", After: "johnny"},
+				}))
+			})
+		})
+	})
+	Context("Get User By ID", func() {
+		var (
+			id   uuid.UUID
+			user core.User
+			err  error
+		)
+		BeforeEach(func() {
+			id = uuid.New()
+		})
+		JustBeforeEach(func() {
+			user, err = manager.GetUserByID(ctx, actor, id)
+		})
+		Context("When user does not exist", func() {
+			BeforeEach(func() {
+				userStore.GetUserByIDReturns(core.User{}, &core.NotFoundError{Entity: "user", Key: id.String()})
+			})
+			It("fails with not found error", func() {
+				Expect(err).To(BeAssignableToTypeOf(&core.NotFoundError{}))
+			})
+		})
+		Context("When store succeeds", func() {
+			BeforeEach(func() {
+				userStore.GetUserByIDReturns(core.User{ID: id}, nil)
+			})
+			It("fetches the user by its id", func() {
+				Expect(err).To(BeNil())
+				Expect(user.ID).To(Equal(id))
+				_, requestedID := userStore.GetUserByIDArgsForCall(0)
+				Expect(requestedID).To(Equal(id))
+			})
+		})
+	})
+	Context("Get User By Email", func() {
+		var (
+			user core.User
+			err  error
+		)
+		JustBeforeEach(func() {
+			user, err = manager.GetUserByEmail(ctx, actor, "test@faceit.com")
+		})
+		Context("When user does not exist", func() {
+			BeforeEach(func() {
+				userStore.GetUserByEmailReturns(core.User{}, &core.NotFoundError{Entity: "user"})
+			})
+			It("fails with not found error", func() {
+				Expect(err).To(BeAssignableToTypeOf(&core.NotFoundError{}))
+			})
+		})
+		Context("When store succeeds", func() {
+			BeforeEach(func() {
+				userStore.GetUserByEmailReturns(core.User{Email: "test@faceit.com"}, nil)
+			})
+			It("fetches the user by its email", func() {
+				Expect(err).To(BeNil())
+				Expect(user.Email).To(Equal("test@faceit.com"))
+			})
+		})
+	})
+	Context("Authenticate", func() {
+		var (
+			current       core.User
+			authenticated core.User
+			err           error
+		)
+		BeforeEach(func() {
+			current = core.User{ID: uuid.New(), Email: "test@faceit.com", Password: "stored-hash", UpdatedAt: time.Now()}
+			userStore.GetUserByEmailReturns(current, nil)
+			userStore.UpdateUserFieldsStub = func(_ context.Context, u core.User, _ []core.UserField, _ time.Time, _ core.EventBuilder) (core.User, error) {
+				return u, nil
+			}
+			passwordHasher.VerifyReturns(true, nil)
+		})
+		JustBeforeEach(func() {
+			authenticated, err = manager.Authenticate(ctx, "test@faceit.com", "secret")
+		})
+		Context("When user does not exist", func() {
+			BeforeEach(func() {
+				userStore.GetUserByEmailReturns(core.User{}, &core.NotFoundError{Entity: "user"})
+			})
+			It("fails with unauthorized error", func() {
+				Expect(err).To(BeAssignableToTypeOf(&core.UnauthorizedError{}))
+				Expect(err.Error()).To(Equal("invalid email or password"))
+			})
+		})
+		Context("When password is wrong", func() {
+			BeforeEach(func() {
+				passwordHasher.VerifyReturns(false, nil)
+			})
+			It("fails with the same unauthorized error", func() {
+				Expect(err).To(BeAssignableToTypeOf(&core.UnauthorizedError{}))
+				Expect(err.Error()).To(Equal("invalid email or password"))
+			})
+		})
+		Context("When stored hash cannot be verified", func() {
+			BeforeEach(func() {
+				passwordHasher.VerifyReturns(false, errors.New("unknown password hash algorithm"))
+			})
+			It("fails with the same unauthorized error", func() {
+				Expect(err).To(BeAssignableToTypeOf(&core.UnauthorizedError{}))
+				Expect(err.Error()).To(Equal("invalid email or password"))
+				Expect(userStore.UpdateUserFieldsCallCount()).To(Equal(0))
+			})
+		})
+		Context("When password is right", func() {
+			It("returns the user without rehashing its password", func() {
+				Expect(err).To(BeNil())
+				Expect(authenticated).To(Equal(current))
+				password, hash := passwordHasher.VerifyArgsForCall(0)
+				Expect(password).To(Equal("secret"))
+				Expect(hash).To(Equal("stored-hash"))
+				Expect(userStore.UpdateUserFieldsCallCount()).To(Equal(0))
+			})
+		})
+		Context("When password hash is outdated", func() {
+			BeforeEach(func() {
+				passwordHasher.NeedsRehashReturns(true)
+			})
+			It("stores the new hash of the password without an event", func() {
+				Expect(err).To(BeNil())
+				Expect(authenticated.Password).To(Equal("hashed-password"))
+				Expect(userStore.UpdateUserFieldsCallCount()).To(Equal(1))
+				_, _, fields, version, newEvent := userStore.UpdateUserFieldsArgsForCall(0)
+				Expect(fields).To(Equal([]core.UserField{core.UserFieldPassword}))
+				Expect(version).To(Equal(current.UpdatedAt))
+				Expect(newEvent).To(BeNil())
+			})
+			Context("When storing the new hash fails", func() {
+				BeforeEach(func() {
+					userStore.UpdateUserFieldsStub = nil
+					userStore.UpdateUserFieldsReturns(core.User{}, errors.New("test-error"))
+				})
+				It("still authenticates the user", func() {
+					Expect(err).To(BeNil())
+					Expect(authenticated).To(Equal(current))
+				})
+			})
+		})
+	})
+	Context("Reset Password", func() {
+		var (
+			current core.User
+			err     error
+		)
+		BeforeEach(func() {
+			current = core.User{ID: uuid.New(), Email: "test@faceit.com", Password: "old-hash", UpdatedAt: time.Now()}
+			actor = core.Actor{ID: current.ID.String(), Type: core.ActorTypeUser}
+			userStore.GetUserByIDReturns(current, nil)
+		})
+		JustBeforeEach(func() {
+			_, err = manager.ResetPassword(ctx, actor, current.ID, "token-hash", "new-password")
+		})
+		Context("When actor is another regular user", func() {
+			BeforeEach(func() {
+				actor = core.Actor{ID: uuid.New().String(), Type: core.ActorTypeUser, Role: core.RoleUser}
+			})
+			It("fails with forbidden error", func() {
+				Expect(err).To(BeAssignableToTypeOf(&core.ForbiddenError{}))
+				Expect(userStore.ResetPasswordCallCount()).To(Equal(0))
+			})
+		})
+		Context("When user does not exist", func() {
+			BeforeEach(func() {
+				userStore.GetUserByIDReturns(core.User{}, &core.NotFoundError{Entity: "user"})
+			})
+			It("fails with not found error", func() {
+				Expect(err).To(BeAssignableToTypeOf(&core.NotFoundError{}))
+			})
+		})
+		Context("When token is unknown, used or expired", func() {
+			BeforeEach(func() {
+				userStore.ResetPasswordReturns(core.User{}, &core.NotFoundError{Entity: "password reset token"})
+			})
+			It("fails with not found error", func() {
+				Expect(err).To(BeAssignableToTypeOf(&core.NotFoundError{}))
+			})
+		})
+		Context("When actor is the user itself", func() {
+			It("stores only the hash of the new password", func() {
+				Expect(err).To(BeNil())
+				Expect(passwordHasher.HashArgsForCall(0)).To(Equal("new-password"))
+				_, storedUser, tokenHash, version, _ := userStore.ResetPasswordArgsForCall(0)
+				Expect(storedUser.Password).To(Equal("hashed-password"))
+				Expect(tokenHash).To(Equal("token-hash"))
+				Expect(version).To(Equal(current.UpdatedAt))
+			})
+			It("stores user password changed event without the password", func() {
+				_, storedUser, _, _, newEvent := userStore.ResetPasswordArgsForCall(0)
+				event := newEvent(storedUser)
+				Expect(event.Type).To(Equal(core.EventUserPasswordChanged))
+				Expect(event.Actor).To(Equal(actor))
+				Expect(event.Changes).To(HaveLen(1))
+				Expect(event.Changes[0].Redacted).To(BeTrue())
+			})
+		})
+	})
+	Context("Verify Email", func() {
+		var (
+			current core.User
+			err     error
+		)
+		BeforeEach(func() {
+			actor = core.Actor{Type: core.ActorTypeAnonymous}
+			current = core.User{ID: uuid.New(), Email: "test@faceit.com", UpdatedAt: time.Now()}
+			userStore.GetUserByIDReturns(current, nil)
+		})
+		JustBeforeEach(func() {
+			_, err = manager.VerifyEmail(ctx, actor, current.ID, "token")
+		})
+		Context("When token is unknown, used or expired", func() {
+			BeforeEach(func() {
+				userStore.VerifyEmailReturns(core.User{}, &core.NotFoundError{Entity: "email verification token"})
+			})
+			It("fails with validation error", func() {
+				Expect(err).To(BeAssignableToTypeOf(&core.ValidationError{}))
+			})
+		})
+		Context("When user has been modified in the meantime", func() {
+			BeforeEach(func() {
+				userStore.VerifyEmailReturns(core.User{}, &core.PreconditionFailedError{Message: "user has been modified in the meantime"})
+			})
+			It("fails with precondition failed error", func() {
+				Expect(err).To(BeAssignableToTypeOf(&core.PreconditionFailedError{}))
+			})
+		})
+		Context("When email has already been verified", func() {
+			BeforeEach(func() {
+				current.EmailVerifiedAt = time.Now()
+				userStore.GetUserByIDReturns(current, nil)
+			})
+			It("fails with conflict error", func() {
+				Expect(err).To(BeAssignableToTypeOf(&core.ConflictError{}))
+				Expect(userStore.VerifyEmailCallCount()).To(Equal(0))
+			})
+		})
+		Context("When user does not exist", func() {
+			BeforeEach(func() {
+				userStore.GetUserByIDReturns(core.User{}, &core.NotFoundError{Entity: "user"})
+			})
+			It("fails with not found error", func() {
+				Expect(err).To(BeAssignableToTypeOf(&core.NotFoundError{}))
+			})
+		})
+		Context("When token is valid", func() {
+			It("uses up the token by its hash and verifies the current email", func() {
+				Expect(err).To(BeNil())
+				digest := sha256.Sum256([]byte("token"))
+				_, verified, tokenHash, version, newEvent := userStore.VerifyEmailArgsForCall(0)
+				Expect(tokenHash).To(Equal(hex.EncodeToString(digest[:])))
+				Expect(verified.Email).To(Equal(current.Email))
+				Expect(version).To(Equal(current.UpdatedAt))
+				Expect(newEvent(verified).Type).To(Equal(core.EventUserUpdated))
 			})
 		})
 	})
 	Context("Delete User", func() {
-		var err error
+		var (
+			current core.User
+			err     error
+		)
+		BeforeEach(func() {
+			current = core.User{ID: uuid.New(), Email: "test@faceit.com", UpdatedAt: time.Now()}
+			userStore.GetUserByIDReturns(current, nil)
+		})
 		JustBeforeEach(func() {
-			err = manager.DeleteUser(ctx, uuid.New())
+			err = manager.RemoveUser(ctx, actor, current.ID, current.UpdatedAt)
+		})
+		Context("When actor is anonymous", func() {
+			BeforeEach(func() {
+				actor = core.Actor{Type: core.ActorTypeAnonymous}
+			})
+			It("fails with unauthorized error", func() {
+				Expect(err).To(BeAssignableToTypeOf(&core.UnauthorizedError{}))
+				Expect(userStore.DeleteUserCallCount()).To(Equal(0))
+			})
+		})
+		Context("When actor is another regular user", func() {
+			BeforeEach(func() {
+				actor = core.Actor{ID: uuid.New().String(), Type: core.ActorTypeUser, Role: core.RoleUser}
+			})
+			It("fails with forbidden error", func() {
+				Expect(err).To(BeAssignableToTypeOf(&core.ForbiddenError{}))
+				Expect(userStore.GetUserByIDCallCount()).To(Equal(0))
+				Expect(userStore.DeleteUserCallCount()).To(Equal(0))
+			})
+		})
+		Context("When actor is a service with write scope only", func() {
+			BeforeEach(func() {
+				actor = core.Actor{ID: uuid.New().String(), Type: core.ActorTypeService, Scopes: []core.Scope{core.ScopeUsersRead, core.ScopeUsersWrite}}
+			})
+			It("fails with forbidden error", func() {
+				Expect(err).To(BeAssignableToTypeOf(&core.ForbiddenError{}))
+				Expect(userStore.DeleteUserCallCount()).To(Equal(0))
+			})
+		})
+		Context("When actor is a service with delete scope", func() {
+			BeforeEach(func() {
+				actor = core.Actor{ID: uuid.New().String(), Type: core.ActorTypeService, Scopes: []core.Scope{core.ScopeUsersDelete}}
+			})
+			It("deletes the user", func() {
+				Expect(err).To(BeNil())
+				Expect(userStore.DeleteUserCallCount()).To(Equal(1))
+			})
+		})
+		Context("When actor is the user itself", func() {
+			BeforeEach(func() {
+				actor = core.Actor{ID: current.ID.String(), Type: core.ActorTypeUser, Role: core.RoleUser}
+			})
+			It("deletes the user on behalf of the actor", func() {
+				Expect(err).To(BeNil())
+				_, _, _, newEvent := userStore.DeleteUserArgsForCall(0)
+				Expect(newEvent(core.User{ID: current.ID}).Actor).To(Equal(actor))
+			})
+		})
+		Context("When user does not exist", func() {
+			BeforeEach(func() {
+				userStore.GetUserByIDReturns(core.User{}, &core.NotFoundError{Entity: "user"})
+			})
+			It("fails with not found error", func() {
+				Expect(err).To(BeAssignableToTypeOf(&core.NotFoundError{}))
+				Expect(userStore.DeleteUserCallCount()).To(Equal(0))
+			})
 		})
 		Context("When store returns an error", func() {
 			BeforeEach(func() {
@@ -168,6 +1050,13 @@ var _ = Describe("User Manager", func() {
 			It("deleted user successfully", func() {
 				Expect(err).To(BeNil())
 			})
+			It("stores user deleted event with the last state of the user", func() {
+				_, _, _, newEvent := userStore.DeleteUserArgsForCall(0)
+				event := newEvent(core.User{ID: current.ID})
+				Expect(event.Type).To(Equal(core.EventUserDeleted))
+				Expect(event.Before.Email).To(Equal("test@faceit.com"))
+				Expect(event.After).To(BeNil())
+			})
 		})
 	})
 })
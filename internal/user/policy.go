@@ -0,0 +1,58 @@
+package user
+
+import (
+	"fmt"
+
+	"com.user.com/user/internal/core"
+	"github.com/google/uuid"
+)
+
+// Authorization policy of user operations. Anyone may sign up, authenticated actors may read users and manage
+// themselves. Admins and the service itself manage everyone. Services calling the API by keys are limited to
+// the scopes of their keys.
+
+var (
+	errUnauthenticated = &core.UnauthorizedError{Message: "authentication is required"}
+	errNotPermitted    = &core.ForbiddenError{Message: "only the user itself or an admin may manage the user"}
+)
+
+// authorizeCreate - actor may create users. Anyone may sign up, services need the write scope.
+func authorizeCreate(actor core.Actor) error {
+	if actor.Type == core.ActorTypeService {
+		return authorizeScope(actor, core.ScopeUsersWrite)
+	}
+	return nil
+}
+
+// authorizeRead - actor may read users
+func authorizeRead(actor core.Actor) error {
+	switch {
+	case !actor.Authenticated():
+		return errUnauthenticated
+	case actor.Type == core.ActorTypeService:
+		return authorizeScope(actor, core.ScopeUsersRead)
+	default:
+		return nil
+	}
+}
+
+// authorizeManage - actor may modify or delete the user with given ID. Services need the scope of the operation.
+func authorizeManage(actor core.Actor, id uuid.UUID, scope core.Scope) error {
+	switch {
+	case !actor.Authenticated():
+		return errUnauthenticated
+	case actor.Type == core.ActorTypeService:
+		return authorizeScope(actor, scope)
+	case actor.Type == core.ActorTypeSystem, actor.Role == core.RoleAdmin, actor.IsUser(id):
+		return nil
+	default:
+		return errNotPermitted
+	}
+}
+
+func authorizeScope(actor core.Actor, scope core.Scope) error {
+	if !actor.HasScope(scope) {
+		return &core.ForbiddenError{Message: fmt.Sprintf("scope %s is required", scope)}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+//go:generate ~/go/bin/counterfeiter  . OutboxStore
+
+// OutboxStore - events stored along with user changes, waiting to be published
+type OutboxStore interface {
+	// ClaimEvents - returns up to limit events ready to be published, at most one per user, so events of
+	// a user are published in order. Claimed events are hidden from other relays for the lease duration.
+	ClaimEvents(ctx context.Context, limit int, lease time.Duration) ([]core.PendingEvent, error)
+	MarkEventPublished(ctx context.Context, id uuid.UUID) error
+	MarkEventFailed(ctx context.Context, id uuid.UUID, retryIn time.Duration, cause string) error
+}
+
+type RelayConfig struct {
+	// BatchSize - max number of events claimed at once
+	BatchSize int
+	// PollInterval - how often the outbox is checked once it has been drained
+	PollInterval time.Duration
+	// Lease - how long claimed events are hidden from other relays. It has to be longer than publishing a batch.
+	Lease time.Duration
+	// MinBackoff, MaxBackoff - bounds of exponential backoff between publish attempts of an event
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+var DefaultRelayConfig = RelayConfig{
+	BatchSize:    100,
+	PollInterval: time.Second,
+	Lease:        time.Minute,
+	MinBackoff:   time.Second,
+	MaxBackoff:   5 * time.Minute,
+}
+
+// Relay - publishes events from the outbox through the Notifier. An event is removed from the outbox only
+// once it has been published, hence delivery is at least once and consumers should deduplicate by event ID.
+type Relay struct {
+	outbox   OutboxStore
+	notifier Notifier
+	config   RelayConfig
+}
+
+func NewRelay(outbox OutboxStore, notifier Notifier, config RelayConfig) *Relay {
+	return &Relay{
+		outbox:   outbox,
+		notifier: notifier,
+		config:   config,
+	}
+}
+
+// Run - publishes events until ctx is done
+func (r *Relay) Run(ctx context.Context) {
+	for {
+		claimed, err := r.PublishPending(ctx)
+		if err != nil {
+			logrus.WithContext(ctx).WithError(err).Error("relay: failed to publish pending events")
+		}
+		// Full batch means there are likely more events waiting
+		if err == nil && claimed == r.config.BatchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.config.PollInterval):
+		}
+	}
+}
+
+// PublishPending - claims a batch of events and publishes them. Failed events are retried later with backoff.
+// Returns number of claimed events.
+func (r *Relay) PublishPending(ctx context.Context) (int, error) {
+	events, err := r.outbox.ClaimEvents(ctx, r.config.BatchSize, r.config.Lease)
+	if err != nil {
+		return 0, err
+	}
+	for _, pending := range events {
+		event := pending.Event
+		err := r.notifier.Notify(ctx, event)
+		if err != nil {
+			retryIn := r.backoff(pending.Attempts)
+			logrus.WithContext(ctx).
+				WithError(err).
+				WithField("event_id", event.ID).
+				WithField("event_type", event.Type).
+				WithField("attempts", pending.Attempts).
+				WithField("retry_in", retryIn).
+				Warn("relay: failed to publish event")
+			err = r.outbox.MarkEventFailed(ctx, event.ID, retryIn, err.Error())
+			if err != nil {
+				return len(events), err
+			}
+			continue
+		}
+		err = r.outbox.MarkEventPublished(ctx, event.ID)
+		if err != nil {
+			return len(events), err
+		}
+	}
+	return len(events), nil
+}
+
+// backoff - doubles the delay with every attempt, up to MaxBackoff
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := r.config.MinBackoff
+	for i := 1; i < attempts && delay < r.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > r.config.MaxBackoff {
+		return r.config.MaxBackoff
+	}
+	return delay
+}
@@ -0,0 +1,163 @@
+package user_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/notifier"
+	"com.user.com/user/internal/user"
+	"com.user.com/user/internal/user/userfakes"
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gocloud.dev/pubsub"
+	"gocloud.dev/pubsub/mempubsub"
+)
+
+var _ = Describe("Outbox Relay", func() {
+	var (
+		outbox       *userfakes.FakeOutboxStore
+		topic        *pubsub.Topic
+		subscription *pubsub.Subscription
+		config       user.RelayConfig
+		relay        *user.Relay
+		ctx          context.Context
+		events       []core.PendingEvent
+	)
+
+	receive := func() *pubsub.Message {
+		receiveCtx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		msg, err := subscription.Receive(receiveCtx)
+		Expect(err).To(BeNil())
+		msg.Ack()
+		return msg
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		topic = mempubsub.NewTopic()
+		subscription = mempubsub.NewSubscription(topic, time.Minute)
+		outbox = &userfakes.FakeOutboxStore{}
+		config = user.RelayConfig{
+			BatchSize:    10,
+			PollInterval: time.Millisecond,
+			Lease:        time.Minute,
+			MinBackoff:   time.Second,
+			MaxBackoff:   10 * time.Second,
+		}
+		relay = user.NewRelay(outbox, notifier.NewPubSubNotifier(topic, notifier.FormatJSON), config)
+
+		first := core.User{ID: uuid.New(), Email: "first@faceit.com"}
+		second := core.User{ID: uuid.New(), Email: "second@faceit.com"}
+		events = []core.PendingEvent{
+			{Event: core.NewUserCreatedEvent(core.SystemActor, first), Attempts: 1},
+			{Event: core.NewUserDeletedEvent(core.SystemActor, second), Attempts: 1},
+		}
+		outbox.ClaimEventsReturns(events, nil)
+	})
+
+	AfterEach(func() {
+		_ = subscription.Shutdown(ctx)
+		_ = topic.Shutdown(ctx)
+	})
+
+	Context("When events are published", func() {
+		var (
+			claimed int
+			err     error
+		)
+		JustBeforeEach(func() {
+			claimed, err = relay.PublishPending(ctx)
+		})
+		It("claims a batch of events", func() {
+			Expect(err).To(BeNil())
+			Expect(claimed).To(Equal(2))
+			_, limit, lease := outbox.ClaimEventsArgsForCall(0)
+			Expect(limit).To(Equal(config.BatchSize))
+			Expect(lease).To(Equal(config.Lease))
+		})
+		It("sends them to the topic", func() {
+			received := []string{
+				receive().Metadata[notifier.AttributeEventID],
+				receive().Metadata[notifier.AttributeEventID],
+			}
+			Expect(received).To(ConsistOf(events[0].Event.ID.String(), events[1].Event.ID.String()))
+		})
+		It("removes them from the outbox", func() {
+			Expect(outbox.MarkEventPublishedCallCount()).To(Equal(2))
+			_, id := outbox.MarkEventPublishedArgsForCall(0)
+			Expect(id).To(Equal(events[0].Event.ID))
+			Expect(outbox.MarkEventFailedCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("When claiming events fails", func() {
+		BeforeEach(func() {
+			outbox.ClaimEventsReturns(nil, errors.New("db-error"))
+		})
+		It("returns the error", func() {
+			_, err := relay.PublishPending(ctx)
+			Expect(err).To(MatchError("db-error"))
+		})
+	})
+
+	Context("When publishing fails", func() {
+		var failingNotifier *userfakes.FakeNotifier
+		BeforeEach(func() {
+			failingNotifier = &userfakes.FakeNotifier{}
+			failingNotifier.NotifyReturns(errors.New("broker-error"))
+			relay = user.NewRelay(outbox, failingNotifier, config)
+			events[1].Attempts = 3
+		})
+		JustBeforeEach(func() {
+			_, err := relay.PublishPending(ctx)
+			Expect(err).To(BeNil())
+		})
+		It("keeps events in the outbox and retries them with exponential backoff", func() {
+			Expect(outbox.MarkEventPublishedCallCount()).To(Equal(0))
+			Expect(outbox.MarkEventFailedCallCount()).To(Equal(2))
+			_, id, retryIn, cause := outbox.MarkEventFailedArgsForCall(0)
+			Expect(id).To(Equal(events[0].Event.ID))
+			Expect(retryIn).To(Equal(time.Second))
+			Expect(cause).To(Equal("broker-error"))
+			_, _, retryIn, _ = outbox.MarkEventFailedArgsForCall(1)
+			Expect(retryIn).To(Equal(4 * time.Second))
+		})
+		Context("Many times", func() {
+			BeforeEach(func() {
+				events[1].Attempts = 50
+			})
+			It("caps the backoff", func() {
+				_, _, retryIn, _ := outbox.MarkEventFailedArgsForCall(1)
+				Expect(retryIn).To(Equal(config.MaxBackoff))
+			})
+		})
+	})
+
+	Context("When running in background", func() {
+		It("keeps publishing until stopped", func() {
+			outbox.ClaimEventsReturnsOnCall(0, events[:1], nil)
+			outbox.ClaimEventsReturnsOnCall(1, nil, errors.New("db-error"))
+			outbox.ClaimEventsReturnsOnCall(2, events[1:], nil)
+			outbox.ClaimEventsReturns(nil, nil)
+
+			runCtx, cancel := context.WithCancel(ctx)
+			done := make(chan struct{})
+			go func() {
+				relay.Run(runCtx)
+				close(done)
+			}()
+
+			received := []string{
+				receive().Metadata[notifier.AttributeEventID],
+				receive().Metadata[notifier.AttributeEventID],
+			}
+			Expect(received).To(ConsistOf(events[0].Event.ID.String(), events[1].Event.ID.String()))
+			cancel()
+			Eventually(done).Should(BeClosed())
+		})
+	})
+})
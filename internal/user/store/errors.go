@@ -0,0 +1,13 @@
+package store
+
+import "com.user.com/user/internal/dberror"
+
+// translateError - converts db specific errors into core error types, e.g. violation of users_email_lower_key into
+// conflict on email
+var translateError = dberror.Translator{
+	Entity: "user",
+	Fields: []dberror.Field{
+		{Constraint: "email", Name: "email"},
+		{Constraint: "nickname", Name: "nickname"},
+	},
+}.Translate
@@ -0,0 +1,89 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"github.com/google/uuid"
+)
+
+const (
+	insertEventStmt = `INSERT INTO user_outbox (id, user_id, event_type, payload, created_at, available_at) VALUES ($1, $2, $3, $4, now(), now())`
+
+	// claimEventsStmt - leases the oldest pending event of every user whose turn it is. Later events of the same user
+	// wait until the earlier ones are published, which keeps per user ordering even across several relays.
+	claimEventsStmt = `UPDATE user_outbox SET attempts=attempts+1, available_at=now() + $1::FLOAT8 * INTERVAL '1 second'
+		WHERE id IN (
+			SELECT o.id FROM user_outbox o
+			WHERE o.available_at <= now()
+			AND NOT EXISTS (
+				SELECT 1 FROM user_outbox e
+				WHERE e.user_id=o.user_id AND (e.created_at, e.seq) < (o.created_at, o.seq)
+			)
+			ORDER BY o.created_at, o.seq
+			LIMIT $2
+		)
+		RETURNING payload, attempts`
+	deleteEventStmt = `DELETE FROM user_outbox WHERE id=$1`
+	retryEventStmt  = `UPDATE user_outbox SET available_at=now() + $1::FLOAT8 * INTERVAL '1 second', last_error=$2 WHERE id=$3`
+)
+
+// saveEvent - stores the event in the outbox as part of the transaction which has written the user
+func (s *Store) saveEvent(ctx context.Context, tx *sql.Tx, newEvent core.EventBuilder, stored core.User) error {
+	if newEvent == nil {
+		return nil
+	}
+	event := newEvent(stored)
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, insertEventStmt, event.ID, event.UserID, string(event.Type), payload)
+	return err
+}
+
+// ClaimEvents - returns up to limit events ready to be published. Claimed events are hidden from other relays
+// for the lease duration, afterwards they are considered abandoned and get claimed again.
+func (s *Store) ClaimEvents(ctx context.Context, limit int, lease time.Duration) ([]core.PendingEvent, error) {
+	rows, err := s.db.QueryContext(ctx, claimEventsStmt, lease.Seconds(), limit)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var events []core.PendingEvent
+	for rows.Next() {
+		var (
+			payload []byte
+			pending core.PendingEvent
+		)
+		if err := rows.Scan(&payload, &pending.Attempts); err != nil {
+			return nil, translateError(err)
+		}
+		if err := json.Unmarshal(payload, &pending.Event); err != nil {
+			return nil, err
+		}
+		events = append(events, pending)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, translateError(err)
+	}
+	return events, nil
+}
+
+// MarkEventPublished - removes published event from the outbox
+func (s *Store) MarkEventPublished(ctx context.Context, id uuid.UUID) error {
+	_, err := s.db.ExecContext(ctx, deleteEventStmt, id)
+	return translateError(err)
+}
+
+// MarkEventFailed - makes the event available again after given delay and records the failure
+func (s *Store) MarkEventFailed(ctx context.Context, id uuid.UUID, retryIn time.Duration, cause string) error {
+	_, err := s.db.ExecContext(ctx, retryEventStmt, retryIn.Seconds(), cause, id)
+	return translateError(err)
+}
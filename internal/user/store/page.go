@@ -0,0 +1,195 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/cursor"
+)
+
+// sortKey - expression users are ordered by, along with the way its value is taken from a listed user
+type sortKey struct {
+	expr       string
+	descending bool
+	// numeric - value is a number, every other one is a string
+	numeric bool
+	value   func(u *core.User, rank float64) interface{}
+}
+
+// sortColumns - whitelist of columns users can be sorted by
+var sortColumns = map[core.UserSortField]func(u *core.User) interface{}{
+	core.UserSortFirstName: func(u *core.User) interface{} { return u.FirstName },
+	core.UserSortLastName:  func(u *core.User) interface{} { return u.LastName },
+	core.UserSortNickname:  func(u *core.User) interface{} { return u.Nickname },
+	core.UserSortEmail:     func(u *core.User) interface{} { return u.Email },
+	core.UserSortCountry:   func(u *core.User) interface{} { return u.Country },
+	core.UserSortCreatedAt: func(u *core.User) interface{} { return formatTime(u.CreatedAt) },
+	core.UserSortUpdatedAt: func(u *core.User) interface{} { return formatTime(u.UpdatedAt) },
+}
+
+// sortKeys - ordering requested by the filter. Newly created users go first by default, the most relevant ones
+// when users are searched. ID always goes last, it makes the order total, so keyset pagination skips no user.
+// Returns rank expression as well if users are ordered by relevance.
+func (q *filterQuery) sortKeys(filter core.UserFilter) ([]sortKey, string, error) {
+	var (
+		keys []sortKey
+		rank string
+	)
+	sort := filter.Sort
+	if len(sort) == 0 {
+		if filter.Query != "" {
+			rank = q.rank(filter.Query)
+			keys = append(keys, sortKey{
+				expr:       rank,
+				descending: true,
+				numeric:    true,
+				value:      func(_ *core.User, rank float64) interface{} { return rank },
+			})
+		}
+		sort = []core.UserSort{{Field: core.UserSortCreatedAt, Descending: true}}
+	}
+	for _, by := range sort {
+		value, ok := sortColumns[by.Field]
+		if !ok {
+			return nil, "", &core.ValidationError{Field: "sort", Message: fmt.Sprintf("users cannot be sorted by %q", by.Field)}
+		}
+		keys = append(keys, sortKey{
+			expr:       string(by.Field),
+			descending: by.Descending,
+			value:      func(u *core.User, _ float64) interface{} { return value(u) },
+		})
+	}
+	keys = append(keys, sortKey{
+		expr:       "id",
+		descending: keys[len(keys)-1].descending,
+		value:      func(u *core.User, _ float64) interface{} { return u.ID.String() },
+	})
+	return keys, rank, nil
+}
+
+// after - limits the query to users following given sort key values, or preceding them when moving backwards
+func (q *filterQuery) after(keys []sortKey, values []interface{}, backwards bool) {
+	exprs := make([]string, 0, len(keys))
+	placeholders := make([]string, 0, len(keys))
+	sameDirection := true
+	for i, key := range keys {
+		exprs = append(exprs, key.expr)
+		placeholders = append(placeholders, q.arg(values[i]))
+		sameDirection = sameDirection && key.descending == keys[0].descending
+	}
+	operator := func(key sortKey) string {
+		if key.descending != backwards {
+			return "<"
+		}
+		return ">"
+	}
+	// Row comparison is able to use indexes, but it works only when all of the keys go in the same direction
+	if sameDirection {
+		q.conditions = append(q.conditions, fmt.Sprintf("(%s) %s (%s)",
+			strings.Join(exprs, ", "), operator(keys[0]), strings.Join(placeholders, ", ")))
+		return
+	}
+	alternatives := make([]string, 0, len(keys))
+	for i, key := range keys {
+		var parts []string
+		for j := 0; j < i; j++ {
+			parts = append(parts, fmt.Sprintf("%s = %s", exprs[j], placeholders[j]))
+		}
+		parts = append(parts, fmt.Sprintf("%s %s %s", key.expr, operator(key), placeholders[i]))
+		alternatives = append(alternatives, "("+strings.Join(parts, " AND ")+")")
+	}
+	q.conditions = append(q.conditions, "("+strings.Join(alternatives, " OR ")+")")
+}
+
+func orderBy(keys []sortKey, backwards bool) string {
+	terms := make([]string, 0, len(keys))
+	for _, key := range keys {
+		direction := "ASC"
+		if key.descending != backwards {
+			direction = "DESC"
+		}
+		terms = append(terms, key.expr+" "+direction)
+	}
+	return " ORDER BY " + strings.Join(terms, ", ")
+}
+
+// resultPage - listed users along with their ranks, which are zero unless users are ordered by relevance
+type resultPage struct {
+	keys    []sortKey
+	users   []*core.User
+	ranks   []float64
+	cursors *cursor.Codec
+	binding string
+}
+
+func (p *resultPage) reverse() {
+	for i, j := 0, len(p.users)-1; i < j; i, j = i+1, j-1 {
+		p.users[i], p.users[j] = p.users[j], p.users[i]
+		p.ranks[i], p.ranks[j] = p.ranks[j], p.ranks[i]
+	}
+}
+
+// cursor - opaque position of the i-th user of the page, bound to the filter of the page
+func (p *resultPage) cursor(i int) (string, error) {
+	pos := position{
+		Values: make([]interface{}, 0, len(p.keys)),
+	}
+	for _, key := range p.keys {
+		pos.Values = append(pos.Values, key.value(p.users[i], p.ranks[i]))
+	}
+	return p.cursors.Encode(p.binding, pos)
+}
+
+// position - sort key values of the user at a page boundary
+type position struct {
+	Values []interface{} `json:"v"`
+}
+
+// decodePosition - fails unless the cursor has been issued for the same filter, including its ordering
+func decodePosition(cursors *cursor.Codec, encodedCursor, binding string, keys []sortKey) (position, error) {
+	var pos position
+	err := cursors.Decode(encodedCursor, binding, &pos)
+	switch {
+	case errors.Is(err, cursor.ErrExpired):
+		return position{}, &core.ValidationError{Field: "cursor", Message: "cursor has expired, start from the first page"}
+	case errors.Is(err, cursor.ErrBindingMismatch):
+		return position{}, &core.ValidationError{Field: "cursor", Message: "cursor has been issued for a different filter"}
+	case err != nil:
+		return position{}, errInvalidCursor
+	}
+	if len(pos.Values) != len(keys) {
+		return position{}, errInvalidCursor
+	}
+	for i, key := range keys {
+		var ok bool
+		if key.numeric {
+			_, ok = pos.Values[i].(float64)
+		} else {
+			_, ok = pos.Values[i].(string)
+		}
+		if !ok {
+			return position{}, errInvalidCursor
+		}
+	}
+	return pos, nil
+}
+
+// filterBinding - identifies the filter, so its cursors cannot be used with another one. Pagination and counting
+// are left out, page size and count mode may change between pages.
+func filterBinding(filter core.UserFilter) string {
+	filter.NextPage = ""
+	filter.PreviousPage = ""
+	filter.Limit = 0
+	filter.Count = ""
+	// Filter consists of strings, times and UUIDs only, hence it is always encoded
+	byt, _ := json.Marshal(filter)
+	return string(byt)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,183 @@
+package store
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/cursor"
+	"com.user.com/user/internal/view"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Keyset pagination", func() {
+	var (
+		createdAt = time.Date(2022, 1, 2, 3, 4, 5, 6, time.UTC)
+		user      = &core.User{ID: uuid.MustParse("00000000-0000-0000-0000-000000000001"), LastName: "Potter", CreatedAt: createdAt}
+	)
+
+	// page - conditions and ordering of the page following the user, or preceding it when moving backwards
+	page := func(filter core.UserFilter, backwards bool) (where string, order string, args []interface{}) {
+		query := newFilterQuery(filter)
+		keys, _, err := query.sortKeys(filter)
+		Expect(err).To(BeNil())
+		values := make([]interface{}, 0, len(keys))
+		for _, key := range keys {
+			values = append(values, key.value(user, 0.5))
+		}
+		query.after(keys, values, backwards)
+		return query.where(), orderBy(keys, backwards), query.args
+	}
+
+	table.DescribeTable("moving between pages",
+		func(filter core.UserFilter, backwards bool, where, order string, args ...interface{}) {
+			actualWhere, actualOrder, actualArgs := page(filter, backwards)
+			Expect(actualWhere).To(Equal(where))
+			Expect(actualOrder).To(Equal(order))
+			Expect(actualArgs).To(Equal(args))
+		},
+		table.Entry("newly created users go first by default", core.UserFilter{}, false,
+			" WHERE (created_at, id) < ($1, $2)",
+			" ORDER BY created_at DESC, id DESC",
+			"2022-01-02T03:04:05.000000006Z", user.ID.String()),
+		table.Entry("backwards the order and the comparison are reversed", core.UserFilter{}, true,
+			" WHERE (created_at, id) > ($1, $2)",
+			" ORDER BY created_at ASC, id ASC",
+			"2022-01-02T03:04:05.000000006Z", user.ID.String()),
+		table.Entry("keys in the same direction are compared as a row",
+			core.UserFilter{Sort: []core.UserSort{{Field: core.UserSortLastName}}}, false,
+			" WHERE (last_name, id) > ($1, $2)",
+			" ORDER BY last_name ASC, id ASC",
+			"Potter", user.ID.String()),
+		table.Entry("keys in mixed directions are expanded into alternatives",
+			core.UserFilter{Sort: []core.UserSort{{Field: core.UserSortLastName}, {Field: core.UserSortCreatedAt, Descending: true}}}, false,
+			" WHERE ((last_name > $1) OR (last_name = $1 AND created_at < $2) OR (last_name = $1 AND created_at = $2 AND id < $3))",
+			" ORDER BY last_name ASC, created_at DESC, id DESC",
+			"Potter", "2022-01-02T03:04:05.000000006Z", user.ID.String()),
+		table.Entry("keys in mixed directions backwards",
+			core.UserFilter{Sort: []core.UserSort{{Field: core.UserSortLastName}, {Field: core.UserSortCreatedAt, Descending: true}}}, true,
+			" WHERE ((last_name < $1) OR (last_name = $1 AND created_at > $2) OR (last_name = $1 AND created_at = $2 AND id > $3))",
+			" ORDER BY last_name DESC, created_at ASC, id ASC",
+			"Potter", "2022-01-02T03:04:05.000000006Z", user.ID.String()),
+		table.Entry("position follows arguments of the filter",
+			core.UserFilter{Country: core.StringCondition{In: []string{"GB"}}, Sort: []core.UserSort{{Field: core.UserSortLastName, Descending: true}}}, false,
+			" WHERE country = ANY($1::TEXT[]) AND (last_name, id) < ($2, $3)",
+			" ORDER BY last_name DESC, id DESC",
+			pq.Array([]string{"GB"}), "Potter", user.ID.String()),
+	)
+
+	It("orders searched users by relevance first", func() {
+		query := newFilterQuery(core.UserFilter{Query: "Harry"})
+		keys, rank, err := query.sortKeys(core.UserFilter{Query: "Harry"})
+		Expect(err).To(BeNil())
+		Expect(rank).ToNot(BeEmpty())
+		Expect(keys).To(HaveLen(3))
+		Expect(keys[0].expr).To(Equal(rank))
+		Expect(keys[0].numeric).To(BeTrue())
+		Expect(keys[0].value(user, 0.5)).To(Equal(0.5))
+		Expect(orderBy(keys, false)).To(Equal(" ORDER BY " + rank + " DESC, created_at DESC, id DESC"))
+	})
+
+	It("rejects unknown sort fields", func() {
+		_, _, err := newFilterQuery(core.UserFilter{}).sortKeys(core.UserFilter{Sort: []core.UserSort{{Field: "password"}}})
+		Expect(err).To(BeAssignableToTypeOf(&core.ValidationError{}))
+	})
+
+	Context("Cursors", func() {
+		var (
+			codec  *cursor.Codec
+			filter core.UserFilter
+			keys   []sortKey
+		)
+		BeforeEach(func() {
+			codec = cursor.NewCodec([]byte("test-key"), time.Hour)
+			filter = core.UserFilter{Country: core.StringCondition{In: []string{"GB"}}}
+			var err error
+			keys, _, err = newFilterQuery(filter).sortKeys(filter)
+			Expect(err).To(BeNil())
+		})
+		pageCursor := func(codec *cursor.Codec, filter core.UserFilter) string {
+			p := resultPage{keys: keys, users: []*core.User{user}, ranks: []float64{0}, cursors: codec, binding: filterBinding(filter)}
+			encoded, err := p.cursor(0)
+			Expect(err).To(BeNil())
+			return encoded
+		}
+
+		It("decodes the position of the user", func() {
+			pos, err := decodePosition(codec, pageCursor(codec, filter), filterBinding(filter), keys)
+			Expect(err).To(BeNil())
+			Expect(pos.Values).To(Equal([]interface{}{"2022-01-02T03:04:05.000000006Z", user.ID.String()}))
+		})
+		It("accepts the cursor when only page size or count mode change", func() {
+			changed := filter
+			changed.Limit = 10
+			changed.Count = core.CountNone
+			changed.NextPage = "next"
+			_, err := decodePosition(codec, pageCursor(codec, filter), filterBinding(changed), keys)
+			Expect(err).To(BeNil())
+		})
+
+		table.DescribeTable("rejects with validation error",
+			func(encoded func() string, binding func() string, message string) {
+				_, err := decodePosition(codec, encoded(), binding(), keys)
+				Expect(err).To(BeAssignableToTypeOf(&core.ValidationError{}))
+				Expect(err.(*core.ValidationError).Field).To(Equal("cursor"))
+				Expect(err.(*core.ValidationError).Message).To(Equal(message))
+				// Clients are told to start over rather than being served an internal error
+				Expect(view.ProblemFromError(err).Status).To(Equal(http.StatusBadRequest))
+			},
+			table.Entry("cursor of another filter",
+				func() string { return pageCursor(codec, filter) },
+				func() string {
+					changed := filter
+					changed.Country.In = []string{"BG"}
+					return filterBinding(changed)
+				},
+				"cursor has been issued for a different filter"),
+			table.Entry("cursor of another ordering",
+				func() string { return pageCursor(codec, filter) },
+				func() string {
+					changed := filter
+					changed.Sort = []core.UserSort{{Field: core.UserSortCreatedAt}}
+					return filterBinding(changed)
+				},
+				"cursor has been issued for a different filter"),
+			table.Entry("tampered cursor",
+				func() string {
+					encoded := pageCursor(codec, filter)
+					return strings.Replace(encoded, encoded[:1], string(encoded[0]^1), 1)
+				},
+				func() string { return filterBinding(filter) },
+				"cursor is invalid"),
+			table.Entry("cursor signed by another key",
+				func() string { return pageCursor(cursor.NewCodec([]byte("other-key"), time.Hour), filter) },
+				func() string { return filterBinding(filter) },
+				"cursor is invalid"),
+			table.Entry("expired cursor",
+				func() string { return pageCursor(cursor.NewCodec([]byte("test-key"), -time.Second), filter) },
+				func() string { return filterBinding(filter) },
+				"cursor has expired, start from the first page"),
+			table.Entry("position of other keys",
+				func() string {
+					encoded, err := codec.Encode(filterBinding(filter), position{Values: []interface{}{"Potter"}})
+					Expect(err).To(BeNil())
+					return encoded
+				},
+				func() string { return filterBinding(filter) },
+				"cursor is invalid"),
+			table.Entry("position of other types",
+				func() string {
+					encoded, err := codec.Encode(filterBinding(filter), position{Values: []interface{}{1.5, user.ID.String()}})
+					Expect(err).To(BeNil())
+					return encoded
+				},
+				func() string { return filterBinding(filter) },
+				"cursor is invalid"),
+		)
+	})
+})
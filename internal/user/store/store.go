@@ -3,59 +3,94 @@ package store
 import (
 	"context"
 	"database/sql"
-	"encoding/base64"
 	"errors"
 	"fmt"
-	"io/ioutil"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
 	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/cursor"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const (
-	storeUserStmt  = `INSERT INTO users (id, first_name, last_name, nickname, password, email, country, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, now(), now())`
-	updateUserStms = `UPDATE users SET first_name=$1, last_name=$2, nickanme=$3, password=$4, email=$5, country=$6 last_updated=now() WHERE id=&7 and updated_at<&8`
-	deleteUserStmt = `DELETE FROM users WHERE id=$1`
+	storeUserStmt     = `INSERT INTO users (id, first_name, last_name, nickname, password, email, country, role, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, now(), now()) RETURNING created_at, updated_at`
+	updateUserStmt    = `UPDATE users SET first_name=$1, last_name=$2, nickname=$3, password=$4, email=$5, country=$6, email_verified_at=CASE WHEN email=$5 THEN email_verified_at END, updated_at=now() WHERE id=$7`
+	verifyEmailStmt   = `UPDATE users SET email_verified_at=now(), updated_at=now() WHERE id=$1 AND email=$2`
+	resetPasswordStmt = `UPDATE users SET password=$1, updated_at=now() WHERE id=$2`
+	deleteUserStmt    = `DELETE FROM users WHERE id=$1`
+	userVersionStmt   = `SELECT updated_at FROM users WHERE id=$1`
+	userColumns       = `id, first_name, last_name, nickname, password, email, country, role, email_verified_at, created_at, updated_at`
+	getUserStmt       = `SELECT ` + userColumns + ` FROM users`
+
+	storeVerificationTokenStmt    = `INSERT INTO email_verification_tokens (id, user_id, email, token_hash, expires_at, created_at) VALUES ($1, $2, $3, $4, $5, now())`
+	useUserVerificationTokensStmt = `UPDATE email_verification_tokens SET used_at=now() WHERE user_id=$1 AND used_at IS NULL`
+	useVerificationTokenStmt      = `UPDATE email_verification_tokens SET used_at=now() WHERE token_hash=$1 AND user_id=$2 AND email=$3 AND used_at IS NULL AND expires_at > now()`
+
+	usePasswordResetTokenStmt      = `UPDATE password_reset_tokens SET used_at=now() WHERE token_hash=$1 AND user_id=$2 AND used_at IS NULL AND expires_at > now()`
+	useUserPasswordResetTokensStmt = `UPDATE password_reset_tokens SET used_at=now() WHERE user_id=$1 AND used_at IS NULL`
+	revokeUserRefreshTokensStmt    = `UPDATE refresh_tokens SET revoked_at=now() WHERE user_id=$1 AND revoked_at IS NULL`
+
+	// estimatedUsersStmt - row count of the latest CockroachDB table statistics, which are refreshed automatically
+	// as the table changes
+	estimatedUsersStmt = `SELECT row_count FROM [SHOW STATISTICS FOR TABLE users] ORDER BY created DESC LIMIT 1`
 
 	DEFAULT_LIMIT = 100
 )
 
+var errInvalidCursor = &core.ValidationError{Field: "cursor", Message: "cursor is invalid"}
+
 // Store - represents abstraction over db
 type Store struct {
-	db sql.DB
+	db *sql.DB
+	// cursors - signs cursors of users listing
+	cursors *cursor.Codec
 }
 
-func NewStore(db sql.DB) *Store {
+func NewStore(db *sql.DB, cursors *cursor.Codec) *Store {
 	return &Store{
-		db: db,
+		db:      db,
+		cursors: cursors,
 	}
 }
 
-// SaveUser - stores user entity in db
-func (s *Store) SaveUser(ctx context.Context, user core.User) error {
-	_, err := s.db.ExecContext(ctx,
-		storeUserStmt,
-		user.ID,
-		user.FirstName,
-		user.LastName,
-		user.Nickname,
-		user.Password,
-		user.Email,
-		user.Country,
-	)
-	return err
+// SaveUser - stores user entity in db and returns it along with db generated timestamps.
+// Event built by newEvent is stored in the outbox within the same transaction, nil newEvent stores none.
+func (s *Store) SaveUser(ctx context.Context, user core.User, newEvent core.EventBuilder) (core.User, error) {
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx,
+			storeUserStmt,
+			user.ID,
+			user.FirstName,
+			user.LastName,
+			user.Nickname,
+			user.Password,
+			user.Email,
+			user.Country,
+			user.Role,
+		).Scan(
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		return s.saveEvent(ctx, tx, newEvent, user)
+	})
+	if err != nil {
+		return core.User{}, translateError(err)
+	}
+	return user, nil
 }
 
-// UpdateUser - updates user entity in db
-// updated_at<&8 condition takes care of concurrent modifications.
-func (s *Store) UpdateUser(ctx context.Context, user core.User) error {
-	_, err := s.db.ExecContext(ctx,
-		"UPDATE users SET first_name=$1, last_name=$2, nickanme=$3, password=$4, email=$5, country=$6 last_updated=now() WHERE id=&7 and updated_at<&8",
+// UpdateUser - updates user entity in db and returns it along with its new version (updated_at).
+// updated_at=version condition takes care of concurrent modifications, zero version updates unconditionally.
+// Event built by newEvent is stored in the outbox within the same transaction, nil newEvent stores none.
+func (s *Store) UpdateUser(ctx context.Context, user core.User, version time.Time, newEvent core.EventBuilder) (core.User, error) {
+	query, args := withVersion(updateUserStmt, []interface{}{
 		user.FirstName,
 		user.LastName,
 		user.Nickname,
@@ -63,82 +98,306 @@ func (s *Store) UpdateUser(ctx context.Context, user core.User) error {
 		user.Email,
 		user.Country,
 		user.ID,
-		user.UpdatedAt,
-	)
-	return err
+	}, version)
+	return s.updateUser(ctx, user, query, args, newEvent)
+}
+
+// userFieldColumns - columns which can be updated one by one through UpdateUserFields
+var userFieldColumns = map[core.UserField]string{
+	core.UserFieldFirstName: "first_name",
+	core.UserFieldLastName:  "last_name",
+	core.UserFieldNickname:  "nickname",
+	core.UserFieldPassword:  "password",
+	core.UserFieldEmail:     "email",
+	core.UserFieldCountry:   "country",
 }
 
-// DeleteUser - deletes user from db
-func (s *Store) DeleteUser(ctx context.Context, id uuid.UUID) error {
-	_, err := s.db.ExecContext(ctx,
-		deleteUserStmt,
-		id,
+// UpdateUserFields - updates only given fields of the user and returns it along with its new version.
+// updated_at=version condition takes care of concurrent modifications, zero version updates unconditionally.
+// Event built by newEvent is stored in the outbox within the same transaction, nil newEvent stores none.
+func (s *Store) UpdateUserFields(ctx context.Context, user core.User, fields []core.UserField, version time.Time, newEvent core.EventBuilder) (core.User, error) {
+	var (
+		assignments []string
+		args        []interface{}
 	)
-	return err
+	for _, field := range fields {
+		column, ok := userFieldColumns[field]
+		if !ok {
+			return core.User{}, fmt.Errorf("store: field %q cannot be updated", field)
+		}
+		args = append(args, user.Field(field))
+		assignments = append(assignments, fmt.Sprintf("%s=$%d", column, len(args)))
+		if field == core.UserFieldEmail {
+			assignments = append(assignments, keepVerification(len(args)))
+		}
+	}
+	args = append(args, user.ID)
+	query := fmt.Sprintf("UPDATE users SET %s, updated_at=now() WHERE id=$%d", strings.Join(assignments, ", "), len(args))
+	query, args = withVersion(query, args, version)
+	return s.updateUser(ctx, user, query, args, newEvent)
+}
+
+// SaveEmailVerificationToken - stores the token and uses up all other tokens of its user in the same transaction,
+// so only the token mailed last works.
+func (s *Store) SaveEmailVerificationToken(ctx context.Context, token core.EmailVerificationToken) error {
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, useUserVerificationTokensStmt, token.UserID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx,
+			storeVerificationTokenStmt,
+			token.ID,
+			token.UserID,
+			token.Email,
+			token.TokenHash,
+			token.ExpiresAt,
+		)
+		return err
+	})
+	return translateError(err)
+}
+
+// VerifyEmail - uses up the token with such hash and marks the email of the user as verified in the same
+// transaction, so the token stays usable if the user cannot be updated. user.Email condition makes sure the verified
+// email is the one the token has been issued for. updated_at=version condition takes care of concurrent
+// modifications. Returns core.NotFoundError if there is no unused and unexpired token of the user with such hash.
+// Event built by newEvent is stored in the outbox within the same transaction, nil newEvent stores none.
+func (s *Store) VerifyEmail(ctx context.Context, user core.User, tokenHash string, version time.Time, newEvent core.EventBuilder) (core.User, error) {
+	query, args := withVersion(verifyEmailStmt, []interface{}{user.ID, user.Email}, version)
+	return s.updateUserAfter(ctx, user, query, args, newEvent, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, useVerificationTokenStmt, tokenHash, user.ID, user.Email)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			// Hash is not logged, it is as good as the token for using it
+			return &core.NotFoundError{Entity: "email verification token"}
+		}
+		return nil
+	})
+}
+
+// ResetPassword - uses up the password reset token with such hash along with all other tokens of the user, replaces
+// its password and revokes all of its refresh tokens in the same transaction. So the token works once, even for
+// concurrent resets, and it stays usable if the password cannot be replaced. updated_at=version condition takes care of
+// concurrent modifications. Returns core.NotFoundError if there is no unused and unexpired token of the user with such
+// hash. Event built by newEvent is stored in the outbox within the same transaction, nil newEvent stores none.
+func (s *Store) ResetPassword(ctx context.Context, user core.User, tokenHash string, version time.Time, newEvent core.EventBuilder) (core.User, error) {
+	query, args := withVersion(resetPasswordStmt, []interface{}{user.Password, user.ID}, version)
+	return s.updateUserAfter(ctx, user, query, args, newEvent, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, usePasswordResetTokenStmt, tokenHash, user.ID)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			// Hash is not logged, it is as good as the token for using it
+			return &core.NotFoundError{Entity: "password reset token"}
+		}
+		_, err = tx.ExecContext(ctx, useUserPasswordResetTokensStmt, user.ID)
+		if err != nil {
+			return err
+		}
+		// Whoever has known the old password may hold a session
+		_, err = tx.ExecContext(ctx, revokeUserRefreshTokensStmt, user.ID)
+		return err
+	})
+}
+
+// keepVerification - assignment which keeps email_verified_at only if the email, bound to given param, is the same.
+// The same assignment is part of updateUserStmt.
+func keepVerification(emailParam int) string {
+	return fmt.Sprintf("email_verified_at=CASE WHEN email=$%d THEN email_verified_at END", emailParam)
+}
+
+func (s *Store) updateUser(ctx context.Context, user core.User, query string, args []interface{}, newEvent core.EventBuilder) (core.User, error) {
+	return s.updateUserAfter(ctx, user, query, args, newEvent, nil)
+}
+
+// updateUserAfter - updates the user once before has succeeded within the same transaction, nil before is skipped
+func (s *Store) updateUserAfter(ctx context.Context, user core.User, query string, args []interface{}, newEvent core.EventBuilder, before func(tx *sql.Tx) error) (core.User, error) {
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		if before != nil {
+			if err := before(tx); err != nil {
+				return err
+			}
+		}
+		var verifiedAt sql.NullTime
+		err := tx.QueryRowContext(ctx, query+" RETURNING email_verified_at, created_at, updated_at", args...).Scan(
+			&verifiedAt,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		user.EmailVerifiedAt = verifiedAt.Time
+		return s.saveEvent(ctx, tx, newEvent, user)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.User{}, s.resolveNoRowsAffected(ctx, user.ID)
+	}
+	if err != nil {
+		return core.User{}, translateError(err)
+	}
+	return user, nil
+}
+
+// DeleteUser - deletes user from db. Returns core.NotFoundError if there is no such user and
+// core.PreconditionFailedError if it has been modified since given version. Zero version deletes unconditionally.
+// Event built by newEvent, out of the user carrying only its ID, is stored in the outbox within the same transaction.
+func (s *Store) DeleteUser(ctx context.Context, id uuid.UUID, version time.Time, newEvent core.EventBuilder) error {
+	query, args := withVersion(deleteUserStmt, []interface{}{id}, version)
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return sql.ErrNoRows
+		}
+		return s.saveEvent(ctx, tx, newEvent, core.User{ID: id})
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return s.resolveNoRowsAffected(ctx, id)
+	}
+	return translateError(err)
+}
+
+func (s *Store) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// withVersion - extends query with optimistic concurrency condition
+func withVersion(query string, args []interface{}, version time.Time) (string, []interface{}) {
+	if version.IsZero() {
+		return query, args
+	}
+	return query + fmt.Sprintf(" AND updated_at=$%d", len(args)+1), append(args, version)
+}
+
+// resolveNoRowsAffected - tells apart missing user from stale version when a write has affected no rows
+func (s *Store) resolveNoRowsAffected(ctx context.Context, id uuid.UUID) error {
+	var updatedAt time.Time
+	err := s.db.QueryRowContext(ctx, userVersionStmt, id).Scan(&updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &core.NotFoundError{Entity: "user", Key: id.String()}
+	}
+	if err != nil {
+		return translateError(err)
+	}
+	return &core.PreconditionFailedError{Message: "user has been modified in the meantime"}
 }
 
-func (s *Store) GetAllUsers(ctx context.Context, filter core.UserFilter) (users []*core.User, previousPage, nextPage string, total int, err error) {
+// GetUserByID - returns core.NotFoundError if there is no user with such id
+func (s *Store) GetUserByID(ctx context.Context, id uuid.UUID) (core.User, error) {
+	return s.getUser(ctx, id.String(), getUserStmt+" WHERE id=$1", id)
+}
+
+// GetUserByEmail - returns core.NotFoundError if there is no user with such email. Emails are case insensitive.
+func (s *Store) GetUserByEmail(ctx context.Context, email string) (core.User, error) {
+	return s.getUser(ctx, email, getUserStmt+" WHERE lower(email)=lower($1)", email)
+}
+
+func (s *Store) getUser(ctx context.Context, key string, query string, args ...interface{}) (core.User, error) {
 	var (
-		createdAt        string
-		id               string
-		offset           int
-		whereOrAndClause string
+		u          core.User
+		verifiedAt sql.NullTime
+	)
+	err := s.db.QueryRowContext(ctx, query, args...).Scan(
+		&u.ID,
+		&u.FirstName,
+		&u.LastName,
+		&u.Nickname,
+		&u.Password,
+		&u.Email,
+		&u.Country,
+		&u.Role,
+		&verifiedAt,
+		&u.CreatedAt,
+		&u.UpdatedAt,
 	)
-	getAllUsersBaseStmt := `SELECT id, first_name, last_name, nickname, password, email, country, created_at, updated_at FROM users`
-	args, nextPlaceholder := s.buildArgumentsAndExtendQueryFromFilter(filter, getAllUsersBaseStmt)
-	whereOrAndClause = "AND"
-	if len(args) == 0 {
-		whereOrAndClause = "WHERE"
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.User{}, &core.NotFoundError{Entity: "user", Key: key}
 	}
+	if err != nil {
+		return core.User{}, translateError(err)
+	}
+	u.EmailVerifiedAt = verifiedAt.Time
+	return u, nil
+}
+
+// GetAllUsers - page of users matching the filter. One more user than the limit is fetched, it tells whether
+// there are users following the page.
+func (s *Store) GetAllUsers(ctx context.Context, filter core.UserFilter) (core.UserPage, error) {
 	limit := filter.Limit
-	if limit == 0 {
+	if limit <= 0 {
 		limit = DEFAULT_LIMIT
 	}
-	// Newly created users first
-	sortOrder := "DESC"
+	query := newFilterQuery(filter)
+	keys, rank, err := query.sortKeys(filter)
+	if err != nil {
+		return core.UserPage{}, err
+	}
+	selectStmt := getUserStmt
+	if rank != "" {
+		selectStmt = fmt.Sprintf("SELECT %s, %s AS rank FROM users", userColumns, rank)
+	}
+	binding := filterBinding(filter)
+	backwards := false
 	if filter.NextPage != "" {
-		createdAt, id, offset, err = s.decodeCursor(filter.NextPage)
+		pos, err := decodePosition(s.cursors, filter.NextPage, binding, keys)
 		if err != nil {
-			return nil, "", "", 0, err
+			return core.UserPage{}, err
 		}
-		getAllUsersBaseStmt += fmt.Sprintf(" %s (created_at, id) < ($%d, $%d)", whereOrAndClause, nextPlaceholder, nextPlaceholder+1)
-		args = append(args, createdAt, id)
-		nextPlaceholder += 2
-		offset += limit
+		query.after(keys, pos.Values, false)
 	} else if filter.PreviousPage != "" {
-		createdAt, id, offset, err = s.decodeCursor(filter.PreviousPage)
+		pos, err := decodePosition(s.cursors, filter.PreviousPage, binding, keys)
 		if err != nil {
-			return nil, "", "", 0, err
-		}
-		// if offset - limit = 0, then reload first page again. In that case if new users have appeared
-		// they will be part of new pagination starting from the first page.
-		if offset-limit != 0 {
-			getAllUsersBaseStmt += fmt.Sprintf(" %s (created_at, id) > ($%d, $%d)", whereOrAndClause, nextPlaceholder, nextPlaceholder+1)
-			args = append(args, createdAt, id)
-			nextPlaceholder += 2
-			// Reverse the set in order to move backwards
-			sortOrder = "ASC"
-		}
-		offset -= limit
-	} else {
-		offset = 0
+			return core.UserPage{}, err
+		}
+		// Reverse the order in order to move backwards
+		query.after(keys, pos.Values, true)
+		backwards = true
 	}
+	stmt := selectStmt + query.where() + orderBy(keys, backwards) + fmt.Sprintf(" LIMIT %d", limit+1)
 
-	getAllUsersBaseStmt += fmt.Sprintf(" ORDER BY created_at %s, id %s", sortOrder, sortOrder)
-	getAllUsersBaseStmt += fmt.Sprintf(" LIMIT %d", limit)
-
-	rows, err := s.db.QueryContext(ctx, getAllUsersBaseStmt, args...)
+	rows, err := s.db.QueryContext(ctx, stmt, query.args...)
+	if err != nil {
+		return core.UserPage{}, translateError(err)
+	}
 	defer func() {
 		_ = rows.Close()
 	}()
-	if err != nil {
-		return nil, "", "", 0, err
-	}
 
-	results := make([]*core.User, 0)
+	page := resultPage{keys: keys, users: make([]*core.User, 0), cursors: s.cursors, binding: binding}
 	for rows.Next() {
-		var u core.User
-		userRowErr := rows.Scan(
+		var (
+			u          core.User
+			verifiedAt sql.NullTime
+			userRank   float64
+		)
+		dest := []interface{}{
 			&u.ID,
 			&u.FirstName,
 			&u.LastName,
@@ -146,142 +405,269 @@ func (s *Store) GetAllUsers(ctx context.Context, filter core.UserFilter) (users
 			&u.Password,
 			&u.Email,
 			&u.Country,
+			&u.Role,
+			&verifiedAt,
 			&u.CreatedAt,
 			&u.UpdatedAt,
-		)
+		}
+		if rank != "" {
+			dest = append(dest, &userRank)
+		}
+		userRowErr := rows.Scan(dest...)
 		if userRowErr != nil {
-			return nil, "", "", 0, userRowErr
+			return core.UserPage{}, translateError(userRowErr)
 		}
-		results = append(results, &u)
+		u.EmailVerifiedAt = verifiedAt.Time
+		page.users = append(page.users, &u)
+		page.ranks = append(page.ranks, userRank)
+	}
+	if err := rows.Err(); err != nil {
+		return core.UserPage{}, translateError(err)
+	}
+	// The extra user is past the end of the page, in the direction of the listing
+	more := len(page.users) > limit
+	if more {
+		page.users = page.users[:limit]
+		page.ranks = page.ranks[:limit]
+	}
+	if backwards {
+		page.reverse()
 	}
 
-	total, err = s.totalCount(ctx, filter)
-	if err != nil {
-		return nil, "", "", 0, err
+	result := core.UserPage{Users: page.users}
+	if len(page.users) > 0 {
+		// Moving backwards, the page the cursor comes from follows this one
+		hasPrevious := (backwards && more) || filter.NextPage != ""
+		result.HasMore = (!backwards && more) || backwards
+		if hasPrevious {
+			result.PreviousPage, err = page.cursor(0)
+			if err != nil {
+				return core.UserPage{}, err
+			}
+		}
+		if result.HasMore {
+			result.NextPage, err = page.cursor(len(page.users) - 1)
+			if err != nil {
+				return core.UserPage{}, err
+			}
+		}
 	}
 
-	previousPage = s.setPreviousPage(results, offset, limit)
-	nextPage = s.setNextPage(results, offset, total, limit)
+	switch filter.Count {
+	case core.CountNone:
+	case core.CountEstimated:
+		total, estimated, err := s.estimatedCount(ctx, filter)
+		if err != nil {
+			return core.UserPage{}, err
+		}
+		result.Total = &total
+		result.TotalEstimated = estimated
+	default:
+		total, err := s.totalCount(ctx, filter)
+		if err != nil {
+			return core.UserPage{}, err
+		}
+		result.Total = &total
+	}
+	return result, nil
+}
 
-	return results, previousPage, nextPage, total, nil
+// searchColumns - columns matched by UserFilter.Query
+var searchColumns = []string{"first_name", "last_name", "nickname", "email"}
+
+// filterQuery - conditions of WHERE clause along with their arguments
+type filterQuery struct {
+	conditions []string
+	args       []interface{}
 }
 
-// returns slice of arguments to be used in the query as well as the last placeholder.
-func (s *Store) buildArgumentsAndExtendQueryFromFilter(filter core.UserFilter, getUsersBaseStmt string) ([]interface{}, int) {
-	var args []interface{}
-	nextPlaceholder := 0
-	whereOrAndStmt := "WHERE"
-	if filter.Country != "" {
-		getUsersBaseStmt += fmt.Sprintf(" %s country=$%d", whereOrAndStmt, nextPlaceholder)
-		args = append(args, filter.Country)
-		whereOrAndStmt = "AND"
-		nextPlaceholder++
+// newFilterQuery - conditions of the filter, along with partial or fuzzy match of the search term
+func newFilterQuery(filter core.UserFilter) *filterQuery {
+	q := &filterQuery{}
+	if len(filter.IDs) > 0 {
+		ids := make([]string, 0, len(filter.IDs))
+		for _, id := range filter.IDs {
+			ids = append(ids, id.String())
+		}
+		q.conditions = append(q.conditions, fmt.Sprintf("id = ANY(%s::UUID[])", q.arg(pq.Array(ids))))
 	}
-	if filter.FirstName != "" {
-		getUsersBaseStmt += fmt.Sprintf(" %s first_name=$%d", whereOrAndStmt, nextPlaceholder)
-		args = append(args, filter.FirstName)
-		whereOrAndStmt = "AND"
-		nextPlaceholder++
+	q.matchStrings("country", filter.Country)
+	q.matchStrings("first_name", filter.FirstName)
+	q.matchStrings("last_name", filter.LastName)
+	q.matchStrings("nickname", filter.Nickname)
+	// Domains are case insensitive
+	q.matchStrings("lower(split_part(email, '@', 2))", lowerCondition(filter.EmailDomain))
+	q.matchTimes("created_at", filter.CreatedAt)
+	q.matchTimes("updated_at", filter.UpdatedAt)
+	if filter.Query != "" {
+		// Case insensitive substring match or trigram similarity, both backed by trigram indexes
+		term := strings.ToLower(filter.Query)
+		contains := q.arg("%" + escapeLike(term) + "%")
+		similar := q.arg(term)
+		matches := make([]string, 0, 2*len(searchColumns))
+		for _, column := range searchColumns {
+			matches = append(matches,
+				fmt.Sprintf("lower(%s) LIKE %s", column, contains),
+				fmt.Sprintf("lower(%s) %% %s", column, similar),
+			)
+		}
+		q.conditions = append(q.conditions, "("+strings.Join(matches, " OR ")+")")
 	}
-	if filter.LastName != "" {
-		getUsersBaseStmt += fmt.Sprintf(" %s last_name=$%d", whereOrAndStmt, nextPlaceholder)
-		args = append(args, filter.LastName)
-		whereOrAndStmt = "AND"
-		nextPlaceholder++
+	return q
+}
+
+func (q *filterQuery) matchStrings(expr string, condition core.StringCondition) {
+	if len(condition.In) > 0 {
+		q.conditions = append(q.conditions, fmt.Sprintf("%s = ANY(%s::TEXT[])", expr, q.arg(pq.Array(condition.In))))
 	}
-	if filter.Nickname != "" {
-		getUsersBaseStmt += fmt.Sprintf(" %s nickname=$%d", whereOrAndStmt, nextPlaceholder)
-		args = append(args, filter.Nickname)
+	if len(condition.NotIn) > 0 {
+		q.conditions = append(q.conditions, fmt.Sprintf("%s <> ALL(%s::TEXT[])", expr, q.arg(pq.Array(condition.NotIn))))
 	}
-	return args, nextPlaceholder + 1
 }
 
-func (s *Store) setPreviousPage(results []*core.User, offset, limit int) string {
-	// If offset - limit = 0, it means that we are on the first page, hence it makes no sense to set previous_page
-	if offset-limit >= 0 && len(results) > 0 {
-		return s.encodeCursor(results[len(results)-1].CreatedAt.UTC(), results[len(results)-1].ID.String(), offset)
+func (q *filterQuery) matchTimes(column string, r core.TimeRange) {
+	bounds := []struct {
+		operator string
+		value    time.Time
+	}{
+		{">", r.After},
+		{">=", r.From},
+		{"<", r.Before},
+		{"<=", r.Until},
+	}
+	for _, bound := range bounds {
+		if !bound.value.IsZero() {
+			q.conditions = append(q.conditions, fmt.Sprintf("%s %s %s", column, bound.operator, q.arg(bound.value)))
+		}
 	}
-	return ""
 }
 
-func (s *Store) setNextPage(results []*core.User, offset, total, limit int) string {
-	// Only in this case it makes sense to put next_page as part of response. Otherwise it means that we are on
-	// the last page, hence next_page is obsolete.
-	if total-offset > 0 && len(results) >= limit && (total-offset != limit) {
-		return s.encodeCursor(results[len(results)-1].CreatedAt.UTC(), results[len(results)-1].ID.String(), offset)
+func lowerCondition(condition core.StringCondition) core.StringCondition {
+	lower := func(values []string) []string {
+		lowered := make([]string, 0, len(values))
+		for _, value := range values {
+			lowered = append(lowered, strings.ToLower(value))
+		}
+		return lowered
 	}
-	return ""
+	return core.StringCondition{In: lower(condition.In), NotIn: lower(condition.NotIn)}
 }
 
-func (s *Store) decodeCursor(encodedCursor string) (createdAt, id string, offset int, err error) {
-	byt, err := base64.StdEncoding.DecodeString(encodedCursor)
-	if err != nil {
-		return
+// rank - relevance of the user to the search term. Prefix matches go first, then the most similar users.
+func (q *filterQuery) rank(term string) string {
+	term = strings.ToLower(term)
+	prefix := q.arg(escapeLike(term) + "%")
+	similar := q.arg(term)
+	prefixes := make([]string, 0, len(searchColumns))
+	similarities := make([]string, 0, len(searchColumns))
+	for _, column := range searchColumns {
+		prefixes = append(prefixes, fmt.Sprintf("lower(%s) LIKE %s", column, prefix))
+		similarities = append(similarities, fmt.Sprintf("similarity(lower(%s), %s)", column, similar))
 	}
+	// Cast makes the rank survive a round trip through the cursor unchanged
+	return fmt.Sprintf("((CASE WHEN %s THEN 1 ELSE 0 END) + GREATEST(%s))::FLOAT8",
+		strings.Join(prefixes, " OR "), strings.Join(similarities, ", "))
+}
+
+func (q *filterQuery) arg(value interface{}) string {
+	q.args = append(q.args, value)
+	return fmt.Sprintf("$%d", len(q.args))
+}
 
-	arrStr := strings.Split(string(byt), ",")
-	if len(arrStr) != 3 {
-		err = errors.New("cursor is invalid")
-		return
+func (q *filterQuery) where() string {
+	if len(q.conditions) == 0 {
+		return ""
 	}
+	return " WHERE " + strings.Join(q.conditions, " AND ")
+}
+
+// escapeLike - makes LIKE wildcards in the term match literally
+func escapeLike(term string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(term)
+}
 
-	createdAt = arrStr[0]
-	id = arrStr[1]
-	offset, err = strconv.Atoi(arrStr[2])
+func (s *Store) totalCount(ctx context.Context, filter core.UserFilter) (int, error) {
+	query := newFilterQuery(filter)
+	var total int
+	err := s.db.QueryRowContext(ctx, `SELECT count(*) FROM users`+query.where(), query.args...).Scan(&total)
 	if err != nil {
-		err = errors.New("cursor is invalid")
-		return
+		return 0, translateError(err)
 	}
-
-	return createdAt, id, offset, nil
+	return total, nil
 }
 
-func (s *Store) encodeCursor(createdAt time.Time, id string, offset int) string {
-	createdAtAsStr := fmt.Sprintf("%d-%d-%d %d:%d:%d", createdAt.Year(), int(createdAt.Month()),
-		createdAt.Day(), createdAt.Hour(), createdAt.Minute(), createdAt.Second())
-	key := fmt.Sprintf("%s,%s,%d", createdAtAsStr, id, offset)
-	return base64.StdEncoding.EncodeToString([]byte(key))
+// estimatedCount - number of users matching the filter as estimated out of CockroachDB table statistics. Users
+// matching a filter are estimated by the optimizer, which derives the estimate from the statistics as well.
+// Exact count is returned while there are no statistics yet, estimated tells which one it is.
+func (s *Store) estimatedCount(ctx context.Context, filter core.UserFilter) (total int, estimated bool, err error) {
+	query := newFilterQuery(filter)
+	var (
+		rows  int64
+		found bool
+	)
+	if len(query.conditions) == 0 {
+		err = s.db.QueryRowContext(ctx, estimatedUsersStmt).Scan(&rows)
+		found = err == nil
+		if errors.Is(err, sql.ErrNoRows) {
+			err = nil
+		}
+	} else {
+		rows, found, err = s.plannedRows(ctx, "SELECT 1 FROM users"+query.where(), query.args)
+	}
+	if err != nil {
+		return 0, false, translateError(err)
+	}
+	if !found {
+		total, err = s.totalCount(ctx, filter)
+		return total, false, err
+	}
+	return int(rows), true, nil
 }
 
-func (s *Store) totalCount(ctx context.Context, filter core.UserFilter) (int, error) {
-	getAllUsersBaseStmt := `SELECT count(*) FROM users`
-	args, _ := s.buildArgumentsAndExtendQueryFromFilter(filter, getAllUsersBaseStmt)
-	rows, err := s.db.QueryContext(ctx, getAllUsersBaseStmt, args...)
+// plannedRows - rows the optimizer expects the query to return, found is false unless the estimate is backed by
+// table statistics
+func (s *Store) plannedRows(ctx context.Context, query string, args []interface{}) (rows int64, found bool, err error) {
+	plan, err := s.db.QueryContext(ctx, "EXPLAIN "+query, args...)
 	if err != nil {
-		return 0, err
+		return 0, false, err
 	}
 	defer func() {
-		_ = rows.Close()
+		_ = plan.Close()
 	}()
-	var total int
-	for rows.Next() {
-		err = rows.Scan(&total)
-		if err != nil {
-			return 0, err
+	var lines []string
+	for plan.Next() {
+		var line string
+		if err := plan.Scan(&line); err != nil {
+			return 0, false, err
 		}
+		lines = append(lines, line)
 	}
-	if rows.Err() != nil {
-		return 0, err
+	if err := plan.Err(); err != nil {
+		return 0, false, err
 	}
-	return total, nil
+	rows, found = estimatedRowCount(lines)
+	return rows, found, nil
 }
 
-func (s *Store) InitDBTables(ctx context.Context) {
-
-	path := filepath.Join("./migrations", "001_create_users_db.up.sql")
-
-	c, ioErr := ioutil.ReadFile(path)
-	if ioErr != nil {
-		// handle error.
-		fmt.Println("XXX error reading file")
-		panic(ioErr)
-	}
-	sql := string(c)
-
-	_, err := s.db.ExecContext(ctx, sql)
-	if err != nil {
-		fmt.Println("XXX error initiating users table")
-		panic(err)
+// estimatedRowCount - estimate of the top node of the plan printed by EXPLAIN, every node carries a line like
+// "estimated row count: 1,234 (12% of the table; stats collected 3 minutes ago)". Nodes are printed top down, hence
+// the first estimate is the one of the whole query. Plans of tables without statistics carry no estimates.
+func estimatedRowCount(plan []string) (int64, bool) {
+	const label = "estimated row count:"
+	for _, line := range plan {
+		i := strings.Index(line, label)
+		if i < 0 {
+			continue
+		}
+		fields := strings.Fields(line[i+len(label):])
+		if len(fields) == 0 {
+			return 0, false
+		}
+		rows, err := strconv.ParseInt(strings.ReplaceAll(fields[0], ",", ""), 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		return rows, true
 	}
-
+	return 0, false
 }
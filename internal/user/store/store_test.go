@@ -0,0 +1,262 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/cursor"
+	"com.user.com/user/internal/migrate"
+	"com.user.com/user/migrations"
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Estimated row count", func() {
+	table.DescribeTable("takes the estimate of the top node of the plan",
+		func(plan []string, rows int64, found bool) {
+			actualRows, actualFound := estimatedRowCount(plan)
+			Expect(actualFound).To(Equal(found))
+			Expect(actualRows).To(Equal(rows))
+		},
+		table.Entry("filtered scan", []string{
+			"distribution: local",
+			"vectorized: true",
+			"",
+			"• filter",
+			"│ estimated row count: 1,234",
+			"│ filter: country = 'BG'",
+			"│",
+			"└── • scan",
+			"      estimated row count: 10,000 (100% of the table; stats collected 3 minutes ago)",
+			"      table: users@users_pkey",
+		}, int64(1234), true),
+		table.Entry("single node", []string{
+			"• scan",
+			"  estimated row count: 42 (0.42% of the table; stats collected 1 hour ago)",
+		}, int64(42), true),
+		table.Entry("table without statistics", []string{
+			"• filter",
+			"│ filter: country = 'BG'",
+			"│",
+			"└── • scan",
+			"      missing stats",
+			"      table: users@users_pkey",
+		}, int64(0), false),
+		table.Entry("malformed estimate", []string{
+			"  estimated row count: many",
+		}, int64(0), false),
+	)
+})
+
+// Specs below run against the db of docker-compose, e.g.
+// TEST_DB_CONNECT_STRING=postgresql://root@localhost:26257/defaultdb?sslmode=disable go test ./internal/user/store/
+var _ = Describe("User Store", func() {
+	var (
+		db      *sql.DB
+		store   *Store
+		ctx     context.Context
+		country string
+		users   []core.User
+	)
+
+	BeforeEach(func() {
+		connectString := os.Getenv("TEST_DB_CONNECT_STRING")
+		if connectString == "" {
+			Skip("TEST_DB_CONNECT_STRING is not set")
+		}
+		ctx = context.Background()
+		var err error
+		db, err = sql.Open("postgres", connectString)
+		Expect(err).To(BeNil())
+		ms, err := migrate.Load(migrations.FS)
+		Expect(err).To(BeNil())
+		Expect(migrate.NewMigrator(db, ms).Up(ctx)).To(Succeed())
+		store = NewStore(db, cursor.NewCodec([]byte("test-key"), time.Hour))
+
+		// Users of every run are told apart by their country, so runs do not see each other
+		country = uuid.New().String()
+		users = nil
+		for i := 0; i < 3; i++ {
+			id := uuid.New()
+			saved, err := store.SaveUser(ctx, core.User{
+				ID:        id,
+				FirstName: "Harry",
+				LastName:  "Potter",
+				Nickname:  id.String(),
+				Password:  "hashed-password",
+				Email:     fmt.Sprintf("%s@hogwarts.test", id),
+				Country:   country,
+				Role:      core.RoleUser,
+			}, nil)
+			Expect(err).To(BeNil())
+			users = append(users, saved)
+		}
+	})
+
+	AfterEach(func() {
+		if db == nil {
+			return
+		}
+		for _, tokens := range []string{"email_verification_tokens", "password_reset_tokens", "refresh_tokens"} {
+			_, err := db.ExecContext(ctx, `DELETE FROM `+tokens+` WHERE user_id IN (SELECT id FROM users WHERE country=$1)`, country)
+			Expect(err).To(BeNil())
+		}
+		_, err := db.ExecContext(ctx, `DELETE FROM users WHERE country=$1`, country)
+		Expect(err).To(BeNil())
+		Expect(db.Close()).To(Succeed())
+	})
+
+	Context("GetAllUsers", func() {
+		var filter core.UserFilter
+		BeforeEach(func() {
+			filter = core.UserFilter{Limit: 1, Country: core.StringCondition{In: []string{country}}}
+		})
+
+		It("counts matching users exactly by default", func() {
+			page, err := store.GetAllUsers(ctx, filter)
+			Expect(err).To(BeNil())
+			Expect(page.Users).To(HaveLen(1))
+			Expect(page.HasMore).To(BeTrue())
+			Expect(*page.Total).To(Equal(3))
+			Expect(page.TotalEstimated).To(BeFalse())
+		})
+
+		Context("With estimated count", func() {
+			BeforeEach(func() {
+				filter.Count = core.CountEstimated
+				_, err := db.ExecContext(ctx, `CREATE STATISTICS users_test FROM users`)
+				Expect(err).To(BeNil())
+			})
+			It("estimates all users out of table statistics", func() {
+				var exact int
+				Expect(db.QueryRowContext(ctx, `SELECT count(*) FROM users`).Scan(&exact)).To(Succeed())
+				page, err := store.GetAllUsers(ctx, core.UserFilter{Limit: 1, Count: core.CountEstimated})
+				Expect(err).To(BeNil())
+				Expect(page.TotalEstimated).To(BeTrue())
+				Expect(*page.Total).To(Equal(exact))
+			})
+			It("estimates matching users by the optimizer", func() {
+				page, err := store.GetAllUsers(ctx, filter)
+				Expect(err).To(BeNil())
+				Expect(page.TotalEstimated).To(BeTrue())
+				Expect(*page.Total).To(BeNumerically(">=", 1))
+			})
+		})
+	})
+
+	Context("Email verification", func() {
+		var (
+			current core.User
+			token   core.EmailVerificationToken
+		)
+		BeforeEach(func() {
+			current = users[0]
+			token = core.EmailVerificationToken{
+				ID:        uuid.New(),
+				UserID:    current.ID,
+				Email:     current.Email,
+				TokenHash: uuid.New().String(),
+				ExpiresAt: time.Now().Add(time.Hour),
+			}
+			Expect(store.SaveEmailVerificationToken(ctx, token)).To(Succeed())
+		})
+
+		It("verifies the email by the token once", func() {
+			verified, err := store.VerifyEmail(ctx, current, token.TokenHash, current.UpdatedAt, nil)
+			Expect(err).To(BeNil())
+			Expect(verified.EmailVerified()).To(BeTrue())
+			_, err = store.VerifyEmail(ctx, verified, token.TokenHash, verified.UpdatedAt, nil)
+			Expect(err).To(BeAssignableToTypeOf(&core.NotFoundError{}))
+		})
+
+		It("rejects the token once another one has been issued", func() {
+			next := token
+			next.ID = uuid.New()
+			next.TokenHash = uuid.New().String()
+			Expect(store.SaveEmailVerificationToken(ctx, next)).To(Succeed())
+			_, err := store.VerifyEmail(ctx, current, token.TokenHash, current.UpdatedAt, nil)
+			Expect(err).To(BeAssignableToTypeOf(&core.NotFoundError{}))
+			_, err = store.VerifyEmail(ctx, current, next.TokenHash, current.UpdatedAt, nil)
+			Expect(err).To(BeNil())
+		})
+
+		It("rejects the token of another email", func() {
+			other := current
+			other.Email = "other@hogwarts.test"
+			_, err := store.VerifyEmail(ctx, other, token.TokenHash, time.Time{}, nil)
+			Expect(err).To(BeAssignableToTypeOf(&core.NotFoundError{}))
+		})
+
+		It("keeps the token when the user has been modified in the meantime", func() {
+			_, err := store.VerifyEmail(ctx, current, token.TokenHash, current.UpdatedAt.Add(-time.Second), nil)
+			Expect(err).To(BeAssignableToTypeOf(&core.PreconditionFailedError{}))
+			_, err = store.VerifyEmail(ctx, current, token.TokenHash, current.UpdatedAt, nil)
+			Expect(err).To(BeNil())
+		})
+	})
+
+	Context("Password reset", func() {
+		var (
+			current   core.User
+			tokenHash string
+		)
+		BeforeEach(func() {
+			current = users[0]
+			current.Password = "new-hashed-password"
+			tokenHash = uuid.New().String()
+			_, err := db.ExecContext(ctx, `INSERT INTO password_reset_tokens (id, user_id, token_hash, expires_at, created_at) VALUES ($1, $2, $3, now() + INTERVAL '1 hour', now())`,
+				uuid.New(), current.ID, tokenHash)
+			Expect(err).To(BeNil())
+			_, err = db.ExecContext(ctx, `INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, expires_at, created_at) VALUES ($1, $2, $3, $4, now() + INTERVAL '1 hour', now())`,
+				uuid.New(), current.ID, uuid.New(), uuid.New().String())
+			Expect(err).To(BeNil())
+		})
+		activeRefreshTokens := func() int {
+			var active int
+			Expect(db.QueryRowContext(ctx, `SELECT count(*) FROM refresh_tokens WHERE user_id=$1 AND revoked_at IS NULL`, current.ID).Scan(&active)).To(Succeed())
+			return active
+		}
+
+		It("replaces the password and ends all sessions of the user", func() {
+			reset, err := store.ResetPassword(ctx, current, tokenHash, current.UpdatedAt, nil)
+			Expect(err).To(BeNil())
+			stored, err := store.GetUserByID(ctx, current.ID)
+			Expect(err).To(BeNil())
+			Expect(stored.Password).To(Equal("new-hashed-password"))
+			Expect(stored.UpdatedAt).To(Equal(reset.UpdatedAt))
+			Expect(activeRefreshTokens()).To(Equal(0))
+		})
+
+		It("rejects the token once it has been used", func() {
+			reset, err := store.ResetPassword(ctx, current, tokenHash, current.UpdatedAt, nil)
+			Expect(err).To(BeNil())
+			again := reset
+			again.Password = "other-hashed-password"
+			_, err = store.ResetPassword(ctx, again, tokenHash, reset.UpdatedAt, nil)
+			Expect(err).To(BeAssignableToTypeOf(&core.NotFoundError{}))
+			stored, err := store.GetUserByID(ctx, current.ID)
+			Expect(err).To(BeNil())
+			Expect(stored.Password).To(Equal("new-hashed-password"))
+		})
+
+		It("rejects the token of another user", func() {
+			other := users[1]
+			_, err := store.ResetPassword(ctx, other, tokenHash, time.Time{}, nil)
+			Expect(err).To(BeAssignableToTypeOf(&core.NotFoundError{}))
+		})
+
+		It("keeps the token and the sessions when the user has been modified in the meantime", func() {
+			_, err := store.ResetPassword(ctx, current, tokenHash, current.UpdatedAt.Add(-time.Second), nil)
+			Expect(err).To(BeAssignableToTypeOf(&core.PreconditionFailedError{}))
+			Expect(activeRefreshTokens()).To(Equal(1))
+			_, err = store.ResetPassword(ctx, current, tokenHash, current.UpdatedAt, nil)
+			Expect(err).To(BeNil())
+		})
+	})
+})
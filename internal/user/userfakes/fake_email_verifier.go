@@ -0,0 +1,201 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package userfakes
+
+import (
+	"context"
+	"sync"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/user"
+)
+
+type FakeEmailVerifier struct {
+	NewTokenStub        func(core.User) (string, core.EmailVerificationToken, error)
+	newTokenMutex       sync.RWMutex
+	newTokenArgsForCall []struct {
+		arg1 core.User
+	}
+	newTokenReturns struct {
+		result1 string
+		result2 core.EmailVerificationToken
+		result3 error
+	}
+	newTokenReturnsOnCall map[int]struct {
+		result1 string
+		result2 core.EmailVerificationToken
+		result3 error
+	}
+	SendVerificationStub        func(context.Context, core.User, string) error
+	sendVerificationMutex       sync.RWMutex
+	sendVerificationArgsForCall []struct {
+		arg1 context.Context
+		arg2 core.User
+		arg3 string
+	}
+	sendVerificationReturns struct {
+		result1 error
+	}
+	sendVerificationReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeEmailVerifier) NewToken(arg1 core.User) (string, core.EmailVerificationToken, error) {
+	fake.newTokenMutex.Lock()
+	ret, specificReturn := fake.newTokenReturnsOnCall[len(fake.newTokenArgsForCall)]
+	fake.newTokenArgsForCall = append(fake.newTokenArgsForCall, struct {
+		arg1 core.User
+	}{arg1})
+	stub := fake.NewTokenStub
+	fakeReturns := fake.newTokenReturns
+	fake.recordInvocation("NewToken", []interface{}{arg1})
+	fake.newTokenMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *FakeEmailVerifier) NewTokenCallCount() int {
+	fake.newTokenMutex.RLock()
+	defer fake.newTokenMutex.RUnlock()
+	return len(fake.newTokenArgsForCall)
+}
+
+func (fake *FakeEmailVerifier) NewTokenCalls(stub func(core.User) (string, core.EmailVerificationToken, error)) {
+	fake.newTokenMutex.Lock()
+	defer fake.newTokenMutex.Unlock()
+	fake.NewTokenStub = stub
+}
+
+func (fake *FakeEmailVerifier) NewTokenArgsForCall(i int) core.User {
+	fake.newTokenMutex.RLock()
+	defer fake.newTokenMutex.RUnlock()
+	argsForCall := fake.newTokenArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeEmailVerifier) NewTokenReturns(result1 string, result2 core.EmailVerificationToken, result3 error) {
+	fake.newTokenMutex.Lock()
+	defer fake.newTokenMutex.Unlock()
+	fake.NewTokenStub = nil
+	fake.newTokenReturns = struct {
+		result1 string
+		result2 core.EmailVerificationToken
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeEmailVerifier) NewTokenReturnsOnCall(i int, result1 string, result2 core.EmailVerificationToken, result3 error) {
+	fake.newTokenMutex.Lock()
+	defer fake.newTokenMutex.Unlock()
+	fake.NewTokenStub = nil
+	if fake.newTokenReturnsOnCall == nil {
+		fake.newTokenReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 core.EmailVerificationToken
+			result3 error
+		})
+	}
+	fake.newTokenReturnsOnCall[i] = struct {
+		result1 string
+		result2 core.EmailVerificationToken
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeEmailVerifier) SendVerification(arg1 context.Context, arg2 core.User, arg3 string) error {
+	fake.sendVerificationMutex.Lock()
+	ret, specificReturn := fake.sendVerificationReturnsOnCall[len(fake.sendVerificationArgsForCall)]
+	fake.sendVerificationArgsForCall = append(fake.sendVerificationArgsForCall, struct {
+		arg1 context.Context
+		arg2 core.User
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.SendVerificationStub
+	fakeReturns := fake.sendVerificationReturns
+	fake.recordInvocation("SendVerification", []interface{}{arg1, arg2, arg3})
+	fake.sendVerificationMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeEmailVerifier) SendVerificationCallCount() int {
+	fake.sendVerificationMutex.RLock()
+	defer fake.sendVerificationMutex.RUnlock()
+	return len(fake.sendVerificationArgsForCall)
+}
+
+func (fake *FakeEmailVerifier) SendVerificationCalls(stub func(context.Context, core.User, string) error) {
+	fake.sendVerificationMutex.Lock()
+	defer fake.sendVerificationMutex.Unlock()
+	fake.SendVerificationStub = stub
+}
+
+func (fake *FakeEmailVerifier) SendVerificationArgsForCall(i int) (context.Context, core.User, string) {
+	fake.sendVerificationMutex.RLock()
+	defer fake.sendVerificationMutex.RUnlock()
+	argsForCall := fake.sendVerificationArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeEmailVerifier) SendVerificationReturns(result1 error) {
+	fake.sendVerificationMutex.Lock()
+	defer fake.sendVerificationMutex.Unlock()
+	fake.SendVerificationStub = nil
+	fake.sendVerificationReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeEmailVerifier) SendVerificationReturnsOnCall(i int, result1 error) {
+	fake.sendVerificationMutex.Lock()
+	defer fake.sendVerificationMutex.Unlock()
+	fake.SendVerificationStub = nil
+	if fake.sendVerificationReturnsOnCall == nil {
+		fake.sendVerificationReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.sendVerificationReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeEmailVerifier) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.newTokenMutex.RLock()
+	defer fake.newTokenMutex.RUnlock()
+	fake.sendVerificationMutex.RLock()
+	defer fake.sendVerificationMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeEmailVerifier) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ user.EmailVerifier = new(FakeEmailVerifier)
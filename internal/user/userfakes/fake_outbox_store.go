@@ -0,0 +1,280 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package userfakes
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/user"
+	"github.com/google/uuid"
+)
+
+type FakeOutboxStore struct {
+	ClaimEventsStub        func(context.Context, int, time.Duration) ([]core.PendingEvent, error)
+	claimEventsMutex       sync.RWMutex
+	claimEventsArgsForCall []struct {
+		arg1 context.Context
+		arg2 int
+		arg3 time.Duration
+	}
+	claimEventsReturns struct {
+		result1 []core.PendingEvent
+		result2 error
+	}
+	claimEventsReturnsOnCall map[int]struct {
+		result1 []core.PendingEvent
+		result2 error
+	}
+	MarkEventFailedStub        func(context.Context, uuid.UUID, time.Duration, string) error
+	markEventFailedMutex       sync.RWMutex
+	markEventFailedArgsForCall []struct {
+		arg1 context.Context
+		arg2 uuid.UUID
+		arg3 time.Duration
+		arg4 string
+	}
+	markEventFailedReturns struct {
+		result1 error
+	}
+	markEventFailedReturnsOnCall map[int]struct {
+		result1 error
+	}
+	MarkEventPublishedStub        func(context.Context, uuid.UUID) error
+	markEventPublishedMutex       sync.RWMutex
+	markEventPublishedArgsForCall []struct {
+		arg1 context.Context
+		arg2 uuid.UUID
+	}
+	markEventPublishedReturns struct {
+		result1 error
+	}
+	markEventPublishedReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeOutboxStore) ClaimEvents(arg1 context.Context, arg2 int, arg3 time.Duration) ([]core.PendingEvent, error) {
+	fake.claimEventsMutex.Lock()
+	ret, specificReturn := fake.claimEventsReturnsOnCall[len(fake.claimEventsArgsForCall)]
+	fake.claimEventsArgsForCall = append(fake.claimEventsArgsForCall, struct {
+		arg1 context.Context
+		arg2 int
+		arg3 time.Duration
+	}{arg1, arg2, arg3})
+	stub := fake.ClaimEventsStub
+	fakeReturns := fake.claimEventsReturns
+	fake.recordInvocation("ClaimEvents", []interface{}{arg1, arg2, arg3})
+	fake.claimEventsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeOutboxStore) ClaimEventsCallCount() int {
+	fake.claimEventsMutex.RLock()
+	defer fake.claimEventsMutex.RUnlock()
+	return len(fake.claimEventsArgsForCall)
+}
+
+func (fake *FakeOutboxStore) ClaimEventsCalls(stub func(context.Context, int, time.Duration) ([]core.PendingEvent, error)) {
+	fake.claimEventsMutex.Lock()
+	defer fake.claimEventsMutex.Unlock()
+	fake.ClaimEventsStub = stub
+}
+
+func (fake *FakeOutboxStore) ClaimEventsArgsForCall(i int) (context.Context, int, time.Duration) {
+	fake.claimEventsMutex.RLock()
+	defer fake.claimEventsMutex.RUnlock()
+	argsForCall := fake.claimEventsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeOutboxStore) ClaimEventsReturns(result1 []core.PendingEvent, result2 error) {
+	fake.claimEventsMutex.Lock()
+	defer fake.claimEventsMutex.Unlock()
+	fake.ClaimEventsStub = nil
+	fake.claimEventsReturns = struct {
+		result1 []core.PendingEvent
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeOutboxStore) ClaimEventsReturnsOnCall(i int, result1 []core.PendingEvent, result2 error) {
+	fake.claimEventsMutex.Lock()
+	defer fake.claimEventsMutex.Unlock()
+	fake.ClaimEventsStub = nil
+	if fake.claimEventsReturnsOnCall == nil {
+		fake.claimEventsReturnsOnCall = make(map[int]struct {
+			result1 []core.PendingEvent
+			result2 error
+		})
+	}
+	fake.claimEventsReturnsOnCall[i] = struct {
+		result1 []core.PendingEvent
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeOutboxStore) MarkEventFailed(arg1 context.Context, arg2 uuid.UUID, arg3 time.Duration, arg4 string) error {
+	fake.markEventFailedMutex.Lock()
+	ret, specificReturn := fake.markEventFailedReturnsOnCall[len(fake.markEventFailedArgsForCall)]
+	fake.markEventFailedArgsForCall = append(fake.markEventFailedArgsForCall, struct {
+		arg1 context.Context
+		arg2 uuid.UUID
+		arg3 time.Duration
+		arg4 string
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.MarkEventFailedStub
+	fakeReturns := fake.markEventFailedReturns
+	fake.recordInvocation("MarkEventFailed", []interface{}{arg1, arg2, arg3, arg4})
+	fake.markEventFailedMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeOutboxStore) MarkEventFailedCallCount() int {
+	fake.markEventFailedMutex.RLock()
+	defer fake.markEventFailedMutex.RUnlock()
+	return len(fake.markEventFailedArgsForCall)
+}
+
+func (fake *FakeOutboxStore) MarkEventFailedCalls(stub func(context.Context, uuid.UUID, time.Duration, string) error) {
+	fake.markEventFailedMutex.Lock()
+	defer fake.markEventFailedMutex.Unlock()
+	fake.MarkEventFailedStub = stub
+}
+
+func (fake *FakeOutboxStore) MarkEventFailedArgsForCall(i int) (context.Context, uuid.UUID, time.Duration, string) {
+	fake.markEventFailedMutex.RLock()
+	defer fake.markEventFailedMutex.RUnlock()
+	argsForCall := fake.markEventFailedArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeOutboxStore) MarkEventFailedReturns(result1 error) {
+	fake.markEventFailedMutex.Lock()
+	defer fake.markEventFailedMutex.Unlock()
+	fake.MarkEventFailedStub = nil
+	fake.markEventFailedReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeOutboxStore) MarkEventFailedReturnsOnCall(i int, result1 error) {
+	fake.markEventFailedMutex.Lock()
+	defer fake.markEventFailedMutex.Unlock()
+	fake.MarkEventFailedStub = nil
+	if fake.markEventFailedReturnsOnCall == nil {
+		fake.markEventFailedReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.markEventFailedReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeOutboxStore) MarkEventPublished(arg1 context.Context, arg2 uuid.UUID) error {
+	fake.markEventPublishedMutex.Lock()
+	ret, specificReturn := fake.markEventPublishedReturnsOnCall[len(fake.markEventPublishedArgsForCall)]
+	fake.markEventPublishedArgsForCall = append(fake.markEventPublishedArgsForCall, struct {
+		arg1 context.Context
+		arg2 uuid.UUID
+	}{arg1, arg2})
+	stub := fake.MarkEventPublishedStub
+	fakeReturns := fake.markEventPublishedReturns
+	fake.recordInvocation("MarkEventPublished", []interface{}{arg1, arg2})
+	fake.markEventPublishedMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeOutboxStore) MarkEventPublishedCallCount() int {
+	fake.markEventPublishedMutex.RLock()
+	defer fake.markEventPublishedMutex.RUnlock()
+	return len(fake.markEventPublishedArgsForCall)
+}
+
+func (fake *FakeOutboxStore) MarkEventPublishedCalls(stub func(context.Context, uuid.UUID) error) {
+	fake.markEventPublishedMutex.Lock()
+	defer fake.markEventPublishedMutex.Unlock()
+	fake.MarkEventPublishedStub = stub
+}
+
+func (fake *FakeOutboxStore) MarkEventPublishedArgsForCall(i int) (context.Context, uuid.UUID) {
+	fake.markEventPublishedMutex.RLock()
+	defer fake.markEventPublishedMutex.RUnlock()
+	argsForCall := fake.markEventPublishedArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeOutboxStore) MarkEventPublishedReturns(result1 error) {
+	fake.markEventPublishedMutex.Lock()
+	defer fake.markEventPublishedMutex.Unlock()
+	fake.MarkEventPublishedStub = nil
+	fake.markEventPublishedReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeOutboxStore) MarkEventPublishedReturnsOnCall(i int, result1 error) {
+	fake.markEventPublishedMutex.Lock()
+	defer fake.markEventPublishedMutex.Unlock()
+	fake.MarkEventPublishedStub = nil
+	if fake.markEventPublishedReturnsOnCall == nil {
+		fake.markEventPublishedReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.markEventPublishedReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeOutboxStore) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.claimEventsMutex.RLock()
+	defer fake.claimEventsMutex.RUnlock()
+	fake.markEventFailedMutex.RLock()
+	defer fake.markEventFailedMutex.RUnlock()
+	fake.markEventPublishedMutex.RLock()
+	defer fake.markEventPublishedMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeOutboxStore) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ user.OutboxStore = new(FakeOutboxStore)
@@ -0,0 +1,271 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package userfakes
+
+import (
+	"sync"
+
+	"com.user.com/user/internal/user"
+)
+
+type FakePasswordHasher struct {
+	HashStub        func(string) (string, error)
+	hashMutex       sync.RWMutex
+	hashArgsForCall []struct {
+		arg1 string
+	}
+	hashReturns struct {
+		result1 string
+		result2 error
+	}
+	hashReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	NeedsRehashStub        func(string) bool
+	needsRehashMutex       sync.RWMutex
+	needsRehashArgsForCall []struct {
+		arg1 string
+	}
+	needsRehashReturns struct {
+		result1 bool
+	}
+	needsRehashReturnsOnCall map[int]struct {
+		result1 bool
+	}
+	VerifyStub        func(string, string) (bool, error)
+	verifyMutex       sync.RWMutex
+	verifyArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	verifyReturns struct {
+		result1 bool
+		result2 error
+	}
+	verifyReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakePasswordHasher) Hash(arg1 string) (string, error) {
+	fake.hashMutex.Lock()
+	ret, specificReturn := fake.hashReturnsOnCall[len(fake.hashArgsForCall)]
+	fake.hashArgsForCall = append(fake.hashArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.HashStub
+	fakeReturns := fake.hashReturns
+	fake.recordInvocation("Hash", []interface{}{arg1})
+	fake.hashMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakePasswordHasher) HashCallCount() int {
+	fake.hashMutex.RLock()
+	defer fake.hashMutex.RUnlock()
+	return len(fake.hashArgsForCall)
+}
+
+func (fake *FakePasswordHasher) HashCalls(stub func(string) (string, error)) {
+	fake.hashMutex.Lock()
+	defer fake.hashMutex.Unlock()
+	fake.HashStub = stub
+}
+
+func (fake *FakePasswordHasher) HashArgsForCall(i int) string {
+	fake.hashMutex.RLock()
+	defer fake.hashMutex.RUnlock()
+	argsForCall := fake.hashArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakePasswordHasher) HashReturns(result1 string, result2 error) {
+	fake.hashMutex.Lock()
+	defer fake.hashMutex.Unlock()
+	fake.HashStub = nil
+	fake.hashReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakePasswordHasher) HashReturnsOnCall(i int, result1 string, result2 error) {
+	fake.hashMutex.Lock()
+	defer fake.hashMutex.Unlock()
+	fake.HashStub = nil
+	if fake.hashReturnsOnCall == nil {
+		fake.hashReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.hashReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakePasswordHasher) NeedsRehash(arg1 string) bool {
+	fake.needsRehashMutex.Lock()
+	ret, specificReturn := fake.needsRehashReturnsOnCall[len(fake.needsRehashArgsForCall)]
+	fake.needsRehashArgsForCall = append(fake.needsRehashArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.NeedsRehashStub
+	fakeReturns := fake.needsRehashReturns
+	fake.recordInvocation("NeedsRehash", []interface{}{arg1})
+	fake.needsRehashMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakePasswordHasher) NeedsRehashCallCount() int {
+	fake.needsRehashMutex.RLock()
+	defer fake.needsRehashMutex.RUnlock()
+	return len(fake.needsRehashArgsForCall)
+}
+
+func (fake *FakePasswordHasher) NeedsRehashCalls(stub func(string) bool) {
+	fake.needsRehashMutex.Lock()
+	defer fake.needsRehashMutex.Unlock()
+	fake.NeedsRehashStub = stub
+}
+
+func (fake *FakePasswordHasher) NeedsRehashArgsForCall(i int) string {
+	fake.needsRehashMutex.RLock()
+	defer fake.needsRehashMutex.RUnlock()
+	argsForCall := fake.needsRehashArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakePasswordHasher) NeedsRehashReturns(result1 bool) {
+	fake.needsRehashMutex.Lock()
+	defer fake.needsRehashMutex.Unlock()
+	fake.NeedsRehashStub = nil
+	fake.needsRehashReturns = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakePasswordHasher) NeedsRehashReturnsOnCall(i int, result1 bool) {
+	fake.needsRehashMutex.Lock()
+	defer fake.needsRehashMutex.Unlock()
+	fake.NeedsRehashStub = nil
+	if fake.needsRehashReturnsOnCall == nil {
+		fake.needsRehashReturnsOnCall = make(map[int]struct {
+			result1 bool
+		})
+	}
+	fake.needsRehashReturnsOnCall[i] = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakePasswordHasher) Verify(arg1 string, arg2 string) (bool, error) {
+	fake.verifyMutex.Lock()
+	ret, specificReturn := fake.verifyReturnsOnCall[len(fake.verifyArgsForCall)]
+	fake.verifyArgsForCall = append(fake.verifyArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.VerifyStub
+	fakeReturns := fake.verifyReturns
+	fake.recordInvocation("Verify", []interface{}{arg1, arg2})
+	fake.verifyMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakePasswordHasher) VerifyCallCount() int {
+	fake.verifyMutex.RLock()
+	defer fake.verifyMutex.RUnlock()
+	return len(fake.verifyArgsForCall)
+}
+
+func (fake *FakePasswordHasher) VerifyCalls(stub func(string, string) (bool, error)) {
+	fake.verifyMutex.Lock()
+	defer fake.verifyMutex.Unlock()
+	fake.VerifyStub = stub
+}
+
+func (fake *FakePasswordHasher) VerifyArgsForCall(i int) (string, string) {
+	fake.verifyMutex.RLock()
+	defer fake.verifyMutex.RUnlock()
+	argsForCall := fake.verifyArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakePasswordHasher) VerifyReturns(result1 bool, result2 error) {
+	fake.verifyMutex.Lock()
+	defer fake.verifyMutex.Unlock()
+	fake.VerifyStub = nil
+	fake.verifyReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakePasswordHasher) VerifyReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.verifyMutex.Lock()
+	defer fake.verifyMutex.Unlock()
+	fake.VerifyStub = nil
+	if fake.verifyReturnsOnCall == nil {
+		fake.verifyReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.verifyReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakePasswordHasher) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.hashMutex.RLock()
+	defer fake.hashMutex.RUnlock()
+	fake.needsRehashMutex.RLock()
+	defer fake.needsRehashMutex.RUnlock()
+	fake.verifyMutex.RLock()
+	defer fake.verifyMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakePasswordHasher) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ user.PasswordHasher = new(FakePasswordHasher)
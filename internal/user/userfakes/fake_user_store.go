@@ -4,6 +4,7 @@ package userfakes
 import (
 	"context"
 	"sync"
+	"time"
 
 	"com.user.com/user/internal/core"
 	"com.user.com/user/internal/user"
@@ -11,11 +12,13 @@ import (
 )
 
 type FakeUserStore struct {
-	DeleteUserStub        func(context.Context, uuid.UUID) error
+	DeleteUserStub        func(context.Context, uuid.UUID, time.Time, core.EventBuilder) error
 	deleteUserMutex       sync.RWMutex
 	deleteUserArgsForCall []struct {
 		arg1 context.Context
 		arg2 uuid.UUID
+		arg3 time.Time
+		arg4 core.EventBuilder
 	}
 	deleteUserReturns struct {
 		result1 error
@@ -23,67 +26,161 @@ type FakeUserStore struct {
 	deleteUserReturnsOnCall map[int]struct {
 		result1 error
 	}
-	GetAllUsersStub        func(context.Context, core.UserFilter) ([]*core.User, string, string, int, error)
+	GetAllUsersStub        func(context.Context, core.UserFilter) (core.UserPage, error)
 	getAllUsersMutex       sync.RWMutex
 	getAllUsersArgsForCall []struct {
 		arg1 context.Context
 		arg2 core.UserFilter
 	}
 	getAllUsersReturns struct {
-		result1 []*core.User
-		result2 string
-		result3 string
-		result4 int
-		result5 error
+		result1 core.UserPage
+		result2 error
 	}
 	getAllUsersReturnsOnCall map[int]struct {
-		result1 []*core.User
-		result2 string
-		result3 string
-		result4 int
-		result5 error
+		result1 core.UserPage
+		result2 error
 	}
-	SaveUserStub        func(context.Context, core.User) error
+	GetUserByEmailStub        func(context.Context, string) (core.User, error)
+	getUserByEmailMutex       sync.RWMutex
+	getUserByEmailArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	getUserByEmailReturns struct {
+		result1 core.User
+		result2 error
+	}
+	getUserByEmailReturnsOnCall map[int]struct {
+		result1 core.User
+		result2 error
+	}
+	GetUserByIDStub        func(context.Context, uuid.UUID) (core.User, error)
+	getUserByIDMutex       sync.RWMutex
+	getUserByIDArgsForCall []struct {
+		arg1 context.Context
+		arg2 uuid.UUID
+	}
+	getUserByIDReturns struct {
+		result1 core.User
+		result2 error
+	}
+	getUserByIDReturnsOnCall map[int]struct {
+		result1 core.User
+		result2 error
+	}
+	ResetPasswordStub        func(context.Context, core.User, string, time.Time, core.EventBuilder) (core.User, error)
+	resetPasswordMutex       sync.RWMutex
+	resetPasswordArgsForCall []struct {
+		arg1 context.Context
+		arg2 core.User
+		arg3 string
+		arg4 time.Time
+		arg5 core.EventBuilder
+	}
+	resetPasswordReturns struct {
+		result1 core.User
+		result2 error
+	}
+	resetPasswordReturnsOnCall map[int]struct {
+		result1 core.User
+		result2 error
+	}
+	SaveEmailVerificationTokenStub        func(context.Context, core.EmailVerificationToken) error
+	saveEmailVerificationTokenMutex       sync.RWMutex
+	saveEmailVerificationTokenArgsForCall []struct {
+		arg1 context.Context
+		arg2 core.EmailVerificationToken
+	}
+	saveEmailVerificationTokenReturns struct {
+		result1 error
+	}
+	saveEmailVerificationTokenReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SaveUserStub        func(context.Context, core.User, core.EventBuilder) (core.User, error)
 	saveUserMutex       sync.RWMutex
 	saveUserArgsForCall []struct {
 		arg1 context.Context
 		arg2 core.User
+		arg3 core.EventBuilder
 	}
 	saveUserReturns struct {
-		result1 error
+		result1 core.User
+		result2 error
 	}
 	saveUserReturnsOnCall map[int]struct {
-		result1 error
+		result1 core.User
+		result2 error
 	}
-	UpdateUserStub        func(context.Context, core.User) error
+	UpdateUserStub        func(context.Context, core.User, time.Time, core.EventBuilder) (core.User, error)
 	updateUserMutex       sync.RWMutex
 	updateUserArgsForCall []struct {
 		arg1 context.Context
 		arg2 core.User
+		arg3 time.Time
+		arg4 core.EventBuilder
 	}
 	updateUserReturns struct {
-		result1 error
+		result1 core.User
+		result2 error
 	}
 	updateUserReturnsOnCall map[int]struct {
-		result1 error
+		result1 core.User
+		result2 error
+	}
+	UpdateUserFieldsStub        func(context.Context, core.User, []core.UserField, time.Time, core.EventBuilder) (core.User, error)
+	updateUserFieldsMutex       sync.RWMutex
+	updateUserFieldsArgsForCall []struct {
+		arg1 context.Context
+		arg2 core.User
+		arg3 []core.UserField
+		arg4 time.Time
+		arg5 core.EventBuilder
+	}
+	updateUserFieldsReturns struct {
+		result1 core.User
+		result2 error
+	}
+	updateUserFieldsReturnsOnCall map[int]struct {
+		result1 core.User
+		result2 error
+	}
+	VerifyEmailStub        func(context.Context, core.User, string, time.Time, core.EventBuilder) (core.User, error)
+	verifyEmailMutex       sync.RWMutex
+	verifyEmailArgsForCall []struct {
+		arg1 context.Context
+		arg2 core.User
+		arg3 string
+		arg4 time.Time
+		arg5 core.EventBuilder
+	}
+	verifyEmailReturns struct {
+		result1 core.User
+		result2 error
+	}
+	verifyEmailReturnsOnCall map[int]struct {
+		result1 core.User
+		result2 error
 	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeUserStore) DeleteUser(arg1 context.Context, arg2 uuid.UUID) error {
+func (fake *FakeUserStore) DeleteUser(arg1 context.Context, arg2 uuid.UUID, arg3 time.Time, arg4 core.EventBuilder) error {
 	fake.deleteUserMutex.Lock()
 	ret, specificReturn := fake.deleteUserReturnsOnCall[len(fake.deleteUserArgsForCall)]
 	fake.deleteUserArgsForCall = append(fake.deleteUserArgsForCall, struct {
 		arg1 context.Context
 		arg2 uuid.UUID
-	}{arg1, arg2})
+		arg3 time.Time
+		arg4 core.EventBuilder
+	}{arg1, arg2, arg3, arg4})
 	stub := fake.DeleteUserStub
 	fakeReturns := fake.deleteUserReturns
-	fake.recordInvocation("DeleteUser", []interface{}{arg1, arg2})
+	fake.recordInvocation("DeleteUser", []interface{}{arg1, arg2, arg3, arg4})
 	fake.deleteUserMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2)
+		return stub(arg1, arg2, arg3, arg4)
 	}
 	if specificReturn {
 		return ret.result1
@@ -97,17 +194,17 @@ func (fake *FakeUserStore) DeleteUserCallCount() int {
 	return len(fake.deleteUserArgsForCall)
 }
 
-func (fake *FakeUserStore) DeleteUserCalls(stub func(context.Context, uuid.UUID) error) {
+func (fake *FakeUserStore) DeleteUserCalls(stub func(context.Context, uuid.UUID, time.Time, core.EventBuilder) error) {
 	fake.deleteUserMutex.Lock()
 	defer fake.deleteUserMutex.Unlock()
 	fake.DeleteUserStub = stub
 }
 
-func (fake *FakeUserStore) DeleteUserArgsForCall(i int) (context.Context, uuid.UUID) {
+func (fake *FakeUserStore) DeleteUserArgsForCall(i int) (context.Context, uuid.UUID, time.Time, core.EventBuilder) {
 	fake.deleteUserMutex.RLock()
 	defer fake.deleteUserMutex.RUnlock()
 	argsForCall := fake.deleteUserArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
 }
 
 func (fake *FakeUserStore) DeleteUserReturns(result1 error) {
@@ -133,7 +230,7 @@ func (fake *FakeUserStore) DeleteUserReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
-func (fake *FakeUserStore) GetAllUsers(arg1 context.Context, arg2 core.UserFilter) ([]*core.User, string, string, int, error) {
+func (fake *FakeUserStore) GetAllUsers(arg1 context.Context, arg2 core.UserFilter) (core.UserPage, error) {
 	fake.getAllUsersMutex.Lock()
 	ret, specificReturn := fake.getAllUsersReturnsOnCall[len(fake.getAllUsersArgsForCall)]
 	fake.getAllUsersArgsForCall = append(fake.getAllUsersArgsForCall, struct {
@@ -148,9 +245,9 @@ func (fake *FakeUserStore) GetAllUsers(arg1 context.Context, arg2 core.UserFilte
 		return stub(arg1, arg2)
 	}
 	if specificReturn {
-		return ret.result1, ret.result2, ret.result3, ret.result4, ret.result5
+		return ret.result1, ret.result2
 	}
-	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3, fakeReturns.result4, fakeReturns.result5
+	return fakeReturns.result1, fakeReturns.result2
 }
 
 func (fake *FakeUserStore) GetAllUsersCallCount() int {
@@ -159,7 +256,7 @@ func (fake *FakeUserStore) GetAllUsersCallCount() int {
 	return len(fake.getAllUsersArgsForCall)
 }
 
-func (fake *FakeUserStore) GetAllUsersCalls(stub func(context.Context, core.UserFilter) ([]*core.User, string, string, int, error)) {
+func (fake *FakeUserStore) GetAllUsersCalls(stub func(context.Context, core.UserFilter) (core.UserPage, error)) {
 	fake.getAllUsersMutex.Lock()
 	defer fake.getAllUsersMutex.Unlock()
 	fake.GetAllUsersStub = stub
@@ -172,59 +269,311 @@ func (fake *FakeUserStore) GetAllUsersArgsForCall(i int) (context.Context, core.
 	return argsForCall.arg1, argsForCall.arg2
 }
 
-func (fake *FakeUserStore) GetAllUsersReturns(result1 []*core.User, result2 string, result3 string, result4 int, result5 error) {
+func (fake *FakeUserStore) GetAllUsersReturns(result1 core.UserPage, result2 error) {
 	fake.getAllUsersMutex.Lock()
 	defer fake.getAllUsersMutex.Unlock()
 	fake.GetAllUsersStub = nil
 	fake.getAllUsersReturns = struct {
-		result1 []*core.User
-		result2 string
-		result3 string
-		result4 int
-		result5 error
-	}{result1, result2, result3, result4, result5}
+		result1 core.UserPage
+		result2 error
+	}{result1, result2}
 }
 
-func (fake *FakeUserStore) GetAllUsersReturnsOnCall(i int, result1 []*core.User, result2 string, result3 string, result4 int, result5 error) {
+func (fake *FakeUserStore) GetAllUsersReturnsOnCall(i int, result1 core.UserPage, result2 error) {
 	fake.getAllUsersMutex.Lock()
 	defer fake.getAllUsersMutex.Unlock()
 	fake.GetAllUsersStub = nil
 	if fake.getAllUsersReturnsOnCall == nil {
 		fake.getAllUsersReturnsOnCall = make(map[int]struct {
-			result1 []*core.User
-			result2 string
-			result3 string
-			result4 int
-			result5 error
+			result1 core.UserPage
+			result2 error
 		})
 	}
 	fake.getAllUsersReturnsOnCall[i] = struct {
-		result1 []*core.User
-		result2 string
-		result3 string
-		result4 int
-		result5 error
-	}{result1, result2, result3, result4, result5}
+		result1 core.UserPage
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserStore) GetUserByEmail(arg1 context.Context, arg2 string) (core.User, error) {
+	fake.getUserByEmailMutex.Lock()
+	ret, specificReturn := fake.getUserByEmailReturnsOnCall[len(fake.getUserByEmailArgsForCall)]
+	fake.getUserByEmailArgsForCall = append(fake.getUserByEmailArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetUserByEmailStub
+	fakeReturns := fake.getUserByEmailReturns
+	fake.recordInvocation("GetUserByEmail", []interface{}{arg1, arg2})
+	fake.getUserByEmailMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeUserStore) GetUserByEmailCallCount() int {
+	fake.getUserByEmailMutex.RLock()
+	defer fake.getUserByEmailMutex.RUnlock()
+	return len(fake.getUserByEmailArgsForCall)
+}
+
+func (fake *FakeUserStore) GetUserByEmailCalls(stub func(context.Context, string) (core.User, error)) {
+	fake.getUserByEmailMutex.Lock()
+	defer fake.getUserByEmailMutex.Unlock()
+	fake.GetUserByEmailStub = stub
+}
+
+func (fake *FakeUserStore) GetUserByEmailArgsForCall(i int) (context.Context, string) {
+	fake.getUserByEmailMutex.RLock()
+	defer fake.getUserByEmailMutex.RUnlock()
+	argsForCall := fake.getUserByEmailArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeUserStore) GetUserByEmailReturns(result1 core.User, result2 error) {
+	fake.getUserByEmailMutex.Lock()
+	defer fake.getUserByEmailMutex.Unlock()
+	fake.GetUserByEmailStub = nil
+	fake.getUserByEmailReturns = struct {
+		result1 core.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserStore) GetUserByEmailReturnsOnCall(i int, result1 core.User, result2 error) {
+	fake.getUserByEmailMutex.Lock()
+	defer fake.getUserByEmailMutex.Unlock()
+	fake.GetUserByEmailStub = nil
+	if fake.getUserByEmailReturnsOnCall == nil {
+		fake.getUserByEmailReturnsOnCall = make(map[int]struct {
+			result1 core.User
+			result2 error
+		})
+	}
+	fake.getUserByEmailReturnsOnCall[i] = struct {
+		result1 core.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserStore) GetUserByID(arg1 context.Context, arg2 uuid.UUID) (core.User, error) {
+	fake.getUserByIDMutex.Lock()
+	ret, specificReturn := fake.getUserByIDReturnsOnCall[len(fake.getUserByIDArgsForCall)]
+	fake.getUserByIDArgsForCall = append(fake.getUserByIDArgsForCall, struct {
+		arg1 context.Context
+		arg2 uuid.UUID
+	}{arg1, arg2})
+	stub := fake.GetUserByIDStub
+	fakeReturns := fake.getUserByIDReturns
+	fake.recordInvocation("GetUserByID", []interface{}{arg1, arg2})
+	fake.getUserByIDMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeUserStore) GetUserByIDCallCount() int {
+	fake.getUserByIDMutex.RLock()
+	defer fake.getUserByIDMutex.RUnlock()
+	return len(fake.getUserByIDArgsForCall)
+}
+
+func (fake *FakeUserStore) GetUserByIDCalls(stub func(context.Context, uuid.UUID) (core.User, error)) {
+	fake.getUserByIDMutex.Lock()
+	defer fake.getUserByIDMutex.Unlock()
+	fake.GetUserByIDStub = stub
+}
+
+func (fake *FakeUserStore) GetUserByIDArgsForCall(i int) (context.Context, uuid.UUID) {
+	fake.getUserByIDMutex.RLock()
+	defer fake.getUserByIDMutex.RUnlock()
+	argsForCall := fake.getUserByIDArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeUserStore) GetUserByIDReturns(result1 core.User, result2 error) {
+	fake.getUserByIDMutex.Lock()
+	defer fake.getUserByIDMutex.Unlock()
+	fake.GetUserByIDStub = nil
+	fake.getUserByIDReturns = struct {
+		result1 core.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserStore) GetUserByIDReturnsOnCall(i int, result1 core.User, result2 error) {
+	fake.getUserByIDMutex.Lock()
+	defer fake.getUserByIDMutex.Unlock()
+	fake.GetUserByIDStub = nil
+	if fake.getUserByIDReturnsOnCall == nil {
+		fake.getUserByIDReturnsOnCall = make(map[int]struct {
+			result1 core.User
+			result2 error
+		})
+	}
+	fake.getUserByIDReturnsOnCall[i] = struct {
+		result1 core.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserStore) ResetPassword(arg1 context.Context, arg2 core.User, arg3 string, arg4 time.Time, arg5 core.EventBuilder) (core.User, error) {
+	fake.resetPasswordMutex.Lock()
+	ret, specificReturn := fake.resetPasswordReturnsOnCall[len(fake.resetPasswordArgsForCall)]
+	fake.resetPasswordArgsForCall = append(fake.resetPasswordArgsForCall, struct {
+		arg1 context.Context
+		arg2 core.User
+		arg3 string
+		arg4 time.Time
+		arg5 core.EventBuilder
+	}{arg1, arg2, arg3, arg4, arg5})
+	stub := fake.ResetPasswordStub
+	fakeReturns := fake.resetPasswordReturns
+	fake.recordInvocation("ResetPassword", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.resetPasswordMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeUserStore) ResetPasswordCallCount() int {
+	fake.resetPasswordMutex.RLock()
+	defer fake.resetPasswordMutex.RUnlock()
+	return len(fake.resetPasswordArgsForCall)
+}
+
+func (fake *FakeUserStore) ResetPasswordCalls(stub func(context.Context, core.User, string, time.Time, core.EventBuilder) (core.User, error)) {
+	fake.resetPasswordMutex.Lock()
+	defer fake.resetPasswordMutex.Unlock()
+	fake.ResetPasswordStub = stub
+}
+
+func (fake *FakeUserStore) ResetPasswordArgsForCall(i int) (context.Context, core.User, string, time.Time, core.EventBuilder) {
+	fake.resetPasswordMutex.RLock()
+	defer fake.resetPasswordMutex.RUnlock()
+	argsForCall := fake.resetPasswordArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
 }
 
-func (fake *FakeUserStore) SaveUser(arg1 context.Context, arg2 core.User) error {
+func (fake *FakeUserStore) ResetPasswordReturns(result1 core.User, result2 error) {
+	fake.resetPasswordMutex.Lock()
+	defer fake.resetPasswordMutex.Unlock()
+	fake.ResetPasswordStub = nil
+	fake.resetPasswordReturns = struct {
+		result1 core.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserStore) ResetPasswordReturnsOnCall(i int, result1 core.User, result2 error) {
+	fake.resetPasswordMutex.Lock()
+	defer fake.resetPasswordMutex.Unlock()
+	fake.ResetPasswordStub = nil
+	if fake.resetPasswordReturnsOnCall == nil {
+		fake.resetPasswordReturnsOnCall = make(map[int]struct {
+			result1 core.User
+			result2 error
+		})
+	}
+	fake.resetPasswordReturnsOnCall[i] = struct {
+		result1 core.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserStore) SaveEmailVerificationToken(arg1 context.Context, arg2 core.EmailVerificationToken) error {
+	fake.saveEmailVerificationTokenMutex.Lock()
+	ret, specificReturn := fake.saveEmailVerificationTokenReturnsOnCall[len(fake.saveEmailVerificationTokenArgsForCall)]
+	fake.saveEmailVerificationTokenArgsForCall = append(fake.saveEmailVerificationTokenArgsForCall, struct {
+		arg1 context.Context
+		arg2 core.EmailVerificationToken
+	}{arg1, arg2})
+	stub := fake.SaveEmailVerificationTokenStub
+	fakeReturns := fake.saveEmailVerificationTokenReturns
+	fake.recordInvocation("SaveEmailVerificationToken", []interface{}{arg1, arg2})
+	fake.saveEmailVerificationTokenMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeUserStore) SaveEmailVerificationTokenCallCount() int {
+	fake.saveEmailVerificationTokenMutex.RLock()
+	defer fake.saveEmailVerificationTokenMutex.RUnlock()
+	return len(fake.saveEmailVerificationTokenArgsForCall)
+}
+
+func (fake *FakeUserStore) SaveEmailVerificationTokenCalls(stub func(context.Context, core.EmailVerificationToken) error) {
+	fake.saveEmailVerificationTokenMutex.Lock()
+	defer fake.saveEmailVerificationTokenMutex.Unlock()
+	fake.SaveEmailVerificationTokenStub = stub
+}
+
+func (fake *FakeUserStore) SaveEmailVerificationTokenArgsForCall(i int) (context.Context, core.EmailVerificationToken) {
+	fake.saveEmailVerificationTokenMutex.RLock()
+	defer fake.saveEmailVerificationTokenMutex.RUnlock()
+	argsForCall := fake.saveEmailVerificationTokenArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeUserStore) SaveEmailVerificationTokenReturns(result1 error) {
+	fake.saveEmailVerificationTokenMutex.Lock()
+	defer fake.saveEmailVerificationTokenMutex.Unlock()
+	fake.SaveEmailVerificationTokenStub = nil
+	fake.saveEmailVerificationTokenReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeUserStore) SaveEmailVerificationTokenReturnsOnCall(i int, result1 error) {
+	fake.saveEmailVerificationTokenMutex.Lock()
+	defer fake.saveEmailVerificationTokenMutex.Unlock()
+	fake.SaveEmailVerificationTokenStub = nil
+	if fake.saveEmailVerificationTokenReturnsOnCall == nil {
+		fake.saveEmailVerificationTokenReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.saveEmailVerificationTokenReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeUserStore) SaveUser(arg1 context.Context, arg2 core.User, arg3 core.EventBuilder) (core.User, error) {
 	fake.saveUserMutex.Lock()
 	ret, specificReturn := fake.saveUserReturnsOnCall[len(fake.saveUserArgsForCall)]
 	fake.saveUserArgsForCall = append(fake.saveUserArgsForCall, struct {
 		arg1 context.Context
 		arg2 core.User
-	}{arg1, arg2})
+		arg3 core.EventBuilder
+	}{arg1, arg2, arg3})
 	stub := fake.SaveUserStub
 	fakeReturns := fake.saveUserReturns
-	fake.recordInvocation("SaveUser", []interface{}{arg1, arg2})
+	fake.recordInvocation("SaveUser", []interface{}{arg1, arg2, arg3})
 	fake.saveUserMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2)
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
-		return ret.result1
+		return ret.result1, ret.result2
 	}
-	return fakeReturns.result1
+	return fakeReturns.result1, fakeReturns.result2
 }
 
 func (fake *FakeUserStore) SaveUserCallCount() int {
@@ -233,60 +582,65 @@ func (fake *FakeUserStore) SaveUserCallCount() int {
 	return len(fake.saveUserArgsForCall)
 }
 
-func (fake *FakeUserStore) SaveUserCalls(stub func(context.Context, core.User) error) {
+func (fake *FakeUserStore) SaveUserCalls(stub func(context.Context, core.User, core.EventBuilder) (core.User, error)) {
 	fake.saveUserMutex.Lock()
 	defer fake.saveUserMutex.Unlock()
 	fake.SaveUserStub = stub
 }
 
-func (fake *FakeUserStore) SaveUserArgsForCall(i int) (context.Context, core.User) {
+func (fake *FakeUserStore) SaveUserArgsForCall(i int) (context.Context, core.User, core.EventBuilder) {
 	fake.saveUserMutex.RLock()
 	defer fake.saveUserMutex.RUnlock()
 	argsForCall := fake.saveUserArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
-func (fake *FakeUserStore) SaveUserReturns(result1 error) {
+func (fake *FakeUserStore) SaveUserReturns(result1 core.User, result2 error) {
 	fake.saveUserMutex.Lock()
 	defer fake.saveUserMutex.Unlock()
 	fake.SaveUserStub = nil
 	fake.saveUserReturns = struct {
-		result1 error
-	}{result1}
+		result1 core.User
+		result2 error
+	}{result1, result2}
 }
 
-func (fake *FakeUserStore) SaveUserReturnsOnCall(i int, result1 error) {
+func (fake *FakeUserStore) SaveUserReturnsOnCall(i int, result1 core.User, result2 error) {
 	fake.saveUserMutex.Lock()
 	defer fake.saveUserMutex.Unlock()
 	fake.SaveUserStub = nil
 	if fake.saveUserReturnsOnCall == nil {
 		fake.saveUserReturnsOnCall = make(map[int]struct {
-			result1 error
+			result1 core.User
+			result2 error
 		})
 	}
 	fake.saveUserReturnsOnCall[i] = struct {
-		result1 error
-	}{result1}
+		result1 core.User
+		result2 error
+	}{result1, result2}
 }
 
-func (fake *FakeUserStore) UpdateUser(arg1 context.Context, arg2 core.User) error {
+func (fake *FakeUserStore) UpdateUser(arg1 context.Context, arg2 core.User, arg3 time.Time, arg4 core.EventBuilder) (core.User, error) {
 	fake.updateUserMutex.Lock()
 	ret, specificReturn := fake.updateUserReturnsOnCall[len(fake.updateUserArgsForCall)]
 	fake.updateUserArgsForCall = append(fake.updateUserArgsForCall, struct {
 		arg1 context.Context
 		arg2 core.User
-	}{arg1, arg2})
+		arg3 time.Time
+		arg4 core.EventBuilder
+	}{arg1, arg2, arg3, arg4})
 	stub := fake.UpdateUserStub
 	fakeReturns := fake.updateUserReturns
-	fake.recordInvocation("UpdateUser", []interface{}{arg1, arg2})
+	fake.recordInvocation("UpdateUser", []interface{}{arg1, arg2, arg3, arg4})
 	fake.updateUserMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2)
+		return stub(arg1, arg2, arg3, arg4)
 	}
 	if specificReturn {
-		return ret.result1
+		return ret.result1, ret.result2
 	}
-	return fakeReturns.result1
+	return fakeReturns.result1, fakeReturns.result2
 }
 
 func (fake *FakeUserStore) UpdateUserCallCount() int {
@@ -295,40 +649,184 @@ func (fake *FakeUserStore) UpdateUserCallCount() int {
 	return len(fake.updateUserArgsForCall)
 }
 
-func (fake *FakeUserStore) UpdateUserCalls(stub func(context.Context, core.User) error) {
+func (fake *FakeUserStore) UpdateUserCalls(stub func(context.Context, core.User, time.Time, core.EventBuilder) (core.User, error)) {
 	fake.updateUserMutex.Lock()
 	defer fake.updateUserMutex.Unlock()
 	fake.UpdateUserStub = stub
 }
 
-func (fake *FakeUserStore) UpdateUserArgsForCall(i int) (context.Context, core.User) {
+func (fake *FakeUserStore) UpdateUserArgsForCall(i int) (context.Context, core.User, time.Time, core.EventBuilder) {
 	fake.updateUserMutex.RLock()
 	defer fake.updateUserMutex.RUnlock()
 	argsForCall := fake.updateUserArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
 }
 
-func (fake *FakeUserStore) UpdateUserReturns(result1 error) {
+func (fake *FakeUserStore) UpdateUserReturns(result1 core.User, result2 error) {
 	fake.updateUserMutex.Lock()
 	defer fake.updateUserMutex.Unlock()
 	fake.UpdateUserStub = nil
 	fake.updateUserReturns = struct {
-		result1 error
-	}{result1}
+		result1 core.User
+		result2 error
+	}{result1, result2}
 }
 
-func (fake *FakeUserStore) UpdateUserReturnsOnCall(i int, result1 error) {
+func (fake *FakeUserStore) UpdateUserReturnsOnCall(i int, result1 core.User, result2 error) {
 	fake.updateUserMutex.Lock()
 	defer fake.updateUserMutex.Unlock()
 	fake.UpdateUserStub = nil
 	if fake.updateUserReturnsOnCall == nil {
 		fake.updateUserReturnsOnCall = make(map[int]struct {
-			result1 error
+			result1 core.User
+			result2 error
 		})
 	}
 	fake.updateUserReturnsOnCall[i] = struct {
-		result1 error
-	}{result1}
+		result1 core.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserStore) UpdateUserFields(arg1 context.Context, arg2 core.User, arg3 []core.UserField, arg4 time.Time, arg5 core.EventBuilder) (core.User, error) {
+	var arg3Copy []core.UserField
+	if arg3 != nil {
+		arg3Copy = make([]core.UserField, len(arg3))
+		copy(arg3Copy, arg3)
+	}
+	fake.updateUserFieldsMutex.Lock()
+	ret, specificReturn := fake.updateUserFieldsReturnsOnCall[len(fake.updateUserFieldsArgsForCall)]
+	fake.updateUserFieldsArgsForCall = append(fake.updateUserFieldsArgsForCall, struct {
+		arg1 context.Context
+		arg2 core.User
+		arg3 []core.UserField
+		arg4 time.Time
+		arg5 core.EventBuilder
+	}{arg1, arg2, arg3Copy, arg4, arg5})
+	stub := fake.UpdateUserFieldsStub
+	fakeReturns := fake.updateUserFieldsReturns
+	fake.recordInvocation("UpdateUserFields", []interface{}{arg1, arg2, arg3Copy, arg4, arg5})
+	fake.updateUserFieldsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeUserStore) UpdateUserFieldsCallCount() int {
+	fake.updateUserFieldsMutex.RLock()
+	defer fake.updateUserFieldsMutex.RUnlock()
+	return len(fake.updateUserFieldsArgsForCall)
+}
+
+func (fake *FakeUserStore) UpdateUserFieldsCalls(stub func(context.Context, core.User, []core.UserField, time.Time, core.EventBuilder) (core.User, error)) {
+	fake.updateUserFieldsMutex.Lock()
+	defer fake.updateUserFieldsMutex.Unlock()
+	fake.UpdateUserFieldsStub = stub
+}
+
+func (fake *FakeUserStore) UpdateUserFieldsArgsForCall(i int) (context.Context, core.User, []core.UserField, time.Time, core.EventBuilder) {
+	fake.updateUserFieldsMutex.RLock()
+	defer fake.updateUserFieldsMutex.RUnlock()
+	argsForCall := fake.updateUserFieldsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeUserStore) UpdateUserFieldsReturns(result1 core.User, result2 error) {
+	fake.updateUserFieldsMutex.Lock()
+	defer fake.updateUserFieldsMutex.Unlock()
+	fake.UpdateUserFieldsStub = nil
+	fake.updateUserFieldsReturns = struct {
+		result1 core.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserStore) UpdateUserFieldsReturnsOnCall(i int, result1 core.User, result2 error) {
+	fake.updateUserFieldsMutex.Lock()
+	defer fake.updateUserFieldsMutex.Unlock()
+	fake.UpdateUserFieldsStub = nil
+	if fake.updateUserFieldsReturnsOnCall == nil {
+		fake.updateUserFieldsReturnsOnCall = make(map[int]struct {
+			result1 core.User
+			result2 error
+		})
+	}
+	fake.updateUserFieldsReturnsOnCall[i] = struct {
+		result1 core.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserStore) VerifyEmail(arg1 context.Context, arg2 core.User, arg3 string, arg4 time.Time, arg5 core.EventBuilder) (core.User, error) {
+	fake.verifyEmailMutex.Lock()
+	ret, specificReturn := fake.verifyEmailReturnsOnCall[len(fake.verifyEmailArgsForCall)]
+	fake.verifyEmailArgsForCall = append(fake.verifyEmailArgsForCall, struct {
+		arg1 context.Context
+		arg2 core.User
+		arg3 string
+		arg4 time.Time
+		arg5 core.EventBuilder
+	}{arg1, arg2, arg3, arg4, arg5})
+	stub := fake.VerifyEmailStub
+	fakeReturns := fake.verifyEmailReturns
+	fake.recordInvocation("VerifyEmail", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.verifyEmailMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeUserStore) VerifyEmailCallCount() int {
+	fake.verifyEmailMutex.RLock()
+	defer fake.verifyEmailMutex.RUnlock()
+	return len(fake.verifyEmailArgsForCall)
+}
+
+func (fake *FakeUserStore) VerifyEmailCalls(stub func(context.Context, core.User, string, time.Time, core.EventBuilder) (core.User, error)) {
+	fake.verifyEmailMutex.Lock()
+	defer fake.verifyEmailMutex.Unlock()
+	fake.VerifyEmailStub = stub
+}
+
+func (fake *FakeUserStore) VerifyEmailArgsForCall(i int) (context.Context, core.User, string, time.Time, core.EventBuilder) {
+	fake.verifyEmailMutex.RLock()
+	defer fake.verifyEmailMutex.RUnlock()
+	argsForCall := fake.verifyEmailArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeUserStore) VerifyEmailReturns(result1 core.User, result2 error) {
+	fake.verifyEmailMutex.Lock()
+	defer fake.verifyEmailMutex.Unlock()
+	fake.VerifyEmailStub = nil
+	fake.verifyEmailReturns = struct {
+		result1 core.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserStore) VerifyEmailReturnsOnCall(i int, result1 core.User, result2 error) {
+	fake.verifyEmailMutex.Lock()
+	defer fake.verifyEmailMutex.Unlock()
+	fake.VerifyEmailStub = nil
+	if fake.verifyEmailReturnsOnCall == nil {
+		fake.verifyEmailReturnsOnCall = make(map[int]struct {
+			result1 core.User
+			result2 error
+		})
+	}
+	fake.verifyEmailReturnsOnCall[i] = struct {
+		result1 core.User
+		result2 error
+	}{result1, result2}
 }
 
 func (fake *FakeUserStore) Invocations() map[string][][]interface{} {
@@ -338,10 +836,22 @@ func (fake *FakeUserStore) Invocations() map[string][][]interface{} {
 	defer fake.deleteUserMutex.RUnlock()
 	fake.getAllUsersMutex.RLock()
 	defer fake.getAllUsersMutex.RUnlock()
+	fake.getUserByEmailMutex.RLock()
+	defer fake.getUserByEmailMutex.RUnlock()
+	fake.getUserByIDMutex.RLock()
+	defer fake.getUserByIDMutex.RUnlock()
+	fake.resetPasswordMutex.RLock()
+	defer fake.resetPasswordMutex.RUnlock()
+	fake.saveEmailVerificationTokenMutex.RLock()
+	defer fake.saveEmailVerificationTokenMutex.RUnlock()
 	fake.saveUserMutex.RLock()
 	defer fake.saveUserMutex.RUnlock()
 	fake.updateUserMutex.RLock()
 	defer fake.updateUserMutex.RUnlock()
+	fake.updateUserFieldsMutex.RLock()
+	defer fake.updateUserFieldsMutex.RUnlock()
+	fake.verifyEmailMutex.RLock()
+	defer fake.verifyEmailMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -0,0 +1,100 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"github.com/google/uuid"
+)
+
+//go:generate ~/go/bin/counterfeiter  . EmailVerifier
+
+// EmailVerifier - proves that users own their emails
+type EmailVerifier interface {
+	// NewToken - random token for the current email of the user, along with its persisted form which carries only
+	// the hash of the token
+	NewToken(user core.User) (string, core.EmailVerificationToken, error)
+	// SendVerification - mails the token to the user
+	SendVerification(ctx context.Context, user core.User, token string) error
+}
+
+// Mailer - sends mails to users
+type Mailer interface {
+	Send(ctx context.Context, mail core.Mail) error
+}
+
+// DefaultVerificationTTL - how long verification tokens are valid
+const DefaultVerificationTTL = 48 * time.Hour
+
+const verificationTokenSize = 32
+
+var errInvalidVerificationToken = &core.ValidationError{Field: "token", Message: "verification token is invalid or expired"}
+
+// EmailVerification - mails random tokens, which are stored hashed by Manager. Tokens expire, they are bound to the
+// user and its email and they work once.
+type EmailVerification struct {
+	mailer Mailer
+	// page - confirms the email, user_id and token are added to its query. Without it mails carry the token only.
+	page *url.URL
+	ttl  time.Duration
+}
+
+func NewEmailVerification(mailer Mailer, page *url.URL, ttl time.Duration) *EmailVerification {
+	return &EmailVerification{
+		mailer: mailer,
+		page:   page,
+		ttl:    ttl,
+	}
+}
+
+func (v *EmailVerification) NewToken(user core.User) (string, core.EmailVerificationToken, error) {
+	secret := make([]byte, verificationTokenSize)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", core.EmailVerificationToken{}, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(secret)
+	return token, core.EmailVerificationToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Email:     user.Email,
+		TokenHash: hashVerificationToken(token),
+		ExpiresAt: time.Now().Add(v.ttl).UTC(),
+	}, nil
+}
+
+func (v *EmailVerification) SendVerification(ctx context.Context, user core.User, token string) error {
+	body := fmt.Sprintf("Hi %s,\n\nplease confirm that %s is your email", user.FirstName, user.Email)
+	if v.page != nil {
+		body += fmt.Sprintf(" by opening\n\n%s\n", v.link(user, token))
+	} else {
+		body += fmt.Sprintf(" by sending verification token\n\n%s\n", token)
+	}
+	return v.mailer.Send(ctx, core.Mail{
+		To:      user.Email,
+		Subject: "Verify your email",
+		Body:    body,
+	})
+}
+
+func (v *EmailVerification) link(user core.User, token string) string {
+	page := *v.page
+	query := page.Query()
+	query.Set("user_id", user.ID.String())
+	query.Set("token", token)
+	page.RawQuery = query.Encode()
+	return page.String()
+}
+
+// hashVerificationToken - tokens are random, hence a plain digest is enough to keep stolen db rows useless
+func hashVerificationToken(token string) string {
+	digest := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(digest[:])
+}
@@ -0,0 +1,92 @@
+package user_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/mailer"
+	"com.user.com/user/internal/user"
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Email Verification", func() {
+	var (
+		mails        *mailer.Memory
+		page         *url.URL
+		verification *user.EmailVerification
+		verified     core.User
+		ctx          context.Context
+	)
+
+	BeforeEach(func() {
+		mails = mailer.NewMemory()
+		page = nil
+		verified = core.User{ID: uuid.New(), FirstName: "Harry", Email: "harry@faceit.com"}
+		ctx = context.Background()
+	})
+
+	JustBeforeEach(func() {
+		verification = user.NewEmailVerification(mails, page, time.Hour)
+	})
+
+	// mailedToken - token of the last mail, which carries a bare token
+	mailedToken := func() string {
+		sent := mails.Sent()
+		Expect(sent).ToNot(BeEmpty())
+		lines := strings.Split(strings.TrimSpace(sent[len(sent)-1].Body), "\n")
+		return lines[len(lines)-1]
+	}
+
+	It("issues random token for the user and its email, keeping only its hash", func() {
+		token, stored, err := verification.NewToken(verified)
+		Expect(err).To(BeNil())
+		digest := sha256.Sum256([]byte(token))
+		Expect(stored.TokenHash).To(Equal(hex.EncodeToString(digest[:])))
+		Expect(stored.ID).ToNot(Equal(uuid.Nil))
+		Expect(stored.UserID).To(Equal(verified.ID))
+		Expect(stored.Email).To(Equal("harry@faceit.com"))
+		Expect(stored.ExpiresAt).To(BeTemporally("~", time.Now().Add(time.Hour), time.Minute))
+
+		other, _, err := verification.NewToken(verified)
+		Expect(err).To(BeNil())
+		Expect(other).ToNot(Equal(token))
+	})
+
+	It("mails the token to the email of the user", func() {
+		Expect(verification.SendVerification(ctx, verified, "token")).To(Succeed())
+		sent := mails.Sent()
+		Expect(sent).To(HaveLen(1))
+		Expect(sent[0].To).To(Equal("harry@faceit.com"))
+		Expect(mailedToken()).To(Equal("token"))
+	})
+
+	It("fails when the mail cannot be sent", func() {
+		mails.FailWith(errors.New("smtp-error"))
+		Expect(verification.SendVerification(ctx, verified, "token")).ToNot(Succeed())
+	})
+
+	Context("With verification page", func() {
+		BeforeEach(func() {
+			var err error
+			page, err = url.Parse("https://faceit.com/verify-email?lang=en")
+			Expect(err).To(BeNil())
+		})
+		It("mails link to the page carrying the user and its token", func() {
+			Expect(verification.SendVerification(ctx, verified, "token")).To(Succeed())
+			link, err := url.Parse(mailedToken())
+			Expect(err).To(BeNil())
+			Expect(link.Host).To(Equal("faceit.com"))
+			Expect(link.Query().Get("lang")).To(Equal("en"))
+			Expect(link.Query().Get("user_id")).To(Equal(verified.ID.String()))
+			Expect(link.Query().Get("token")).To(Equal("token"))
+		})
+	})
+})
@@ -2,13 +2,12 @@ package userview
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
 	"net/http"
+	"path"
 	"time"
 
 	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/view"
 	"github.com/go-playground/validator"
 	"github.com/sirupsen/logrus"
 )
@@ -19,7 +18,7 @@ type CreateUserEndpoint struct {
 }
 
 type UserCreator interface {
-	CreateUser(ctx context.Context, user core.User) error
+	CreateUser(ctx context.Context, actor core.Actor, user core.User) (core.User, error)
 }
 
 type CreateUserParams struct {
@@ -34,19 +33,19 @@ type CreateUserParams struct {
 func NewCreateUserEndpoint(userCreator UserCreator) *CreateUserEndpoint {
 	return &CreateUserEndpoint{
 		userCreator: userCreator,
-		validator:   validator.New(),
+		validator:   view.NewValidator(),
 	}
 }
 
 func (c *CreateUserEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(time.Millisecond*10000))
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(time.Millisecond*10000))
 	defer cancel()
 
 	logrus.WithContext(ctx).
 		WithField("Endoint", "userview.CreateUserEndpoint").
 		Debug("request started")
 	var createUserParams CreateUserParams
-	if !tryReadingBody(ctx, w, r, &createUserParams, c.validator) {
+	if !view.ReadBody(ctx, w, r, &createUserParams, c.validator) {
 		return
 	}
 
@@ -60,41 +59,17 @@ func (c *CreateUserEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		Country:   createUserParams.Country,
 	}
 
-	err := c.userCreator.CreateUser(ctx, user)
+	createdUser, err := c.userCreator.CreateUser(ctx, core.ActorFromContext(ctx), user)
 	if err != nil {
-		logrus.WithContext(ctx).
-			WithError(err).
-			Error("error while creating user")
-		http.Error(w, fmt.Sprintf("error while creating user: %v", err), http.StatusInternalServerError)
+		view.RespondError(ctx, w, err, "creating user")
 		return
 	}
+
+	w.Header().Set("Location", path.Join(r.URL.Path, createdUser.ID.String()))
+	w.Header().Set("ETag", etag(createdUser))
+	view.RespondJSON(ctx, w, http.StatusCreated, newPublicUser(createdUser))
 	logrus.WithContext(ctx).
 		WithField("Endoint", "userview.CreateUserEndpoint").
 		Debug("request completed")
 
 }
-
-func tryReadingBody(
-	ctx context.Context, w http.ResponseWriter, r *http.Request,
-	into interface{}, validate *validator.Validate,
-) bool {
-	data, err := ioutil.ReadAll(r.Body)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("failed parsing request body: %v", err), http.StatusBadRequest)
-		return false
-	}
-	_ = r.Body.Close()
-
-	err = json.Unmarshal(data, into)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("failed to deserialize request body: %v", err), http.StatusBadRequest)
-		return false
-	}
-
-	err = validate.Struct(into)
-	if err != nil {
-		http.Error(w, "invalid parameters", http.StatusBadRequest)
-		return false
-	}
-	return true
-}
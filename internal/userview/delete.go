@@ -6,17 +6,21 @@ import (
 	"net/http"
 	"time"
 
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/view"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 )
 
+//go:generate ~/go/bin/counterfeiter  . UserRemover
+
 type DeleteUserEndpoint struct {
 	userRemover UserRemover
 }
 
 type UserRemover interface {
-	RemoveUser(ctx context.Context, id uuid.UUID) error
+	RemoveUser(ctx context.Context, actor core.Actor, id uuid.UUID, version time.Time) error
 }
 
 func NewDeleteUserEndpoint(userRemover UserRemover) *DeleteUserEndpoint {
@@ -26,7 +30,7 @@ func NewDeleteUserEndpoint(userRemover UserRemover) *DeleteUserEndpoint {
 }
 
 func (d *DeleteUserEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(time.Millisecond*10000))
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(time.Millisecond*10000))
 	defer cancel()
 	logrus.WithContext(ctx).
 		WithField("Endoint", "userview.DeleteUserEndpoint").
@@ -39,15 +43,16 @@ func (d *DeleteUserEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		logrus.WithContext(ctx).
 			WithError(err).
 			Error("error parsing user's payload")
-		http.Error(w, fmt.Sprintf("invalid user id: %v", userID), http.StatusBadRequest)
+		view.RespondBadRequest(ctx, w, fmt.Sprintf("invalid user id: %v", userID), view.InvalidParam{Name: "userID", Reason: "must be a valid UUID"})
+		return
+	}
+	version, ok := requireVersion(ctx, w, r)
+	if !ok {
 		return
 	}
-	err = d.userRemover.RemoveUser(ctx, id)
+	err = d.userRemover.RemoveUser(ctx, core.ActorFromContext(ctx), id, version)
 	if err != nil {
-		logrus.WithContext(ctx).
-			WithError(err).
-			Error("error while deleting user")
-		http.Error(w, fmt.Sprintf("error while deleting user: %v", err), http.StatusInternalServerError)
+		view.RespondError(ctx, w, err, "deleting user")
 		return
 	}
 	logrus.WithContext(ctx).
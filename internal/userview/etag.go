@@ -0,0 +1,44 @@
+package userview
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/view"
+)
+
+// etag - strong entity tag of the user. It is derived from user's version (updated_at), which
+// changes on every modification.
+func etag(user core.User) string {
+	return fmt.Sprintf(`"%d"`, user.UpdatedAt.UnixNano())
+}
+
+// requireVersion - reads the version the client intends to modify from If-Match header.
+// Responds with 428 when the header is missing and with 400 when it is not an ETag of a user. Whether the version
+// is the current one is up to the store, which fails with core.PreconditionFailedError otherwise.
+// "If-Match: *" yields zero version, which means that any version may be modified.
+func requireVersion(ctx context.Context, w http.ResponseWriter, r *http.Request) (time.Time, bool) {
+	ifMatch := strings.TrimSpace(r.Header.Get("If-Match"))
+	if ifMatch == "" {
+		view.RespondProblem(ctx, w, view.NewProblem(view.ProblemTypePreconditionRequired, http.StatusPreconditionRequired,
+			"If-Match header with user's ETag is required"))
+		return time.Time{}, false
+	}
+	if ifMatch == "*" {
+		return time.Time{}, true
+	}
+	nanos, err := strconv.ParseInt(strings.Trim(ifMatch, `"`), 10, 64)
+	if err != nil || !strings.HasPrefix(ifMatch, `"`) || !strings.HasSuffix(ifMatch, `"`) {
+		view.RespondBadRequest(ctx, w, "malformed If-Match header", view.InvalidParam{
+			Name:   "If-Match",
+			Reason: `must be "*" or an ETag of the user`,
+		})
+		return time.Time{}, false
+	}
+	return time.Unix(0, nanos).UTC(), true
+}
@@ -0,0 +1,110 @@
+package userview_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/userview"
+	"com.user.com/user/internal/userview/userviewfakes"
+	"com.user.com/user/internal/view"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Versions", func() {
+	var (
+		id      uuid.UUID
+		version time.Time
+	)
+
+	BeforeEach(func() {
+		id = uuid.New()
+		version = time.Date(2022, 1, 2, 3, 4, 5, 6, time.UTC)
+	})
+
+	It("tags read users with their version", func() {
+		userFinder := &userviewfakes.FakeUserFinder{}
+		userFinder.GetUserByIDReturns(core.User{ID: id, UpdatedAt: version}, nil)
+		request := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/api/public/v1/users/"+id.String(), nil),
+			map[string]string{"userID": id.String()})
+		recorder := httptest.NewRecorder()
+		userview.NewGetUserEndpoint(userFinder).ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("ETag")).To(Equal(fmt.Sprintf(`"%d"`, version.UnixNano())))
+	})
+
+	Context("If-Match", func() {
+		var userRemover *userviewfakes.FakeUserRemover
+
+		BeforeEach(func() {
+			userRemover = &userviewfakes.FakeUserRemover{}
+		})
+
+		remove := func(ifMatch string) *httptest.ResponseRecorder {
+			request := httptest.NewRequest(http.MethodDelete, "/api/public/v1/users/"+id.String(), nil)
+			request = mux.SetURLVars(request, map[string]string{"userID": id.String()})
+			if ifMatch != "" {
+				request.Header.Set("If-Match", ifMatch)
+			}
+			recorder := httptest.NewRecorder()
+			userview.NewDeleteUserEndpoint(userRemover).ServeHTTP(recorder, request)
+			return recorder
+		}
+		problem := func(recorder *httptest.ResponseRecorder) view.Problem {
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/problem+json"))
+			var body view.Problem
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &body)).To(Succeed())
+			return body
+		}
+
+		It("passes version of the ETag to the manager", func() {
+			recorder := remove(fmt.Sprintf(`"%d"`, version.UnixNano()))
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			_, _, removedID, removedVersion := userRemover.RemoveUserArgsForCall(0)
+			Expect(removedID).To(Equal(id))
+			Expect(removedVersion).To(Equal(version))
+		})
+
+		It("passes zero version for any version", func() {
+			Expect(remove("*").Code).To(Equal(http.StatusOK))
+			_, _, _, removedVersion := userRemover.RemoveUserArgsForCall(0)
+			Expect(removedVersion.IsZero()).To(BeTrue())
+		})
+
+		It("requires the header", func() {
+			recorder := remove("")
+			Expect(recorder.Code).To(Equal(http.StatusPreconditionRequired))
+			Expect(problem(recorder).Type).To(Equal(view.ProblemTypePreconditionRequired))
+			Expect(userRemover.RemoveUserCallCount()).To(Equal(0))
+		})
+
+		table.DescribeTable("rejects malformed ETag",
+			func(ifMatch string) {
+				recorder := remove(ifMatch)
+				Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+				Expect(problem(recorder).InvalidParams).To(ConsistOf(view.InvalidParam{
+					Name:   "If-Match",
+					Reason: `must be "*" or an ETag of the user`,
+				}))
+				Expect(userRemover.RemoveUserCallCount()).To(Equal(0))
+			},
+			table.Entry("unquoted", "1641092645000000006"),
+			table.Entry("not a version", `"abc"`),
+			table.Entry("weak", `W/"1641092645000000006"`),
+		)
+
+		It("fails when the version is stale", func() {
+			userRemover.RemoveUserReturns(&core.PreconditionFailedError{Message: "user has been modified in the meantime"})
+			recorder := remove(fmt.Sprintf(`"%d"`, version.UnixNano()))
+			Expect(recorder.Code).To(Equal(http.StatusPreconditionFailed))
+			Expect(problem(recorder).Type).To(Equal(view.ProblemTypePreconditionFail))
+		})
+	})
+})
@@ -0,0 +1,210 @@
+package userview
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/view"
+	"github.com/google/uuid"
+)
+
+// Filter params of users listing look like name=value or name[operator]=value. Lists are comma separated.
+//
+//	country=BG,DE                  - any of the values
+//	country[ne]=BG,DE              - none of the values
+//	created_at[gte]=2022-01-01     - gt, gte, lt and lte bound timestamps, RFC 3339 or dates
+//	ids=<uuid>,<uuid>              - batch lookup
+const (
+	operatorEq  = "eq"
+	operatorNe  = "ne"
+	operatorGt  = "gt"
+	operatorGte = "gte"
+	operatorLt  = "lt"
+	operatorLte = "lte"
+)
+
+// listingParams - params of users listing, which are not filters
+var listingParams = map[string]bool{
+	"q":             true,
+	"sort":          true,
+	"count":         true,
+	"fields":        true,
+	"limit":         true,
+	"next_page":     true,
+	"previous_page": true,
+}
+
+type stringFilterParam func(filter *core.UserFilter) *core.StringCondition
+
+var stringFilterParams = map[string]stringFilterParam{
+	"country":      func(f *core.UserFilter) *core.StringCondition { return &f.Country },
+	"first_name":   func(f *core.UserFilter) *core.StringCondition { return &f.FirstName },
+	"last_name":    func(f *core.UserFilter) *core.StringCondition { return &f.LastName },
+	"nickname":     func(f *core.UserFilter) *core.StringCondition { return &f.Nickname },
+	"email_domain": func(f *core.UserFilter) *core.StringCondition { return &f.EmailDomain },
+}
+
+type timeFilterParam func(filter *core.UserFilter) *core.TimeRange
+
+var timeFilterParams = map[string]timeFilterParam{
+	"created_at": func(f *core.UserFilter) *core.TimeRange { return &f.CreatedAt },
+	"updated_at": func(f *core.UserFilter) *core.TimeRange { return &f.UpdatedAt },
+}
+
+// parseUserFilter - builds the filter out of query params. Every unknown or malformed param is reported.
+func parseUserFilter(query url.Values) (core.UserFilter, []view.InvalidParam) {
+	var (
+		filter        core.UserFilter
+		invalidParams []view.InvalidParam
+	)
+	invalid := func(param, reason string, args ...interface{}) {
+		invalidParams = append(invalidParams, view.InvalidParam{Name: param, Reason: fmt.Sprintf(reason, args...)})
+	}
+
+	// Params are visited in order, so the invalid ones are reported in a stable order
+	params := make([]string, 0, len(query))
+	for param := range query {
+		params = append(params, param)
+	}
+	sort.Strings(params)
+
+	for _, param := range params {
+		if listingParams[param] {
+			continue
+		}
+		name, operator, err := splitFilterParam(param)
+		if err != nil {
+			invalid(param, err.Error())
+			continue
+		}
+		values := splitValues(query[param])
+		if len(values) == 0 {
+			invalid(param, "must not be empty")
+			continue
+		}
+
+		if condition, ok := stringFilterParams[name]; ok {
+			switch operator {
+			case operatorEq:
+				condition(&filter).In = append(condition(&filter).In, values...)
+			case operatorNe:
+				condition(&filter).NotIn = append(condition(&filter).NotIn, values...)
+			default:
+				invalid(param, "supports only eq and ne operators")
+			}
+			continue
+		}
+		if timeRange, ok := timeFilterParams[name]; ok {
+			if len(values) > 1 {
+				invalid(param, "must be a single timestamp")
+				continue
+			}
+			t, err := parseFilterTime(values[0])
+			if err != nil {
+				invalid(param, err.Error())
+				continue
+			}
+			switch operator {
+			case operatorGt:
+				timeRange(&filter).After = t
+			case operatorGte:
+				timeRange(&filter).From = t
+			case operatorLt:
+				timeRange(&filter).Before = t
+			case operatorLte:
+				timeRange(&filter).Until = t
+			default:
+				invalid(param, "requires one of gt, gte, lt or lte operators")
+			}
+			continue
+		}
+		if name == "ids" {
+			if operator != operatorEq {
+				invalid(param, "supports only eq operator")
+				continue
+			}
+			for _, value := range values {
+				id, err := uuid.Parse(value)
+				if err != nil {
+					invalid(param, "must be a list of valid UUIDs, got %q", value)
+					break
+				}
+				filter.IDs = append(filter.IDs, id)
+			}
+			continue
+		}
+		invalid(param, "unknown filter")
+	}
+	return filter, invalidParams
+}
+
+// parseUserSort - comma separated properties users are sorted by, descending when prefixed by '-'. Every property
+// is allowed once.
+func parseUserSort(raw string) ([]core.UserSort, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var sortBy []core.UserSort
+	seen := make(map[core.UserSortField]bool)
+	for _, term := range strings.Split(raw, ",") {
+		term = strings.TrimSpace(term)
+		by := core.UserSort{Field: core.UserSortField(strings.TrimPrefix(term, "-")), Descending: strings.HasPrefix(term, "-")}
+		if !by.Field.Valid() {
+			return nil, fmt.Errorf("users cannot be sorted by %q", by.Field)
+		}
+		// Later occurrences would never take effect, e.g. name,-name
+		if seen[by.Field] {
+			return nil, fmt.Errorf("users are sorted by %q more than once", by.Field)
+		}
+		seen[by.Field] = true
+		sortBy = append(sortBy, by)
+	}
+	return sortBy, nil
+}
+
+// splitFilterParam - splits name[operator] param, plain name means eq operator
+func splitFilterParam(param string) (name, operator string, err error) {
+	open := strings.Index(param, "[")
+	if open < 0 {
+		return param, operatorEq, nil
+	}
+	if !strings.HasSuffix(param, "]") || open == 0 {
+		return "", "", fmt.Errorf("must look like name[operator]")
+	}
+	operator = param[open+1 : len(param)-1]
+	switch operator {
+	case operatorEq, operatorNe, operatorGt, operatorGte, operatorLt, operatorLte:
+		return param[:open], operator, nil
+	default:
+		return "", "", fmt.Errorf("unknown operator %q", operator)
+	}
+}
+
+// splitValues - comma separated values of all occurrences of the param, blank ones are dropped
+func splitValues(rawValues []string) []string {
+	var values []string
+	for _, raw := range rawValues {
+		for _, value := range strings.Split(raw, ",") {
+			value = strings.TrimSpace(value)
+			if value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// parseFilterTime - RFC 3339 timestamp or a date, which stands for its midnight in UTC
+func parseFilterTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("must be an RFC 3339 timestamp or a date, got %q", value)
+}
@@ -0,0 +1,153 @@
+package userview_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/userview"
+	"com.user.com/user/internal/userview/userviewfakes"
+	"com.user.com/user/internal/view"
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Users listing params", func() {
+	var (
+		userGetter *userviewfakes.FakeUserGetter
+		endpoint   *userview.GetAllUsersEndpoint
+	)
+
+	BeforeEach(func() {
+		userGetter = &userviewfakes.FakeUserGetter{}
+		endpoint = userview.NewGetAllUsersEndpoint(userGetter)
+	})
+
+	list := func(query string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		endpoint.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/public/v1/users?"+query, nil))
+		return recorder
+	}
+	listedFilter := func(query string) core.UserFilter {
+		recorder := list(query)
+		Expect(recorder.Code).To(Equal(http.StatusOK), recorder.Body.String())
+		Expect(userGetter.GetAllUsersCallCount()).To(Equal(1))
+		_, _, filter := userGetter.GetAllUsersArgsForCall(0)
+		return filter
+	}
+	invalidParams := func(query string) []view.InvalidParam {
+		recorder := list(query)
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("application/problem+json"))
+		Expect(userGetter.GetAllUsersCallCount()).To(Equal(0))
+		var problem view.Problem
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &problem)).To(Succeed())
+		return problem.InvalidParams
+	}
+
+	Context("Filters", func() {
+		table.DescribeTable("accepted",
+			func(query string, expected core.UserFilter) {
+				Expect(listedFilter(query)).To(Equal(expected))
+			},
+			table.Entry("plain name means any of the values", "country=BG,DE",
+				core.UserFilter{Country: core.StringCondition{In: []string{"BG", "DE"}}}),
+			table.Entry("eq operator", "nickname[eq]=harry",
+				core.UserFilter{Nickname: core.StringCondition{In: []string{"harry"}}}),
+			table.Entry("ne operator", "country[ne]=BG&country[ne]=DE",
+				core.UserFilter{Country: core.StringCondition{NotIn: []string{"BG", "DE"}}}),
+			table.Entry("both operators on the same field", "first_name=Harry&first_name[ne]=Ron",
+				core.UserFilter{FirstName: core.StringCondition{In: []string{"Harry"}, NotIn: []string{"Ron"}}}),
+			table.Entry("blank values are dropped", "email_domain=gmail.com,,%20",
+				core.UserFilter{EmailDomain: core.StringCondition{In: []string{"gmail.com"}}}),
+			table.Entry("gte and lt timestamps", "created_at[gte]=2022-01-01&created_at[lt]=2022-02-01T10:00:00Z",
+				core.UserFilter{CreatedAt: core.TimeRange{
+					From:   time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+					Before: time.Date(2022, 2, 1, 10, 0, 0, 0, time.UTC),
+				}}),
+			table.Entry("gt and lte timestamps", "updated_at[gt]=2022-01-01&updated_at[lte]=2022-01-02",
+				core.UserFilter{UpdatedAt: core.TimeRange{
+					After: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+					Until: time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC),
+				}}),
+			table.Entry("batch lookup", "ids=00000000-0000-0000-0000-000000000001,00000000-0000-0000-0000-000000000002",
+				core.UserFilter{IDs: []uuid.UUID{
+					uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+					uuid.MustParse("00000000-0000-0000-0000-000000000002"),
+				}}),
+			table.Entry("listing params are not filters", "limit=10&fields=id&count=none&q=harry",
+				core.UserFilter{Limit: 10, Count: core.CountNone, Query: "harry"}),
+		)
+
+		table.DescribeTable("rejected",
+			func(query string, expected ...view.InvalidParam) {
+				Expect(invalidParams(query)).To(Equal(expected))
+			},
+			table.Entry("unknown filter", "favourite_color=red",
+				view.InvalidParam{Name: "favourite_color", Reason: "unknown filter"}),
+			table.Entry("unknown operator", "country[like]=B",
+				view.InvalidParam{Name: "country[like]", Reason: `unknown operator "like"`}),
+			table.Entry("malformed operator", "country[ne=BG",
+				view.InvalidParam{Name: "country[ne", Reason: "must look like name[operator]"}),
+			table.Entry("range operator on string field", "country[gt]=BG",
+				view.InvalidParam{Name: "country[gt]", Reason: "supports only eq and ne operators"}),
+			table.Entry("string operator on time field", "created_at=2022-01-01",
+				view.InvalidParam{Name: "created_at", Reason: "requires one of gt, gte, lt or lte operators"}),
+			table.Entry("empty values", "country=,",
+				view.InvalidParam{Name: "country", Reason: "must not be empty"}),
+			table.Entry("bad timestamp", "created_at[gte]=yesterday",
+				view.InvalidParam{Name: "created_at[gte]", Reason: `must be an RFC 3339 timestamp or a date, got "yesterday"`}),
+			table.Entry("several timestamps", "created_at[gte]=2022-01-01,2022-01-02",
+				view.InvalidParam{Name: "created_at[gte]", Reason: "must be a single timestamp"}),
+			table.Entry("malformed id", "ids=not-a-uuid",
+				view.InvalidParam{Name: "ids", Reason: `must be a list of valid UUIDs, got "not-a-uuid"`}),
+			table.Entry("every invalid param in order of names", "zodiac=leo&country[like]=B",
+				view.InvalidParam{Name: "country[like]", Reason: `unknown operator "like"`},
+				view.InvalidParam{Name: "zodiac", Reason: "unknown filter"}),
+		)
+	})
+
+	Context("Sort", func() {
+		table.DescribeTable("accepted",
+			func(query string, expected ...core.UserSort) {
+				Expect(listedFilter(query).Sort).To(Equal(expected))
+			},
+			table.Entry("ascending by default", "sort=last_name",
+				core.UserSort{Field: core.UserSortLastName}),
+			table.Entry("descending when prefixed by '-'", "sort=-created_at,%20nickname",
+				core.UserSort{Field: core.UserSortCreatedAt, Descending: true},
+				core.UserSort{Field: core.UserSortNickname}),
+		)
+
+		table.DescribeTable("rejected",
+			func(query string, reason string) {
+				Expect(invalidParams(query)).To(ConsistOf(view.InvalidParam{Name: "sort", Reason: reason}))
+			},
+			table.Entry("unknown field", "sort=password", `users cannot be sorted by "password"`),
+			table.Entry("empty term", "sort=email,", `users cannot be sorted by ""`),
+			table.Entry("duplicate field", "sort=email,-email", `users are sorted by "email" more than once`),
+		)
+	})
+
+	Context("Limit", func() {
+		It("is left to the manager unless given", func() {
+			Expect(listedFilter("").Limit).To(Equal(0))
+		})
+		It("is passed as given", func() {
+			Expect(listedFilter("limit=50").Limit).To(Equal(50))
+		})
+
+		table.DescribeTable("rejected",
+			func(query string, reason string) {
+				Expect(invalidParams(query)).To(ConsistOf(view.InvalidParam{Name: "limit", Reason: reason}))
+			},
+			table.Entry("zero", "limit=0", `must be a positive integer, got "0"`),
+			table.Entry("negative", "limit=-1", `must be a positive integer, got "-1"`),
+			table.Entry("not a number", "limit=ten", `must be a positive integer, got "ten"`),
+		)
+	})
+})
@@ -0,0 +1,63 @@
+package userview
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/view"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+//go:generate ~/go/bin/counterfeiter  . UserFinder
+
+type GetUserEndpoint struct {
+	userFinder UserFinder
+}
+
+type UserFinder interface {
+	GetUserByID(ctx context.Context, actor core.Actor, id uuid.UUID) (core.User, error)
+}
+
+func NewGetUserEndpoint(userFinder UserFinder) *GetUserEndpoint {
+	return &GetUserEndpoint{
+		userFinder: userFinder,
+	}
+}
+
+func (g *GetUserEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(time.Millisecond*10000))
+	defer cancel()
+	logrus.WithContext(ctx).
+		WithField("Endoint", "userview.GetUserEndpoint").
+		Debug("request started")
+
+	params := mux.Vars(r)
+	userID := params["userID"]
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		view.RespondBadRequest(ctx, w, fmt.Sprintf("invalid user id: %v", userID), view.InvalidParam{Name: "userID", Reason: "must be a valid UUID"})
+		return
+	}
+	fields, err := parseFieldSelection(r.URL.Query().Get("fields"))
+	if err != nil {
+		view.RespondInvalidParam(ctx, w, "fields", err)
+		return
+	}
+
+	user, err := g.userFinder.GetUserByID(ctx, core.ActorFromContext(ctx), id)
+	if err != nil {
+		view.RespondError(ctx, w, err, "getting user")
+		return
+	}
+
+	w.Header().Set("ETag", etag(user))
+	view.RespondJSON(ctx, w, http.StatusOK, fields.apply(newPublicUser(user)))
+	logrus.WithContext(ctx).
+		WithField("Endoint", "userview.GetUserEndpoint").
+		Debug("request completed")
+}
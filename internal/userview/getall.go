@@ -2,126 +2,105 @@ package userview
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
 	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/view"
 	"github.com/go-playground/validator"
-	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
+//go:generate ~/go/bin/counterfeiter  . UserGetter
+
 type GetAllUsersEndpoint struct {
 	userGetter UserGetter
 	validator  *validator.Validate
 }
 
-type UserResponse struct {
-	ID        uuid.UUID `json:"id"`
-	FirstName string    `json:"first_name"`
-	LastName  string    `json:"last_name"`
-	Nickname  string    `json:"nickname"`
-	Password  string    `json:"password"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-}
-
 type GetAllUsersResponse struct {
-	Users        []UserResponse `json:"users"`
-	PreviousPage string         `json:"previous_page,omitempty"`
-	NextPage     string         `json:"next_page,omitempty"`
-	Total        int            `json:"total"`
+	// Users - either PublicUser or its subset, shaped by 'fields' query param
+	Users        []interface{} `json:"users"`
+	PreviousPage string        `json:"previous_page,omitempty"`
+	NextPage     string        `json:"next_page,omitempty"`
+	HasMore      bool          `json:"has_more"`
+	// Total - number of users matching the filter, missing for count=none
+	Total          *int `json:"total,omitempty"`
+	TotalEstimated bool `json:"total_estimated,omitempty"`
 }
 
 type UserGetter interface {
-	GetAllUsers(ctx context.Context, filter core.UserFilter) (users []*core.User, previousPage, nextPage string, total int, err error)
+	GetAllUsers(ctx context.Context, actor core.Actor, filter core.UserFilter) (core.UserPage, error)
 }
 
 func NewGetAllUsersEndpoint(userGetter UserGetter) *GetAllUsersEndpoint {
 	return &GetAllUsersEndpoint{
 		userGetter: userGetter,
-		validator:  validator.New(),
+		validator:  view.NewValidator(),
 	}
 }
 
 func (c *GetAllUsersEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(time.Millisecond*10000))
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(time.Millisecond*10000))
 	defer cancel()
 	logrus.WithContext(ctx).
 		WithField("Endoint", "userview.GetAllUsersEndpoint").
 		Debug("request started")
 
-	previousPage := r.URL.Query().Get("previous_page")
-	nextPage := r.URL.Query().Get("next_page")
-	nickname := r.URL.Query().Get("nickname")
-	country := r.URL.Query().Get("country")
-	firstName := r.URL.Query().Get("first_name")
-	lastName := r.URL.Query().Get("last_name")
-	filter := core.UserFilter{
-		NextPage:     nextPage,
-		PreviousPage: previousPage,
-		Nickname:     nickname,
-		Country:      country,
-		FirstName:    firstName,
-		LastName:     lastName,
+	filter, invalidParams := parseUserFilter(r.URL.Query())
+	if len(invalidParams) > 0 {
+		view.RespondBadRequest(ctx, w, "invalid filter", invalidParams...)
+		return
+	}
+	filter.PreviousPage = r.URL.Query().Get("previous_page")
+	filter.NextPage = r.URL.Query().Get("next_page")
+	filter.Query = r.URL.Query().Get("q")
+	sortBy, err := parseUserSort(r.URL.Query().Get("sort"))
+	if err != nil {
+		view.RespondInvalidParam(ctx, w, "sort", err)
+		return
+	}
+	filter.Sort = sortBy
+	filter.Count = core.CountMode(r.URL.Query().Get("count"))
+	fields, err := parseFieldSelection(r.URL.Query().Get("fields"))
+	if err != nil {
+		view.RespondInvalidParam(ctx, w, "fields", err)
+		return
 	}
 	limit := r.URL.Query().Get("limit")
 	if limit != "" {
 		l, err := strconv.Atoi(limit)
-		if err != nil {
-			http.Error(w, fmt.Sprintf("invalid 'limit' query param: %v", limit), http.StatusBadRequest)
+		if err != nil || l < 1 {
+			view.RespondInvalidParam(ctx, w, "limit", fmt.Errorf("must be a positive integer, got %q", limit))
 			return
 		}
 		filter.Limit = l
 	}
 
-	users, previousPage, nextPage, total, err := c.userGetter.GetAllUsers(ctx, filter)
+	page, err := c.userGetter.GetAllUsers(ctx, core.ActorFromContext(ctx), filter)
 	if err != nil {
-		logrus.WithContext(ctx).
-			WithError(err).
-			Error("error while getting users")
-		http.Error(w, fmt.Sprintf("failed to get users: %v", err), http.StatusInternalServerError)
+		view.RespondError(ctx, w, err, "getting users")
 		return
 	}
-	sliceOfUsers := make([]UserResponse, 0, len(users))
-	for u := range users {
-		sliceOfUsers = append(sliceOfUsers, UserResponse{
-			ID:        users[u].ID,
-			FirstName: users[u].FirstName,
-			LastName:  users[u].LastName,
-			Nickname:  users[u].Nickname,
-			Email:     users[u].Email,
-			Password:  users[u].Password,
-			CreatedAt: users[u].CreatedAt,
-			UpdatedAt: users[u].UpdatedAt,
-		})
+	sliceOfUsers := make([]interface{}, 0, len(page.Users))
+	for u := range page.Users {
+		sliceOfUsers = append(sliceOfUsers, fields.apply(newPublicUser(*page.Users[u])))
 	}
 
 	response := GetAllUsersResponse{
-		Users:        sliceOfUsers,
-		PreviousPage: previousPage,
-		NextPage:     nextPage,
-		Total:        total,
+		Users:          sliceOfUsers,
+		PreviousPage:   page.PreviousPage,
+		NextPage:       page.NextPage,
+		HasMore:        page.HasMore,
+		Total:          page.Total,
+		TotalEstimated: page.TotalEstimated,
 	}
 
-	respondJSON(ctx, w, &response)
+	view.RespondJSON(ctx, w, http.StatusOK, &response)
 	logrus.WithContext(ctx).
 		WithField("Endoint", "userview.GetAllUsersEndpoint").
 		Debug("request completed")
 
 }
-
-func respondJSON(ctx context.Context, w http.ResponseWriter, resp interface{}) {
-	jsonBody, err := json.Marshal(&resp)
-	if err != nil {
-		logrus.WithContext(ctx).WithError(err).Error("failed serializing response")
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-	w.Header().Set("Content-Type", "application/json")
-	_, _ = w.Write(jsonBody)
-}
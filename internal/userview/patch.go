@@ -0,0 +1,171 @@
+package userview
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/view"
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	mergePatchContentType = "application/merge-patch+json"
+	jsonPatchContentType  = "application/json-patch+json"
+)
+
+//go:generate ~/go/bin/counterfeiter  . UserPatcher
+
+type PatchUserEndpoint struct {
+	userPatcher UserPatcher
+}
+
+type UserPatcher interface {
+	PatchUser(ctx context.Context, actor core.Actor, id uuid.UUID, version time.Time, patch core.UserPatch) (core.User, error)
+}
+
+// PatchableUser - document which patches are applied to. Password is never exposed, hence it is only
+// present in the document if the patch sets it.
+type PatchableUser struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Nickname  string `json:"nickname"`
+	Password  string `json:"password,omitempty"`
+	Email     string `json:"email"`
+	Country   string `json:"country"`
+}
+
+func NewPatchUserEndpoint(userPatcher UserPatcher) *PatchUserEndpoint {
+	return &PatchUserEndpoint{
+		userPatcher: userPatcher,
+	}
+}
+
+func (p *PatchUserEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(time.Millisecond*10000))
+	defer cancel()
+	logrus.WithContext(ctx).
+		WithField("Endoint", "userview.PatchUserEndpoint").
+		Debug("request started")
+
+	params := mux.Vars(r)
+	userID := params["userID"]
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		view.RespondBadRequest(ctx, w, fmt.Sprintf("invalid user id: %v", userID), view.InvalidParam{Name: "userID", Reason: "must be a valid UUID"})
+		return
+	}
+
+	version, ok := requireVersion(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		view.RespondBadRequest(ctx, w, fmt.Sprintf("failed parsing request body: %v", err))
+		return
+	}
+	_ = r.Body.Close()
+
+	contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	var patch core.UserPatch
+	switch contentType {
+	case mergePatchContentType:
+		patch = mergePatch(body)
+	case jsonPatchContentType:
+		decoded, err := jsonpatch.DecodePatch(body)
+		if err != nil {
+			view.RespondBadRequest(ctx, w, fmt.Sprintf("failed to deserialize JSON patch: %v", err))
+			return
+		}
+		patch = jsonPatch{patch: decoded}
+	default:
+		w.Header().Set("Accept-Patch", mergePatchContentType+", "+jsonPatchContentType)
+		view.RespondProblem(ctx, w, view.NewProblem(view.ProblemTypeInvalidRequest, http.StatusUnsupportedMediaType,
+			fmt.Sprintf("unsupported patch format: %q", contentType)))
+		return
+	}
+
+	patchedUser, err := p.userPatcher.PatchUser(ctx, core.ActorFromContext(ctx), id, version, patch)
+	if err != nil {
+		view.RespondError(ctx, w, err, "patching user")
+		return
+	}
+
+	w.Header().Set("ETag", etag(patchedUser))
+	view.RespondJSON(ctx, w, http.StatusOK, newPublicUser(patchedUser))
+	logrus.WithContext(ctx).
+		WithField("Endoint", "userview.PatchUserEndpoint").
+		Debug("request completed")
+}
+
+// mergePatch - RFC 7396 JSON merge patch
+type mergePatch []byte
+
+func (m mergePatch) Apply(u core.User) (core.User, error) {
+	return applyOnPatchableUser(u, func(doc []byte) ([]byte, error) {
+		return jsonpatch.MergePatch(doc, m)
+	})
+}
+
+// jsonPatch - RFC 6902 JSON patch
+type jsonPatch struct {
+	patch jsonpatch.Patch
+}
+
+func (j jsonPatch) Apply(u core.User) (core.User, error) {
+	return applyOnPatchableUser(u, j.patch.Apply)
+}
+
+// applyOnPatchableUser - translates the user into PatchableUser document, patches the document and
+// translates it back. Failed JSON patch test operation is a conflict, any other patch failure is a validation error.
+func applyOnPatchableUser(u core.User, apply func(doc []byte) ([]byte, error)) (core.User, error) {
+	doc, err := json.Marshal(PatchableUser{
+		FirstName: u.FirstName,
+		LastName:  u.LastName,
+		Nickname:  u.Nickname,
+		Email:     u.Email,
+		Country:   u.Country,
+	})
+	if err != nil {
+		return core.User{}, err
+	}
+
+	patchedDoc, err := apply(doc)
+	if errors.Is(err, jsonpatch.ErrTestFailed) {
+		return core.User{}, &core.ConflictError{Message: "JSON patch test operation has failed", Err: err}
+	}
+	if err != nil {
+		return core.User{}, &core.ValidationError{Message: fmt.Sprintf("failed to apply patch: %v", err)}
+	}
+
+	var patched PatchableUser
+	decoder := json.NewDecoder(bytes.NewReader(patchedDoc))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&patched)
+	if err != nil {
+		return core.User{}, &core.ValidationError{Message: fmt.Sprintf("patched user is invalid: %s", strings.TrimPrefix(err.Error(), "json: "))}
+	}
+
+	u.FirstName = patched.FirstName
+	u.LastName = patched.LastName
+	u.Nickname = patched.Nickname
+	u.Email = patched.Email
+	u.Country = patched.Country
+	if patched.Password != "" {
+		u.Password = patched.Password
+	}
+	return u, nil
+}
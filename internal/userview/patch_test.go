@@ -0,0 +1,150 @@
+package userview_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/userview"
+	"com.user.com/user/internal/userview/userviewfakes"
+	"com.user.com/user/internal/view"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Patch User", func() {
+	var (
+		userPatcher *userviewfakes.FakeUserPatcher
+		current     core.User
+	)
+
+	BeforeEach(func() {
+		current = core.User{
+			ID:        uuid.New(),
+			FirstName: "Harry",
+			LastName:  "Potter",
+			Nickname:  "hp",
+			Password:  "hashed-password",
+			Email:     "harry@faceit.com",
+			Country:   "UK",
+			Role:      core.RoleUser,
+			UpdatedAt: time.Now().UTC(),
+		}
+		userPatcher = &userviewfakes.FakeUserPatcher{}
+		// Patch is applied the way Manager does, on the current user
+		userPatcher.PatchUserStub = func(_ context.Context, _ core.Actor, _ uuid.UUID, _ time.Time, patch core.UserPatch) (core.User, error) {
+			return patch.Apply(current)
+		}
+	})
+
+	patchUser := func(contentType, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPatch, "/api/public/v1/users/"+current.ID.String(), strings.NewReader(body))
+		request = mux.SetURLVars(request, map[string]string{"userID": current.ID.String()})
+		request.Header.Set("If-Match", "*")
+		if contentType != "" {
+			request.Header.Set("Content-Type", contentType)
+		}
+		recorder := httptest.NewRecorder()
+		userview.NewPatchUserEndpoint(userPatcher).ServeHTTP(recorder, request)
+		return recorder
+	}
+	// patched - user the patch has produced, as passed to the store by Manager
+	patched := func(contentType, body string) core.User {
+		recorder := patchUser(contentType, body)
+		Expect(recorder.Code).To(Equal(http.StatusOK), recorder.Body.String())
+		Expect(userPatcher.PatchUserCallCount()).To(Equal(1))
+		_, _, _, _, patch := userPatcher.PatchUserArgsForCall(0)
+		user, err := patch.Apply(current)
+		Expect(err).To(BeNil())
+		return user
+	}
+	problem := func(recorder *httptest.ResponseRecorder) view.Problem {
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("application/problem+json"))
+		var body view.Problem
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &body)).To(Succeed())
+		return body
+	}
+
+	Context("Merge patch", func() {
+		It("changes only fields of the patch", func() {
+			user := patched("application/merge-patch+json", `{"nickname": "chosen-one", "country": "BG"}`)
+			expected := current
+			expected.Nickname = "chosen-one"
+			expected.Country = "BG"
+			Expect(user).To(Equal(expected))
+		})
+		It("replaces the password with the one of the patch", func() {
+			user := patched("application/merge-patch+json; charset=utf-8", `{"password": "new-secret"}`)
+			Expect(user.Password).To(Equal("new-secret"))
+		})
+		It("keeps the password unless the patch sets it", func() {
+			user := patched("application/merge-patch+json", `{"password": null}`)
+			Expect(user.Password).To(Equal("hashed-password"))
+		})
+	})
+
+	Context("JSON patch", func() {
+		It("applies operations in order", func() {
+			user := patched("application/json-patch+json", `[
+				{"op": "test", "path": "/nickname", "value": "hp"},
+				{"op": "replace", "path": "/nickname", "value": "chosen-one"},
+				{"op": "copy", "from": "/nickname", "path": "/first_name"}
+			]`)
+			Expect(user.Nickname).To(Equal("chosen-one"))
+			Expect(user.FirstName).To(Equal("chosen-one"))
+			Expect(user.Email).To(Equal(current.Email))
+		})
+		It("fails with conflict when test operation fails", func() {
+			recorder := patchUser("application/json-patch+json", `[
+				{"op": "test", "path": "/nickname", "value": "ron"},
+				{"op": "replace", "path": "/nickname", "value": "chosen-one"}
+			]`)
+			Expect(recorder.Code).To(Equal(http.StatusConflict))
+			Expect(problem(recorder).Type).To(Equal(view.ProblemTypeConflict))
+		})
+		It("rejects malformed patch before patching", func() {
+			recorder := patchUser("application/json-patch+json", `{"op": "replace"}`)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(userPatcher.PatchUserCallCount()).To(Equal(0))
+		})
+		It("does not expose the stored password to test operations", func() {
+			recorder := patchUser("application/json-patch+json", `[{"op": "test", "path": "/password", "value": "hashed-password"}]`)
+			Expect(recorder.Code).To(Equal(http.StatusConflict))
+		})
+	})
+
+	table.DescribeTable("rejects patches of protected fields",
+		func(contentType, body string) {
+			recorder := patchUser(contentType, body)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(problem(recorder).Type).To(Equal(view.ProblemTypeValidation))
+		},
+		table.Entry("id by merge patch", "application/merge-patch+json", `{"id": "00000000-0000-0000-0000-000000000001"}`),
+		table.Entry("role by merge patch", "application/merge-patch+json", `{"role": "admin"}`),
+		table.Entry("email verification by merge patch", "application/merge-patch+json", `{"email_verified_at": "2022-01-01T00:00:00Z"}`),
+		table.Entry("id by JSON patch", "application/json-patch+json", `[{"op": "add", "path": "/id", "value": "00000000-0000-0000-0000-000000000001"}]`),
+		table.Entry("role by JSON patch", "application/json-patch+json", `[{"op": "add", "path": "/role", "value": "admin"}]`),
+		table.Entry("password removal by JSON patch", "application/json-patch+json", `[{"op": "remove", "path": "/password"}]`),
+		table.Entry("wrong type", "application/merge-patch+json", `{"nickname": 42}`),
+	)
+
+	table.DescribeTable("rejects other content types",
+		func(contentType string) {
+			recorder := patchUser(contentType, `{"nickname": "chosen-one"}`)
+			Expect(recorder.Code).To(Equal(http.StatusUnsupportedMediaType))
+			Expect(recorder.Header().Get("Accept-Patch")).To(Equal("application/merge-patch+json, application/json-patch+json"))
+			Expect(problem(recorder).Status).To(Equal(http.StatusUnsupportedMediaType))
+			Expect(userPatcher.PatchUserCallCount()).To(Equal(0))
+		},
+		table.Entry("plain JSON", "application/json"),
+		table.Entry("form", "application/x-www-form-urlencoded"),
+		table.Entry("missing", ""),
+	)
+})
@@ -0,0 +1,94 @@
+package userview
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"github.com/google/uuid"
+)
+
+// PublicUser - the only representation of a user which leaves the service. It has no place for
+// secrets (password etc.), so every endpoint must translate core.User through newPublicUser.
+type PublicUser struct {
+	ID        uuid.UUID `json:"id"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Nickname  string    `json:"nickname"`
+	Email     string    `json:"email"`
+	Country   string    `json:"country"`
+	Role      core.Role `json:"role"`
+	// EmailVerifiedAt - null while the email is not verified
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+func newPublicUser(user core.User) PublicUser {
+	public := PublicUser{
+		ID:        user.ID,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Nickname:  user.Nickname,
+		Email:     user.Email,
+		Country:   user.Country,
+		Role:      user.Role,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+	if user.EmailVerified() {
+		public.EmailVerifiedAt = &user.EmailVerifiedAt
+	}
+	return public
+}
+
+// publicUserFields - fields which can be requested through 'fields' query param.
+var publicUserFields = map[string]func(u PublicUser) interface{}{
+	"id":                func(u PublicUser) interface{} { return u.ID },
+	"first_name":        func(u PublicUser) interface{} { return u.FirstName },
+	"last_name":         func(u PublicUser) interface{} { return u.LastName },
+	"nickname":          func(u PublicUser) interface{} { return u.Nickname },
+	"email":             func(u PublicUser) interface{} { return u.Email },
+	"country":           func(u PublicUser) interface{} { return u.Country },
+	"role":              func(u PublicUser) interface{} { return u.Role },
+	"email_verified_at": func(u PublicUser) interface{} { return u.EmailVerifiedAt },
+	"created_at":        func(u PublicUser) interface{} { return u.CreatedAt },
+	"updated_at":        func(u PublicUser) interface{} { return u.UpdatedAt },
+}
+
+// fieldSelection - subset of PublicUser fields requested by the client, e.g. fields=id,email.
+// Empty selection means all fields.
+type fieldSelection []string
+
+func parseFieldSelection(raw string) (fieldSelection, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var selection fieldSelection
+	seen := make(map[string]bool)
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if _, ok := publicUserFields[field]; !ok {
+			return nil, fmt.Errorf("unknown field: %q", field)
+		}
+		if seen[field] {
+			continue
+		}
+		seen[field] = true
+		selection = append(selection, field)
+	}
+	return selection, nil
+}
+
+// apply - shapes the user according to the selection.
+func (f fieldSelection) apply(user PublicUser) interface{} {
+	if len(f) == 0 {
+		return user
+	}
+	shaped := make(map[string]interface{}, len(f))
+	for _, field := range f {
+		shaped[field] = publicUserFields[field](user)
+	}
+	return shaped
+}
@@ -0,0 +1,93 @@
+package userview_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/userview"
+	"com.user.com/user/internal/userview/userviewfakes"
+	"com.user.com/user/internal/view"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fields selection", func() {
+	var (
+		userFinder *userviewfakes.FakeUserFinder
+		endpoint   *userview.GetUserEndpoint
+		user       core.User
+	)
+
+	BeforeEach(func() {
+		user = core.User{
+			ID:        uuid.New(),
+			FirstName: "Harry",
+			LastName:  "Potter",
+			Nickname:  "hp",
+			Password:  "hashed-password",
+			Email:     "harry@faceit.com",
+			Country:   "UK",
+			Role:      core.RoleUser,
+			CreatedAt: time.Now().UTC(),
+			UpdatedAt: time.Now().UTC(),
+		}
+		userFinder = &userviewfakes.FakeUserFinder{}
+		userFinder.GetUserByIDReturns(user, nil)
+		endpoint = userview.NewGetUserEndpoint(userFinder)
+	})
+
+	get := func(query string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/api/public/v1/users/"+user.ID.String()+"?"+query, nil)
+		request = mux.SetURLVars(request, map[string]string{"userID": user.ID.String()})
+		recorder := httptest.NewRecorder()
+		endpoint.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	table.DescribeTable("accepted",
+		func(query string, expectedFields ...string) {
+			recorder := get(query)
+			Expect(recorder.Code).To(Equal(http.StatusOK), recorder.Body.String())
+			var body map[string]interface{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &body)).To(Succeed())
+			Expect(body).To(HaveLen(len(expectedFields)))
+			for _, field := range expectedFields {
+				Expect(body).To(HaveKey(field))
+			}
+			Expect(body).ToNot(HaveKey("password"))
+		},
+		table.Entry("all public fields by default", "",
+			"id", "first_name", "last_name", "nickname", "email", "country", "role",
+			"email_verified_at", "created_at", "updated_at"),
+		table.Entry("only selected fields", "fields=id,email", "id", "email"),
+		table.Entry("spaces around fields", "fields=id,%20nickname%20", "id", "nickname"),
+		table.Entry("duplicate fields once", "fields=id,email,id", "id", "email"),
+	)
+
+	It("keeps values of selected fields", func() {
+		recorder := get("fields=nickname,email_verified_at")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"nickname": "hp", "email_verified_at": null}`))
+	})
+
+	table.DescribeTable("rejected",
+		func(query string, reason string) {
+			recorder := get(query)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(userFinder.GetUserByIDCallCount()).To(Equal(0))
+			var problem view.Problem
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &problem)).To(Succeed())
+			Expect(problem.InvalidParams).To(ConsistOf(view.InvalidParam{Name: "fields", Reason: reason}))
+		},
+		table.Entry("unknown field", "fields=id,favourite_color", `unknown field: "favourite_color"`),
+		table.Entry("secret field", "fields=password", `unknown field: "password"`),
+		table.Entry("empty entry", "fields=id,,email", `unknown field: ""`),
+		table.Entry("trailing comma", "fields=id,", `unknown field: ""`),
+	)
+})
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/view"
 	"github.com/go-playground/validator"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
@@ -19,7 +20,7 @@ type UpdateUserEndpoint struct {
 }
 
 type UserModifier interface {
-	ModifyUser(ctx context.Context, user core.User) error
+	ModifyUser(ctx context.Context, actor core.Actor, user core.User, version time.Time) (core.User, error)
 }
 
 type UpdateUserParams struct {
@@ -35,12 +36,12 @@ type UpdateUserParams struct {
 func NewUpdateUserEndpoint(userManager UserModifier) *UpdateUserEndpoint {
 	return &UpdateUserEndpoint{
 		userModifier: userManager,
-		validator:    validator.New(),
+		validator:    view.NewValidator(),
 	}
 }
 
 func (u *UpdateUserEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(time.Millisecond*10000))
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(time.Millisecond*10000))
 	defer cancel()
 	logrus.WithContext(ctx).
 		WithField("Endoint", "userview.GetAllUsersEndpoint").
@@ -50,12 +51,17 @@ func (u *UpdateUserEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	userID := params["userID"]
 	id, err := uuid.Parse(userID)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("invalid user id: %v", userID), http.StatusBadRequest)
+		view.RespondBadRequest(ctx, w, fmt.Sprintf("invalid user id: %v", userID), view.InvalidParam{Name: "userID", Reason: "must be a valid UUID"})
+		return
+	}
+
+	version, ok := requireVersion(ctx, w, r)
+	if !ok {
 		return
 	}
 
 	var updateUserParams UpdateUserParams
-	if !tryReadingBody(ctx, w, r, &updateUserParams, u.validator) {
+	if !view.ReadBody(ctx, w, r, &updateUserParams, u.validator) {
 		return
 	}
 
@@ -70,15 +76,15 @@ func (u *UpdateUserEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		Country:   updateUserParams.Country,
 	}
 
-	err = u.userModifier.ModifyUser(ctx, user)
+	modifiedUser, err := u.userModifier.ModifyUser(ctx, core.ActorFromContext(ctx), user, version)
 	if err != nil {
-		logrus.WithContext(ctx).
-			WithError(err).
-			Error("error while modifying user")
-		http.Error(w, fmt.Sprintf("error while modifying user: %v", err), http.StatusInternalServerError)
+		view.RespondError(ctx, w, err, "modifying user")
 		return
 	}
 
+	w.Header().Set("ETag", etag(modifiedUser))
+	view.RespondJSON(ctx, w, http.StatusOK, newPublicUser(modifiedUser))
+
 	logrus.WithContext(ctx).
 		WithField("Endoint", "userview.GetAllUsersEndpoint").
 		Debug("request started")
@@ -0,0 +1,13 @@
+package userview_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestUserview(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Userview Suite")
+}
@@ -0,0 +1,122 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package userviewfakes
+
+import (
+	"context"
+	"sync"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/userview"
+)
+
+type FakeUserGetter struct {
+	GetAllUsersStub        func(context.Context, core.Actor, core.UserFilter) (core.UserPage, error)
+	getAllUsersMutex       sync.RWMutex
+	getAllUsersArgsForCall []struct {
+		arg1 context.Context
+		arg2 core.Actor
+		arg3 core.UserFilter
+	}
+	getAllUsersReturns struct {
+		result1 core.UserPage
+		result2 error
+	}
+	getAllUsersReturnsOnCall map[int]struct {
+		result1 core.UserPage
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeUserGetter) GetAllUsers(arg1 context.Context, arg2 core.Actor, arg3 core.UserFilter) (core.UserPage, error) {
+	fake.getAllUsersMutex.Lock()
+	ret, specificReturn := fake.getAllUsersReturnsOnCall[len(fake.getAllUsersArgsForCall)]
+	fake.getAllUsersArgsForCall = append(fake.getAllUsersArgsForCall, struct {
+		arg1 context.Context
+		arg2 core.Actor
+		arg3 core.UserFilter
+	}{arg1, arg2, arg3})
+	stub := fake.GetAllUsersStub
+	fakeReturns := fake.getAllUsersReturns
+	fake.recordInvocation("GetAllUsers", []interface{}{arg1, arg2, arg3})
+	fake.getAllUsersMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeUserGetter) GetAllUsersCallCount() int {
+	fake.getAllUsersMutex.RLock()
+	defer fake.getAllUsersMutex.RUnlock()
+	return len(fake.getAllUsersArgsForCall)
+}
+
+func (fake *FakeUserGetter) GetAllUsersCalls(stub func(context.Context, core.Actor, core.UserFilter) (core.UserPage, error)) {
+	fake.getAllUsersMutex.Lock()
+	defer fake.getAllUsersMutex.Unlock()
+	fake.GetAllUsersStub = stub
+}
+
+func (fake *FakeUserGetter) GetAllUsersArgsForCall(i int) (context.Context, core.Actor, core.UserFilter) {
+	fake.getAllUsersMutex.RLock()
+	defer fake.getAllUsersMutex.RUnlock()
+	argsForCall := fake.getAllUsersArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeUserGetter) GetAllUsersReturns(result1 core.UserPage, result2 error) {
+	fake.getAllUsersMutex.Lock()
+	defer fake.getAllUsersMutex.Unlock()
+	fake.GetAllUsersStub = nil
+	fake.getAllUsersReturns = struct {
+		result1 core.UserPage
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserGetter) GetAllUsersReturnsOnCall(i int, result1 core.UserPage, result2 error) {
+	fake.getAllUsersMutex.Lock()
+	defer fake.getAllUsersMutex.Unlock()
+	fake.GetAllUsersStub = nil
+	if fake.getAllUsersReturnsOnCall == nil {
+		fake.getAllUsersReturnsOnCall = make(map[int]struct {
+			result1 core.UserPage
+			result2 error
+		})
+	}
+	fake.getAllUsersReturnsOnCall[i] = struct {
+		result1 core.UserPage
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserGetter) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.getAllUsersMutex.RLock()
+	defer fake.getAllUsersMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeUserGetter) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ userview.UserGetter = new(FakeUserGetter)
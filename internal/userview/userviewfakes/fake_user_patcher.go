@@ -0,0 +1,128 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package userviewfakes
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/userview"
+	"github.com/google/uuid"
+)
+
+type FakeUserPatcher struct {
+	PatchUserStub        func(context.Context, core.Actor, uuid.UUID, time.Time, core.UserPatch) (core.User, error)
+	patchUserMutex       sync.RWMutex
+	patchUserArgsForCall []struct {
+		arg1 context.Context
+		arg2 core.Actor
+		arg3 uuid.UUID
+		arg4 time.Time
+		arg5 core.UserPatch
+	}
+	patchUserReturns struct {
+		result1 core.User
+		result2 error
+	}
+	patchUserReturnsOnCall map[int]struct {
+		result1 core.User
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeUserPatcher) PatchUser(arg1 context.Context, arg2 core.Actor, arg3 uuid.UUID, arg4 time.Time, arg5 core.UserPatch) (core.User, error) {
+	fake.patchUserMutex.Lock()
+	ret, specificReturn := fake.patchUserReturnsOnCall[len(fake.patchUserArgsForCall)]
+	fake.patchUserArgsForCall = append(fake.patchUserArgsForCall, struct {
+		arg1 context.Context
+		arg2 core.Actor
+		arg3 uuid.UUID
+		arg4 time.Time
+		arg5 core.UserPatch
+	}{arg1, arg2, arg3, arg4, arg5})
+	stub := fake.PatchUserStub
+	fakeReturns := fake.patchUserReturns
+	fake.recordInvocation("PatchUser", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.patchUserMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeUserPatcher) PatchUserCallCount() int {
+	fake.patchUserMutex.RLock()
+	defer fake.patchUserMutex.RUnlock()
+	return len(fake.patchUserArgsForCall)
+}
+
+func (fake *FakeUserPatcher) PatchUserCalls(stub func(context.Context, core.Actor, uuid.UUID, time.Time, core.UserPatch) (core.User, error)) {
+	fake.patchUserMutex.Lock()
+	defer fake.patchUserMutex.Unlock()
+	fake.PatchUserStub = stub
+}
+
+func (fake *FakeUserPatcher) PatchUserArgsForCall(i int) (context.Context, core.Actor, uuid.UUID, time.Time, core.UserPatch) {
+	fake.patchUserMutex.RLock()
+	defer fake.patchUserMutex.RUnlock()
+	argsForCall := fake.patchUserArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeUserPatcher) PatchUserReturns(result1 core.User, result2 error) {
+	fake.patchUserMutex.Lock()
+	defer fake.patchUserMutex.Unlock()
+	fake.PatchUserStub = nil
+	fake.patchUserReturns = struct {
+		result1 core.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserPatcher) PatchUserReturnsOnCall(i int, result1 core.User, result2 error) {
+	fake.patchUserMutex.Lock()
+	defer fake.patchUserMutex.Unlock()
+	fake.PatchUserStub = nil
+	if fake.patchUserReturnsOnCall == nil {
+		fake.patchUserReturnsOnCall = make(map[int]struct {
+			result1 core.User
+			result2 error
+		})
+	}
+	fake.patchUserReturnsOnCall[i] = struct {
+		result1 core.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserPatcher) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.patchUserMutex.RLock()
+	defer fake.patchUserMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeUserPatcher) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ userview.UserPatcher = new(FakeUserPatcher)
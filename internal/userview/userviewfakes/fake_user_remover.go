@@ -0,0 +1,121 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package userviewfakes
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/userview"
+	"github.com/google/uuid"
+)
+
+type FakeUserRemover struct {
+	RemoveUserStub        func(context.Context, core.Actor, uuid.UUID, time.Time) error
+	removeUserMutex       sync.RWMutex
+	removeUserArgsForCall []struct {
+		arg1 context.Context
+		arg2 core.Actor
+		arg3 uuid.UUID
+		arg4 time.Time
+	}
+	removeUserReturns struct {
+		result1 error
+	}
+	removeUserReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeUserRemover) RemoveUser(arg1 context.Context, arg2 core.Actor, arg3 uuid.UUID, arg4 time.Time) error {
+	fake.removeUserMutex.Lock()
+	ret, specificReturn := fake.removeUserReturnsOnCall[len(fake.removeUserArgsForCall)]
+	fake.removeUserArgsForCall = append(fake.removeUserArgsForCall, struct {
+		arg1 context.Context
+		arg2 core.Actor
+		arg3 uuid.UUID
+		arg4 time.Time
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.RemoveUserStub
+	fakeReturns := fake.removeUserReturns
+	fake.recordInvocation("RemoveUser", []interface{}{arg1, arg2, arg3, arg4})
+	fake.removeUserMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeUserRemover) RemoveUserCallCount() int {
+	fake.removeUserMutex.RLock()
+	defer fake.removeUserMutex.RUnlock()
+	return len(fake.removeUserArgsForCall)
+}
+
+func (fake *FakeUserRemover) RemoveUserCalls(stub func(context.Context, core.Actor, uuid.UUID, time.Time) error) {
+	fake.removeUserMutex.Lock()
+	defer fake.removeUserMutex.Unlock()
+	fake.RemoveUserStub = stub
+}
+
+func (fake *FakeUserRemover) RemoveUserArgsForCall(i int) (context.Context, core.Actor, uuid.UUID, time.Time) {
+	fake.removeUserMutex.RLock()
+	defer fake.removeUserMutex.RUnlock()
+	argsForCall := fake.removeUserArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeUserRemover) RemoveUserReturns(result1 error) {
+	fake.removeUserMutex.Lock()
+	defer fake.removeUserMutex.Unlock()
+	fake.RemoveUserStub = nil
+	fake.removeUserReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeUserRemover) RemoveUserReturnsOnCall(i int, result1 error) {
+	fake.removeUserMutex.Lock()
+	defer fake.removeUserMutex.Unlock()
+	fake.RemoveUserStub = nil
+	if fake.removeUserReturnsOnCall == nil {
+		fake.removeUserReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.removeUserReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeUserRemover) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.removeUserMutex.RLock()
+	defer fake.removeUserMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeUserRemover) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ userview.UserRemover = new(FakeUserRemover)
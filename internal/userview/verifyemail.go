@@ -0,0 +1,67 @@
+package userview
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/view"
+	"github.com/go-playground/validator"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+type VerifyEmailEndpoint struct {
+	emailVerifier EmailVerifier
+	validator     *validator.Validate
+}
+
+type EmailVerifier interface {
+	VerifyEmail(ctx context.Context, actor core.Actor, id uuid.UUID, token string) (core.User, error)
+}
+
+// VerifyEmailParams - token the user has been mailed
+type VerifyEmailParams struct {
+	Token string `json:"token" validate:"required"`
+}
+
+func NewVerifyEmailEndpoint(emailVerifier EmailVerifier) *VerifyEmailEndpoint {
+	return &VerifyEmailEndpoint{
+		emailVerifier: emailVerifier,
+		validator:     view.NewValidator(),
+	}
+}
+
+func (v *VerifyEmailEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(time.Millisecond*10000))
+	defer cancel()
+	logrus.WithContext(ctx).
+		WithField("Endoint", "userview.VerifyEmailEndpoint").
+		Debug("request started")
+
+	userID := mux.Vars(r)["userID"]
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		view.RespondBadRequest(ctx, w, fmt.Sprintf("invalid user id: %v", userID), view.InvalidParam{Name: "userID", Reason: "must be a valid UUID"})
+		return
+	}
+	var params VerifyEmailParams
+	if !view.ReadBody(ctx, w, r, &params, v.validator) {
+		return
+	}
+
+	// Token is the proof, so the caller may be anonymous and it gets no user data
+	_, err = v.emailVerifier.VerifyEmail(ctx, core.ActorFromContext(ctx), id, params.Token)
+	if err != nil {
+		view.RespondError(ctx, w, err, "verifying email")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	logrus.WithContext(ctx).
+		WithField("Endoint", "userview.VerifyEmailEndpoint").
+		Debug("request completed")
+}
@@ -0,0 +1,72 @@
+package view
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"com.user.com/user/internal/core"
+	"github.com/sirupsen/logrus"
+)
+
+// ProblemFromError - maps core error types to problem details. Unknown errors are internal ones.
+func ProblemFromError(err error) Problem {
+	var (
+		notFoundErr           *core.NotFoundError
+		validationErr         *core.ValidationError
+		conflictErr           *core.ConflictError
+		preconditionFailedErr *core.PreconditionFailedError
+		unavailableErr        *core.UnavailableError
+		unauthorizedErr       *core.UnauthorizedError
+		forbiddenErr          *core.ForbiddenError
+	)
+	switch {
+	case errors.As(err, &notFoundErr):
+		return NewProblem(ProblemTypeNotFound, http.StatusNotFound, err.Error())
+	case errors.As(err, &validationErr):
+		return NewProblem(ProblemTypeValidation, http.StatusBadRequest, err.Error(),
+			invalidParamsFromField(validationErr.Field, validationErr.Message)...)
+	case errors.As(err, &conflictErr):
+		return NewProblem(ProblemTypeConflict, http.StatusConflict, err.Error(),
+			invalidParamsFromField(conflictErr.Field, conflictErr.Message)...)
+	case errors.As(err, &preconditionFailedErr):
+		return NewProblem(ProblemTypePreconditionFail, http.StatusPreconditionFailed, err.Error())
+	case errors.As(err, &unauthorizedErr):
+		return NewProblem(ProblemTypeUnauthorized, http.StatusUnauthorized, err.Error())
+	case errors.As(err, &forbiddenErr):
+		return NewProblem(ProblemTypeForbidden, http.StatusForbidden, err.Error())
+	case errors.As(err, &unavailableErr):
+		return NewProblem(ProblemTypeUnavailable, http.StatusServiceUnavailable, unavailableErr.Message)
+	default:
+		return NewProblem(ProblemTypeBlank, http.StatusInternalServerError, "")
+	}
+}
+
+func invalidParamsFromField(field, reason string) []InvalidParam {
+	if field == "" {
+		return nil
+	}
+	return []InvalidParam{{Name: field, Reason: reason}}
+}
+
+// RespondError - responds with problem derived from the error type. Details of internal errors
+// are only logged, they are not exposed to the client.
+func RespondError(ctx context.Context, w http.ResponseWriter, err error, action string) {
+	problem := ProblemFromError(err)
+	if problem.Status >= http.StatusInternalServerError {
+		logrus.WithContext(ctx).
+			WithError(err).
+			Errorf("error while %s", action)
+	}
+	if problem.Status == http.StatusInternalServerError {
+		problem.Detail = fmt.Sprintf("error while %s", action)
+	}
+	if problem.Status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", "Bearer, ApiKey")
+	}
+	if problem.Status == http.StatusServiceUnavailable {
+		w.Header().Set("Retry-After", "1")
+	}
+	RespondProblem(ctx, w, problem)
+}
@@ -0,0 +1,49 @@
+package view_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/view"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Problem From Error", func() {
+	table.DescribeTable("maps core errors to status and type",
+		func(err error, expected view.Problem) {
+			Expect(view.ProblemFromError(err)).To(Equal(expected))
+		},
+		table.Entry("not found", &core.NotFoundError{Entity: "user", Key: "42"},
+			view.Problem{Type: view.ProblemTypeNotFound, Title: "Not Found", Status: http.StatusNotFound, Detail: "user not found: 42"}),
+		table.Entry("validation of a field", &core.ValidationError{Field: "email", Message: "invalid email"},
+			view.Problem{Type: view.ProblemTypeValidation, Title: "Bad Request", Status: http.StatusBadRequest, Detail: "invalid email",
+				InvalidParams: []view.InvalidParam{{Name: "email", Reason: "invalid email"}}}),
+		table.Entry("validation of the request", &core.ValidationError{Message: "either next or previous page should be provided"},
+			view.Problem{Type: view.ProblemTypeValidation, Title: "Bad Request", Status: http.StatusBadRequest,
+				Detail: "either next or previous page should be provided"}),
+		table.Entry("conflict", &core.ConflictError{Field: "nickname", Message: "user with such nickname already exists"},
+			view.Problem{Type: view.ProblemTypeConflict, Title: "Conflict", Status: http.StatusConflict,
+				Detail:        "user with such nickname already exists",
+				InvalidParams: []view.InvalidParam{{Name: "nickname", Reason: "user with such nickname already exists"}}}),
+		table.Entry("precondition failed", &core.PreconditionFailedError{Message: "user has been modified in the meantime"},
+			view.Problem{Type: view.ProblemTypePreconditionFail, Title: "Precondition Failed", Status: http.StatusPreconditionFailed,
+				Detail: "user has been modified in the meantime"}),
+		table.Entry("unauthorized", &core.UnauthorizedError{Message: "invalid email or password"},
+			view.Problem{Type: view.ProblemTypeUnauthorized, Title: "Unauthorized", Status: http.StatusUnauthorized,
+				Detail: "invalid email or password"}),
+		table.Entry("forbidden", &core.ForbiddenError{Message: "users:write scope is required"},
+			view.Problem{Type: view.ProblemTypeForbidden, Title: "Forbidden", Status: http.StatusForbidden,
+				Detail: "users:write scope is required"}),
+		table.Entry("unavailable, without its cause", &core.UnavailableError{Message: "database is unavailable", Err: errors.New("connection refused")},
+			view.Problem{Type: view.ProblemTypeUnavailable, Title: "Service Unavailable", Status: http.StatusServiceUnavailable,
+				Detail: "database is unavailable"}),
+		table.Entry("wrapped core error", fmt.Errorf("deleting user: %w", &core.NotFoundError{Entity: "user"}),
+			view.Problem{Type: view.ProblemTypeNotFound, Title: "Not Found", Status: http.StatusNotFound, Detail: "deleting user: user not found"}),
+		table.Entry("unknown error, without details", errors.New("pq: syntax error"),
+			view.Problem{Type: view.ProblemTypeBlank, Title: "Internal Server Error", Status: http.StatusInternalServerError}),
+	)
+})
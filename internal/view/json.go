@@ -0,0 +1,51 @@
+package view
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/go-playground/validator"
+)
+
+// RespondJSON - writes resp as JSON body with given status
+func RespondJSON(ctx context.Context, w http.ResponseWriter, status int, resp interface{}) {
+	jsonBody, err := json.Marshal(&resp)
+	if err != nil {
+		RespondError(ctx, w, err, "serializing response")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(jsonBody)
+}
+
+// ReadBody - decodes JSON body into given struct and validates it. Responds with a problem and returns false
+// if the body is not acceptable.
+func ReadBody(
+	ctx context.Context, w http.ResponseWriter, r *http.Request,
+	into interface{}, validate *validator.Validate,
+) bool {
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		RespondBadRequest(ctx, w, fmt.Sprintf("failed parsing request body: %v", err))
+		return false
+	}
+	_ = r.Body.Close()
+
+	err = json.Unmarshal(data, into)
+	if err != nil {
+		RespondBadRequest(ctx, w, fmt.Sprintf("failed to deserialize request body: %v", err))
+		return false
+	}
+
+	err = validate.Struct(into)
+	if err != nil {
+		RespondProblem(ctx, w, NewProblem(ProblemTypeValidation, http.StatusBadRequest,
+			"invalid parameters", InvalidParamsFromValidation(err)...))
+		return false
+	}
+	return true
+}
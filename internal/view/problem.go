@@ -0,0 +1,130 @@
+// Package view - HTTP helpers shared by all view packages: RFC 7807 problem responses, JSON bodies and validation.
+package view
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator"
+	"github.com/sirupsen/logrus"
+)
+
+const problemContentType = "application/problem+json"
+
+// Problem types - URI references identifying the kind of problem (RFC 7807, section 3.1)
+const (
+	ProblemTypeBlank                = "about:blank"
+	ProblemTypeInvalidRequest       = "/problems/invalid-request"
+	ProblemTypeValidation           = "/problems/validation-error"
+	ProblemTypeNotFound             = "/problems/not-found"
+	ProblemTypeConflict             = "/problems/conflict"
+	ProblemTypePreconditionFail     = "/problems/precondition-failed"
+	ProblemTypePreconditionRequired = "/problems/precondition-required"
+	ProblemTypeUnavailable          = "/problems/unavailable"
+	ProblemTypeUnauthorized         = "/problems/unauthorized"
+	ProblemTypeForbidden            = "/problems/forbidden"
+)
+
+// Problem - RFC 7807 problem details, the body of every error response.
+type Problem struct {
+	Type          string         `json:"type"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail,omitempty"`
+	InvalidParams []InvalidParam `json:"invalid_params,omitempty"`
+}
+
+// InvalidParam - single offending field, so clients are able to point it out to the user.
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// NewProblem - problem details of given type, its title is derived from the status
+func NewProblem(problemType string, status int, detail string, invalidParams ...InvalidParam) Problem {
+	return Problem{
+		Type:          problemType,
+		Title:         http.StatusText(status),
+		Status:        status,
+		Detail:        detail,
+		InvalidParams: invalidParams,
+	}
+}
+
+// RespondProblem - writes problem details as application/problem+json
+func RespondProblem(ctx context.Context, w http.ResponseWriter, problem Problem) {
+	jsonBody, err := json.Marshal(&problem)
+	if err != nil {
+		logrus.WithContext(ctx).WithError(err).Error("failed serializing problem")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", problemContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(problem.Status)
+	_, _ = w.Write(jsonBody)
+}
+
+// RespondBadRequest - for malformed requests which have been rejected before reaching business logic
+func RespondBadRequest(ctx context.Context, w http.ResponseWriter, detail string, invalidParams ...InvalidParam) {
+	RespondProblem(ctx, w, NewProblem(ProblemTypeInvalidRequest, http.StatusBadRequest, detail, invalidParams...))
+}
+
+// RespondInvalidParam - for a single malformed path or query param
+func RespondInvalidParam(ctx context.Context, w http.ResponseWriter, name string, err error) {
+	RespondBadRequest(ctx, w, fmt.Sprintf("invalid '%s' param", name), InvalidParam{
+		Name:   name,
+		Reason: err.Error(),
+	})
+}
+
+// NewValidator - validator which reports fields by their json names
+func NewValidator() *validator.Validate {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return validate
+}
+
+// InvalidParamsFromValidation - translates validator failures into invalid params
+func InvalidParamsFromValidation(err error) []InvalidParam {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil
+	}
+	invalidParams := make([]InvalidParam, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		invalidParams = append(invalidParams, InvalidParam{
+			Name:   fieldErr.Field(),
+			Reason: reasonFromValidationTag(fieldErr),
+		})
+	}
+	return invalidParams
+}
+
+func reasonFromValidationTag(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email"
+	case "url":
+		return "must be a valid URL"
+	case "min":
+		return fmt.Sprintf("must be at least %s", fieldErr.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s", fieldErr.Param())
+	default:
+		return fmt.Sprintf("failed on '%s' validation", fieldErr.Tag())
+	}
+}
@@ -0,0 +1,67 @@
+package view_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"com.user.com/user/internal/view"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+type validatedParams struct {
+	Email    string `json:"email" validate:"required,email"`
+	Nickname string `json:"nickname" validate:"min=3,max=10"`
+	Homepage string `json:"homepage,omitempty" validate:"omitempty,url"`
+	Country  string `json:"country" validate:"len=2"`
+}
+
+var _ = Describe("Invalid Params From Validation", func() {
+	validate := view.NewValidator()
+
+	table.DescribeTable("reports offending fields by their json names",
+		func(params validatedParams, expected ...view.InvalidParam) {
+			Expect(view.InvalidParamsFromValidation(validate.Struct(params))).To(ConsistOf(expected))
+		},
+		table.Entry("required", validatedParams{Nickname: "harry", Country: "UK"},
+			view.InvalidParam{Name: "email", Reason: "is required"}),
+		table.Entry("email", validatedParams{Email: "harry", Nickname: "harry", Country: "UK"},
+			view.InvalidParam{Name: "email", Reason: "must be a valid email"}),
+		table.Entry("url", validatedParams{Email: "harry@faceit.com", Nickname: "harry", Homepage: "faceit", Country: "UK"},
+			view.InvalidParam{Name: "homepage", Reason: "must be a valid URL"}),
+		table.Entry("min", validatedParams{Email: "harry@faceit.com", Nickname: "hp", Country: "UK"},
+			view.InvalidParam{Name: "nickname", Reason: "must be at least 3"}),
+		table.Entry("max", validatedParams{Email: "harry@faceit.com", Nickname: "harry-potter", Country: "UK"},
+			view.InvalidParam{Name: "nickname", Reason: "must be at most 10"}),
+		table.Entry("other tag", validatedParams{Email: "harry@faceit.com", Nickname: "harry", Country: "GBR"},
+			view.InvalidParam{Name: "country", Reason: "failed on 'len' validation"}),
+		table.Entry("every offending field", validatedParams{Nickname: "hp", Country: "UK"},
+			view.InvalidParam{Name: "email", Reason: "is required"},
+			view.InvalidParam{Name: "nickname", Reason: "must be at least 3"}),
+	)
+
+	It("reports nothing for other errors", func() {
+		Expect(view.InvalidParamsFromValidation(errors.New("test-error"))).To(BeNil())
+	})
+
+	It("responds with invalid params of the body", func() {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"email": "harry", "nickname": "harry", "country": "UK"}`))
+		var params struct {
+			Email    string `json:"email" validate:"email"`
+			Nickname string `json:"nickname" validate:"required"`
+		}
+		Expect(view.ReadBody(context.Background(), recorder, request, &params, validate)).To(BeFalse())
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("application/problem+json"))
+		var problem view.Problem
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &problem)).To(Succeed())
+		Expect(problem.Type).To(Equal(view.ProblemTypeValidation))
+		Expect(problem.InvalidParams).To(Equal([]view.InvalidParam{{Name: "email", Reason: "must be a valid email"}}))
+	})
+})
@@ -0,0 +1,82 @@
+package view_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/view"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Respond Error", func() {
+	var recorder *httptest.ResponseRecorder
+
+	BeforeEach(func() {
+		recorder = httptest.NewRecorder()
+	})
+
+	problem := func() view.Problem {
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("application/problem+json"))
+		Expect(recorder.Header().Get("X-Content-Type-Options")).To(Equal("nosniff"))
+		var body view.Problem
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &body)).To(Succeed())
+		Expect(body.Status).To(Equal(recorder.Code))
+		return body
+	}
+
+	It("writes problem details of core error", func() {
+		err := fmt.Errorf("getting user: %w", &core.NotFoundError{Entity: "user", Key: "42"})
+		view.RespondError(context.Background(), recorder, err, "getting user")
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		Expect(problem()).To(Equal(view.Problem{
+			Type:   view.ProblemTypeNotFound,
+			Title:  "Not Found",
+			Status: http.StatusNotFound,
+			Detail: "getting user: user not found: 42",
+		}))
+	})
+
+	It("writes offending field of validation error", func() {
+		view.RespondError(context.Background(), recorder, &core.ValidationError{Field: "email", Message: "invalid email"}, "creating user")
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		Expect(problem()).To(Equal(view.Problem{
+			Type:          view.ProblemTypeValidation,
+			Title:         "Bad Request",
+			Status:        http.StatusBadRequest,
+			Detail:        "invalid email",
+			InvalidParams: []view.InvalidParam{{Name: "email", Reason: "invalid email"}},
+		}))
+	})
+
+	It("hides details of internal error", func() {
+		view.RespondError(context.Background(), recorder, errors.New("pq: password authentication failed"), "getting user")
+		Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+		Expect(problem()).To(Equal(view.Problem{
+			Type:   view.ProblemTypeBlank,
+			Title:  "Internal Server Error",
+			Status: http.StatusInternalServerError,
+			Detail: "error while getting user",
+		}))
+	})
+
+	It("asks unauthorized clients to authenticate", func() {
+		view.RespondError(context.Background(), recorder, &core.UnauthorizedError{Message: "authentication required"}, "getting user")
+		Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+		Expect(recorder.Header().Get("WWW-Authenticate")).To(Equal("Bearer, ApiKey"))
+		Expect(problem().Type).To(Equal(view.ProblemTypeUnauthorized))
+	})
+
+	It("asks clients to retry when unavailable, without the cause", func() {
+		err := &core.UnavailableError{Message: "database is unavailable", Err: errors.New("dial tcp 10.0.0.1:26257: connection refused")}
+		view.RespondError(context.Background(), recorder, err, "getting user")
+		Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(recorder.Header().Get("Retry-After")).To(Equal("1"))
+		Expect(problem().Detail).To(Equal("database is unavailable"))
+	})
+})
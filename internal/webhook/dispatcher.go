@@ -0,0 +1,187 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	userAgent = "user-service-webhooks"
+	// maxResponseBody - responses are read only to let the connection be reused
+	maxResponseBody = 64 * 1024
+)
+
+type DispatcherConfig struct {
+	// BatchSize - max number of deliveries claimed at once
+	BatchSize int
+	// PollInterval - how often pending deliveries are checked once there are no more due
+	PollInterval time.Duration
+	// Lease - how long claimed deliveries are hidden from other dispatchers. It has to be longer than sending a batch.
+	Lease time.Duration
+	// Timeout - of a single delivery attempt
+	Timeout time.Duration
+	// MaxAttempts - deliveries are moved to dead letter after that many failed attempts
+	MaxAttempts int
+	// MinBackoff, MaxBackoff - bounds of exponential backoff between attempts
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+var DefaultDispatcherConfig = DispatcherConfig{
+	BatchSize:    50,
+	PollInterval: time.Second,
+	Lease:        5 * time.Minute,
+	Timeout:      10 * time.Second,
+	MaxAttempts:  10,
+	MinBackoff:   10 * time.Second,
+	MaxBackoff:   time.Hour,
+}
+
+// Dispatcher - sends pending deliveries to webhook endpoints, signed by their secrets.
+// Failed deliveries are retried with exponential backoff until they run out of attempts.
+type Dispatcher struct {
+	store  WebhookStore
+	client *http.Client
+	config DispatcherConfig
+}
+
+func NewDispatcher(store WebhookStore, config DispatcherConfig) *Dispatcher {
+	return &Dispatcher{
+		store:  store,
+		client: &http.Client{Timeout: config.Timeout},
+		config: config,
+	}
+}
+
+// Run - sends deliveries until ctx is done
+func (d *Dispatcher) Run(ctx context.Context) {
+	for {
+		claimed, err := d.DeliverPending(ctx)
+		if err != nil {
+			logrus.WithContext(ctx).WithError(err).Error("webhook: failed to deliver pending deliveries")
+		}
+		// Full batch means there are likely more deliveries waiting
+		if err == nil && claimed == d.config.BatchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(d.config.PollInterval):
+		}
+	}
+}
+
+// DeliverPending - claims a batch of due deliveries and sends them. Returns number of claimed deliveries.
+func (d *Dispatcher) DeliverPending(ctx context.Context) (int, error) {
+	deliveries, err := d.store.ClaimDeliveries(ctx, d.config.BatchSize, d.config.Lease)
+	if err != nil {
+		return 0, err
+	}
+	webhooks := make(map[uuid.UUID]core.Webhook)
+	for _, delivery := range deliveries {
+		webhook, ok := webhooks[delivery.WebhookID]
+		if !ok {
+			webhook, err = d.store.GetWebhookByID(ctx, delivery.WebhookID)
+			var notFoundErr *core.NotFoundError
+			if errors.As(err, &notFoundErr) {
+				// Webhook has been removed in the meantime, its deliveries are gone as well
+				continue
+			}
+			if err != nil {
+				return len(deliveries), err
+			}
+			webhooks[delivery.WebhookID] = webhook
+		}
+
+		attempt := d.send(ctx, webhook, delivery)
+		retryIn := time.Duration(0)
+		switch {
+		case attempt.Error == "":
+			delivery.Status = core.DeliverySucceeded
+			delivery.LastError = ""
+		case delivery.AttemptCount >= d.config.MaxAttempts:
+			delivery.Status = core.DeliveryDeadLetter
+			delivery.LastError = attempt.Error
+		default:
+			delivery.Status = core.DeliveryPending
+			delivery.LastError = attempt.Error
+			retryIn = d.backoff(delivery.AttemptCount)
+		}
+		if delivery.Status != core.DeliverySucceeded {
+			logrus.WithContext(ctx).
+				WithField("webhook_id", webhook.ID).
+				WithField("delivery_id", delivery.ID).
+				WithField("attempt", attempt.Number).
+				WithField("status", delivery.Status).
+				WithField("error", attempt.Error).
+				Warn("webhook: delivery attempt failed")
+		}
+
+		err = d.store.RecordAttempt(ctx, delivery, attempt, retryIn)
+		if err != nil {
+			return len(deliveries), err
+		}
+	}
+	return len(deliveries), nil
+}
+
+// send - makes single delivery attempt. Only 2xx responses count as delivered.
+func (d *Dispatcher) send(ctx context.Context, webhook core.Webhook, delivery core.WebhookDelivery) (attempt core.DeliveryAttempt) {
+	attempt = core.DeliveryAttempt{
+		Number:      delivery.AttemptCount,
+		AttemptedAt: time.Now().UTC(),
+	}
+	defer func() {
+		attempt.Duration = time.Since(attempt.AttemptedAt)
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(HeaderDeliveryID, delivery.ID.String())
+	req.Header.Set(HeaderEventType, string(delivery.EventType))
+	req.Header.Set(HeaderTimestamp, fmt.Sprint(attempt.AttemptedAt.Unix()))
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, attempt.AttemptedAt, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	defer func() {
+		_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, maxResponseBody))
+		_ = resp.Body.Close()
+	}()
+	attempt.StatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		attempt.Error = fmt.Sprintf("unexpected response status %d", resp.StatusCode)
+	}
+	return attempt
+}
+
+// backoff - doubles the delay with every attempt, up to MaxBackoff
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.config.MinBackoff
+	for i := 1; i < attempts && delay < d.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > d.config.MaxBackoff {
+		return d.config.MaxBackoff
+	}
+	return delay
+}
@@ -0,0 +1,145 @@
+package webhook_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/webhook"
+	"com.user.com/user/internal/webhook/webhookfakes"
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Webhook Dispatcher", func() {
+	var (
+		store      *webhookfakes.FakeWebhookStore
+		server     *httptest.Server
+		status     int
+		requests   chan *http.Request
+		bodies     chan []byte
+		config     webhook.DispatcherConfig
+		dispatcher *webhook.Dispatcher
+		hook       core.Webhook
+		delivery   core.WebhookDelivery
+		ctx        context.Context
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		status = http.StatusOK
+		requests = make(chan *http.Request, 1)
+		bodies = make(chan []byte, 1)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			requests <- r
+			bodies <- body
+			w.WriteHeader(status)
+		}))
+
+		hook = core.Webhook{ID: uuid.New(), URL: server.URL, Secret: "secret"}
+		delivery = core.WebhookDelivery{
+			ID:           uuid.New(),
+			WebhookID:    hook.ID,
+			EventID:      uuid.New(),
+			EventType:    core.EventUserCreated,
+			Payload:      []byte(`{"type":"user.created"}`),
+			Status:       core.DeliveryPending,
+			AttemptCount: 1,
+		}
+		store = &webhookfakes.FakeWebhookStore{}
+		store.GetWebhookByIDReturns(hook, nil)
+		config = webhook.DispatcherConfig{
+			BatchSize:   10,
+			Lease:       time.Minute,
+			Timeout:     time.Second,
+			MaxAttempts: 3,
+			MinBackoff:  time.Second,
+			MaxBackoff:  time.Minute,
+		}
+		dispatcher = webhook.NewDispatcher(store, config)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	JustBeforeEach(func() {
+		store.ClaimDeliveriesReturns([]core.WebhookDelivery{delivery}, nil)
+		claimed, err := dispatcher.DeliverPending(ctx)
+		Expect(err).To(BeNil())
+		Expect(claimed).To(Equal(1))
+	})
+
+	Context("When endpoint accepts the delivery", func() {
+		It("sends the payload signed by the webhook secret", func() {
+			req := <-requests
+			body := <-bodies
+			Expect(body).To(Equal(delivery.Payload))
+			Expect(req.Header.Get(webhook.HeaderDeliveryID)).To(Equal(delivery.ID.String()))
+			Expect(req.Header.Get(webhook.HeaderEventType)).To(Equal("user.created"))
+
+			timestamp, err := strconv.ParseInt(req.Header.Get(webhook.HeaderTimestamp), 10, 64)
+			Expect(err).To(BeNil())
+			signature := req.Header.Get(webhook.HeaderSignature)
+			Expect(webhook.VerifySignature("secret", time.Unix(timestamp, 0), body, signature)).To(BeTrue())
+			Expect(webhook.VerifySignature("other-secret", time.Unix(timestamp, 0), body, signature)).To(BeFalse())
+		})
+		It("records successful attempt", func() {
+			_, recorded, attempt, _ := store.RecordAttemptArgsForCall(0)
+			Expect(recorded.Status).To(Equal(core.DeliverySucceeded))
+			Expect(attempt.Number).To(Equal(1))
+			Expect(attempt.StatusCode).To(Equal(http.StatusOK))
+			Expect(attempt.Error).To(BeEmpty())
+		})
+	})
+
+	Context("When endpoint fails", func() {
+		BeforeEach(func() {
+			status = http.StatusInternalServerError
+			delivery.AttemptCount = 2
+		})
+		It("keeps the delivery pending and retries it with backoff", func() {
+			_, recorded, attempt, retryIn := store.RecordAttemptArgsForCall(0)
+			Expect(recorded.Status).To(Equal(core.DeliveryPending))
+			Expect(recorded.LastError).To(Equal("unexpected response status 500"))
+			Expect(attempt.StatusCode).To(Equal(http.StatusInternalServerError))
+			Expect(retryIn).To(Equal(2 * time.Second))
+		})
+		Context("For the last time", func() {
+			BeforeEach(func() {
+				delivery.AttemptCount = config.MaxAttempts
+			})
+			It("moves the delivery to dead letter", func() {
+				_, recorded, _, _ := store.RecordAttemptArgsForCall(0)
+				Expect(recorded.Status).To(Equal(core.DeliveryDeadLetter))
+			})
+		})
+	})
+
+	Context("When endpoint is unreachable", func() {
+		BeforeEach(func() {
+			server.Close()
+		})
+		It("records the attempt without status code", func() {
+			_, recorded, attempt, _ := store.RecordAttemptArgsForCall(0)
+			Expect(recorded.Status).To(Equal(core.DeliveryPending))
+			Expect(attempt.StatusCode).To(Equal(0))
+			Expect(attempt.Error).ToNot(BeEmpty())
+		})
+	})
+
+	Context("When webhook has been removed", func() {
+		BeforeEach(func() {
+			store.GetWebhookByIDReturns(core.Webhook{}, &core.NotFoundError{Entity: "webhook"})
+		})
+		It("skips the delivery", func() {
+			Expect(store.RecordAttemptCallCount()).To(Equal(0))
+		})
+	})
+})
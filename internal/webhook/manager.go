@@ -0,0 +1,169 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/notifier"
+	"github.com/google/uuid"
+)
+
+//go:generate ~/go/bin/counterfeiter  . WebhookStore
+
+type WebhookStore interface {
+	SaveWebhook(ctx context.Context, webhook core.Webhook) (core.Webhook, error)
+	UpdateWebhook(ctx context.Context, webhook core.Webhook) (core.Webhook, error)
+	DeleteWebhook(ctx context.Context, id uuid.UUID) error
+	GetWebhookByID(ctx context.Context, id uuid.UUID) (core.Webhook, error)
+	GetAllWebhooks(ctx context.Context) ([]core.Webhook, error)
+	// GetWebhooksForEvent - active webhooks subscribed to given event type
+	GetWebhooksForEvent(ctx context.Context, eventType core.EventType) ([]core.Webhook, error)
+
+	// SaveDeliveries - stores new deliveries. Deliveries of an event which is already stored for the webhook are skipped.
+	SaveDeliveries(ctx context.Context, deliveries []core.WebhookDelivery) error
+	// ClaimDeliveries - returns up to limit pending deliveries which are due, with their attempt count incremented.
+	// Claimed deliveries are hidden from other dispatchers for the lease duration.
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]core.WebhookDelivery, error)
+	// RecordAttempt - stores the attempt along with the new state of the delivery. Pending delivery is due again after retryIn.
+	RecordAttempt(ctx context.Context, delivery core.WebhookDelivery, attempt core.DeliveryAttempt, retryIn time.Duration) error
+	// GetDeliveries - latest deliveries of the webhook along with their attempts
+	GetDeliveries(ctx context.Context, webhookID uuid.UUID, limit int) ([]core.WebhookDelivery, error)
+}
+
+const (
+	secretLength         = 32
+	defaultDeliveryLimit = 50
+	maxDeliveryLimit     = 500
+)
+
+// Manager - manages webhook subscriptions. It implements user.Notifier, which turns events into
+// persisted deliveries, sent by Dispatcher.
+type Manager struct {
+	store WebhookStore
+}
+
+func NewManager(store WebhookStore) *Manager {
+	return &Manager{
+		store: store,
+	}
+}
+
+// CreateWebhook - registers the webhook. Secret is generated unless it is provided.
+func (m *Manager) CreateWebhook(ctx context.Context, webhook core.Webhook) (core.Webhook, error) {
+	err := validateWebhook(webhook)
+	if err != nil {
+		return core.Webhook{}, err
+	}
+	webhook.ID = uuid.New()
+	if webhook.Secret == "" {
+		webhook.Secret, err = generateSecret()
+		if err != nil {
+			return core.Webhook{}, err
+		}
+	}
+	return m.store.SaveWebhook(ctx, webhook)
+}
+
+// ModifyWebhook - replaces URL, event types and state of the webhook. Secret is kept unless a new one is provided.
+func (m *Manager) ModifyWebhook(ctx context.Context, webhook core.Webhook) (core.Webhook, error) {
+	err := validateWebhook(webhook)
+	if err != nil {
+		return core.Webhook{}, err
+	}
+	current, err := m.store.GetWebhookByID(ctx, webhook.ID)
+	if err != nil {
+		return core.Webhook{}, err
+	}
+	if webhook.Secret == "" {
+		webhook.Secret = current.Secret
+	}
+	webhook.CreatedAt = current.CreatedAt
+	return m.store.UpdateWebhook(ctx, webhook)
+}
+
+// RemoveWebhook - deletes the webhook along with its deliveries
+func (m *Manager) RemoveWebhook(ctx context.Context, id uuid.UUID) error {
+	return m.store.DeleteWebhook(ctx, id)
+}
+
+// GetWebhookByID - returns core.NotFoundError if there is no such webhook
+func (m *Manager) GetWebhookByID(ctx context.Context, id uuid.UUID) (core.Webhook, error) {
+	return m.store.GetWebhookByID(ctx, id)
+}
+
+func (m *Manager) GetAllWebhooks(ctx context.Context) ([]core.Webhook, error) {
+	return m.store.GetAllWebhooks(ctx)
+}
+
+// GetDeliveries - delivery log of the webhook, latest deliveries first. Zero limit means the default one.
+func (m *Manager) GetDeliveries(ctx context.Context, webhookID uuid.UUID, limit int) ([]core.WebhookDelivery, error) {
+	// Missing webhook is reported rather than an empty log
+	_, err := m.store.GetWebhookByID(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultDeliveryLimit
+	}
+	if limit > maxDeliveryLimit {
+		limit = maxDeliveryLimit
+	}
+	return m.store.GetDeliveries(ctx, webhookID, limit)
+}
+
+// Notify - schedules delivery of the event to every active webhook subscribed to its type
+func (m *Manager) Notify(ctx context.Context, event core.Event) error {
+	webhooks, err := m.store.GetWebhooksForEvent(ctx, event.Type)
+	if err != nil {
+		return err
+	}
+	if len(webhooks) == 0 {
+		return nil
+	}
+	payload, _, err := notifier.EncodeEvent(event, notifier.FormatJSON)
+	if err != nil {
+		return err
+	}
+	deliveries := make([]core.WebhookDelivery, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		deliveries = append(deliveries, core.WebhookDelivery{
+			ID:        uuid.New(),
+			WebhookID: webhook.ID,
+			EventID:   event.ID,
+			EventType: event.Type,
+			Payload:   payload,
+			Status:    core.DeliveryPending,
+		})
+	}
+	return m.store.SaveDeliveries(ctx, deliveries)
+}
+
+func validateWebhook(webhook core.Webhook) error {
+	u, err := url.Parse(webhook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return &core.ValidationError{Field: "url", Message: "url must be an absolute http or https URL"}
+	}
+	if len(webhook.EventTypes) == 0 {
+		return &core.ValidationError{Field: "event_types", Message: "at least one event type is required"}
+	}
+	for _, eventType := range webhook.EventTypes {
+		if !eventType.Valid() {
+			return &core.ValidationError{Field: "event_types", Message: fmt.Sprintf("unknown event type %q", eventType)}
+		}
+	}
+	return nil
+}
+
+func generateSecret() (string, error) {
+	secret := make([]byte, secretLength)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
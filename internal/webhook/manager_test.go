@@ -0,0 +1,188 @@
+package webhook_test
+
+import (
+	"context"
+	"errors"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/webhook"
+	"com.user.com/user/internal/webhook/webhookfakes"
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Webhook Manager", func() {
+	var (
+		store   *webhookfakes.FakeWebhookStore
+		manager *webhook.Manager
+		ctx     context.Context
+	)
+
+	BeforeEach(func() {
+		store = &webhookfakes.FakeWebhookStore{}
+		store.SaveWebhookStub = func(_ context.Context, w core.Webhook) (core.Webhook, error) {
+			return w, nil
+		}
+		store.UpdateWebhookStub = func(_ context.Context, w core.Webhook) (core.Webhook, error) {
+			return w, nil
+		}
+		manager = webhook.NewManager(store)
+		ctx = context.Background()
+	})
+
+	Context("Create Webhook", func() {
+		var (
+			params  core.Webhook
+			created core.Webhook
+			err     error
+		)
+		BeforeEach(func() {
+			params = core.Webhook{
+				URL:        "https://example.com/hooks",
+				EventTypes: []core.EventType{core.EventUserCreated},
+				Active:     true,
+			}
+		})
+		JustBeforeEach(func() {
+			created, err = manager.CreateWebhook(ctx, params)
+		})
+		Context("With relative URL", func() {
+			BeforeEach(func() {
+				params.URL = "/hooks"
+			})
+			It("fails with validation error of url field", func() {
+				var validationErr *core.ValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				Expect(validationErr.Field).To(Equal("url"))
+				Expect(store.SaveWebhookCallCount()).To(Equal(0))
+			})
+		})
+		Context("With unknown event type", func() {
+			BeforeEach(func() {
+				params.EventTypes = []core.EventType{"user.renamed"}
+			})
+			It("fails with validation error of event_types field", func() {
+				var validationErr *core.ValidationError
+				Expect(errors.As(err, &validationErr)).To(BeTrue())
+				Expect(validationErr.Field).To(Equal("event_types"))
+			})
+		})
+		Context("Without secret", func() {
+			It("stores the webhook with generated id and secret", func() {
+				Expect(err).To(BeNil())
+				Expect(created.ID).ToNot(Equal(uuid.Nil))
+				Expect(created.Secret).To(HaveLen(64))
+			})
+		})
+		Context("With secret", func() {
+			BeforeEach(func() {
+				params.Secret = "my-own-secret-value"
+			})
+			It("keeps given secret", func() {
+				Expect(created.Secret).To(Equal("my-own-secret-value"))
+			})
+		})
+	})
+
+	Context("Modify Webhook", func() {
+		var (
+			current  core.Webhook
+			modified core.Webhook
+			err      error
+		)
+		BeforeEach(func() {
+			current = core.Webhook{
+				ID:         uuid.New(),
+				URL:        "https://example.com/hooks",
+				EventTypes: []core.EventType{core.EventUserCreated},
+				Secret:     "stored-secret",
+			}
+			store.GetWebhookByIDReturns(current, nil)
+		})
+		JustBeforeEach(func() {
+			modified, err = manager.ModifyWebhook(ctx, core.Webhook{
+				ID:         current.ID,
+				URL:        "https://example.com/other",
+				EventTypes: []core.EventType{core.EventUserDeleted},
+			})
+		})
+		It("keeps the stored secret", func() {
+			Expect(err).To(BeNil())
+			Expect(modified.URL).To(Equal("https://example.com/other"))
+			Expect(modified.Secret).To(Equal("stored-secret"))
+		})
+		Context("When webhook does not exist", func() {
+			BeforeEach(func() {
+				store.GetWebhookByIDReturns(core.Webhook{}, &core.NotFoundError{Entity: "webhook"})
+			})
+			It("fails with not found error", func() {
+				Expect(err).To(BeAssignableToTypeOf(&core.NotFoundError{}))
+				Expect(store.UpdateWebhookCallCount()).To(Equal(0))
+			})
+		})
+	})
+
+	Context("Notify", func() {
+		var (
+			event core.Event
+			err   error
+		)
+		BeforeEach(func() {
+			event = core.NewUserCreatedEvent(core.SystemActor, core.User{ID: uuid.New()})
+		})
+		JustBeforeEach(func() {
+			err = manager.Notify(ctx, event)
+		})
+		Context("When no webhook is subscribed", func() {
+			It("schedules no delivery", func() {
+				Expect(err).To(BeNil())
+				_, eventType := store.GetWebhooksForEventArgsForCall(0)
+				Expect(eventType).To(Equal(core.EventUserCreated))
+				Expect(store.SaveDeliveriesCallCount()).To(Equal(0))
+			})
+		})
+		Context("When webhooks are subscribed", func() {
+			var webhooks []core.Webhook
+			BeforeEach(func() {
+				webhooks = []core.Webhook{{ID: uuid.New()}, {ID: uuid.New()}}
+				store.GetWebhooksForEventReturns(webhooks, nil)
+			})
+			It("schedules pending delivery of the event to each of them", func() {
+				Expect(err).To(BeNil())
+				_, deliveries := store.SaveDeliveriesArgsForCall(0)
+				Expect(deliveries).To(HaveLen(2))
+				Expect(deliveries[0].WebhookID).To(Equal(webhooks[0].ID))
+				Expect(deliveries[1].WebhookID).To(Equal(webhooks[1].ID))
+				for _, delivery := range deliveries {
+					Expect(delivery.EventID).To(Equal(event.ID))
+					Expect(delivery.Status).To(Equal(core.DeliveryPending))
+					Expect(string(delivery.Payload)).To(ContainSubstring(event.ID.String()))
+				}
+			})
+		})
+		Context("When webhooks cannot be loaded", func() {
+			BeforeEach(func() {
+				store.GetWebhooksForEventReturns(nil, errors.New("db-error"))
+			})
+			It("fails, so the event is published again", func() {
+				Expect(err).To(MatchError("db-error"))
+			})
+		})
+	})
+
+	Context("Get Deliveries", func() {
+		It("fails with not found error when webhook does not exist", func() {
+			store.GetWebhookByIDReturns(core.Webhook{}, &core.NotFoundError{Entity: "webhook"})
+			_, err := manager.GetDeliveries(ctx, uuid.New(), 0)
+			Expect(err).To(BeAssignableToTypeOf(&core.NotFoundError{}))
+			Expect(store.GetDeliveriesCallCount()).To(Equal(0))
+		})
+		It("uses default limit", func() {
+			_, err := manager.GetDeliveries(ctx, uuid.New(), 0)
+			Expect(err).To(BeNil())
+			_, _, limit := store.GetDeliveriesArgsForCall(0)
+			Expect(limit).To(Equal(50))
+		})
+	})
+})
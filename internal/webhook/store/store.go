@@ -0,0 +1,328 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	webhookColumns     = `id, url, event_types, secret, active, created_at, updated_at`
+	storeWebhookStmt   = `INSERT INTO webhooks (id, url, event_types, secret, active, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, now(), now()) RETURNING created_at, updated_at`
+	updateWebhookStmt  = `UPDATE webhooks SET url=$1, event_types=$2, secret=$3, active=$4, updated_at=now() WHERE id=$5 RETURNING created_at, updated_at`
+	deleteWebhookStmt  = `DELETE FROM webhooks WHERE id=$1`
+	getWebhookStmt     = `SELECT ` + webhookColumns + ` FROM webhooks WHERE id=$1`
+	getAllWebhooksStmt = `SELECT ` + webhookColumns + ` FROM webhooks ORDER BY created_at`
+	getEventWebhooks   = `SELECT ` + webhookColumns + ` FROM webhooks WHERE active AND $1 = ANY(event_types)`
+
+	deliveryColumns     = `id, webhook_id, event_id, event_type, payload, status, attempts, last_error, created_at, updated_at`
+	storeDeliveryStmt   = `INSERT INTO webhook_deliveries (id, webhook_id, event_id, event_type, payload, status, next_attempt_at, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, now(), now(), now()) ON CONFLICT (webhook_id, event_id) DO NOTHING`
+	claimDeliveriesStmt = `UPDATE webhook_deliveries SET attempts=attempts+1, next_attempt_at=now() + $1::FLOAT8 * INTERVAL '1 second', updated_at=now()
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status='pending' AND next_attempt_at <= now()
+			ORDER BY next_attempt_at
+			LIMIT $2
+		)
+		RETURNING ` + deliveryColumns
+	updateDeliveryStmt = `UPDATE webhook_deliveries SET status=$1, last_error=$2, next_attempt_at=now() + $3::FLOAT8 * INTERVAL '1 second', updated_at=now() WHERE id=$4`
+	storeAttemptStmt   = `INSERT INTO webhook_delivery_attempts (delivery_id, number, attempted_at, duration_ms, status_code, error) VALUES ($1, $2, $3, $4, $5, $6)`
+	getDeliveriesStmt  = `SELECT ` + deliveryColumns + ` FROM webhook_deliveries WHERE webhook_id=$1 ORDER BY created_at DESC LIMIT $2`
+	getAttemptsStmt    = `SELECT delivery_id, number, attempted_at, duration_ms, status_code, error FROM webhook_delivery_attempts WHERE delivery_id = ANY($1::UUID[]) ORDER BY number`
+)
+
+// Store - webhooks and their deliveries in db
+type Store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{
+		db: db,
+	}
+}
+
+// SaveWebhook - stores the webhook and returns it along with db generated timestamps
+func (s *Store) SaveWebhook(ctx context.Context, webhook core.Webhook) (core.Webhook, error) {
+	err := s.db.QueryRowContext(ctx,
+		storeWebhookStmt,
+		webhook.ID,
+		webhook.URL,
+		pq.Array(eventTypesToStrings(webhook.EventTypes)),
+		webhook.Secret,
+		webhook.Active,
+	).Scan(
+		&webhook.CreatedAt,
+		&webhook.UpdatedAt,
+	)
+	if err != nil {
+		return core.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+// UpdateWebhook - returns core.NotFoundError if there is no such webhook
+func (s *Store) UpdateWebhook(ctx context.Context, webhook core.Webhook) (core.Webhook, error) {
+	err := s.db.QueryRowContext(ctx,
+		updateWebhookStmt,
+		webhook.URL,
+		pq.Array(eventTypesToStrings(webhook.EventTypes)),
+		webhook.Secret,
+		webhook.Active,
+		webhook.ID,
+	).Scan(
+		&webhook.CreatedAt,
+		&webhook.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.Webhook{}, &core.NotFoundError{Entity: "webhook", Key: webhook.ID.String()}
+	}
+	if err != nil {
+		return core.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+// DeleteWebhook - deletes the webhook along with its deliveries. Returns core.NotFoundError if there is no such webhook.
+func (s *Store) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	res, err := s.db.ExecContext(ctx, deleteWebhookStmt, id)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return &core.NotFoundError{Entity: "webhook", Key: id.String()}
+	}
+	return nil
+}
+
+// GetWebhookByID - returns core.NotFoundError if there is no such webhook
+func (s *Store) GetWebhookByID(ctx context.Context, id uuid.UUID) (core.Webhook, error) {
+	webhook, err := scanWebhook(s.db.QueryRowContext(ctx, getWebhookStmt, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.Webhook{}, &core.NotFoundError{Entity: "webhook", Key: id.String()}
+	}
+	if err != nil {
+		return core.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+func (s *Store) GetAllWebhooks(ctx context.Context) ([]core.Webhook, error) {
+	return s.queryWebhooks(ctx, getAllWebhooksStmt)
+}
+
+// GetWebhooksForEvent - active webhooks subscribed to given event type
+func (s *Store) GetWebhooksForEvent(ctx context.Context, eventType core.EventType) ([]core.Webhook, error) {
+	return s.queryWebhooks(ctx, getEventWebhooks, string(eventType))
+}
+
+func (s *Store) queryWebhooks(ctx context.Context, query string, args ...interface{}) ([]core.Webhook, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	webhooks := make([]core.Webhook, 0)
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+// SaveDeliveries - stores new deliveries in a single transaction. Events already stored for a webhook are skipped,
+// so republished events are not delivered twice.
+func (s *Store) SaveDeliveries(ctx context.Context, deliveries []core.WebhookDelivery) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, delivery := range deliveries {
+			_, err := tx.ExecContext(ctx,
+				storeDeliveryStmt,
+				delivery.ID,
+				delivery.WebhookID,
+				delivery.EventID,
+				string(delivery.EventType),
+				delivery.Payload,
+				string(delivery.Status),
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ClaimDeliveries - returns up to limit pending deliveries which are due, with their attempt count incremented.
+// Claimed deliveries are hidden from other dispatchers for the lease duration.
+func (s *Store) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]core.WebhookDelivery, error) {
+	return s.queryDeliveries(ctx, claimDeliveriesStmt, lease.Seconds(), limit)
+}
+
+// RecordAttempt - stores the attempt along with the new state of the delivery. Pending delivery is due again after retryIn.
+func (s *Store) RecordAttempt(ctx context.Context, delivery core.WebhookDelivery, attempt core.DeliveryAttempt, retryIn time.Duration) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx,
+			storeAttemptStmt,
+			delivery.ID,
+			attempt.Number,
+			attempt.AttemptedAt,
+			attempt.Duration.Milliseconds(),
+			sql.NullInt64{Int64: int64(attempt.StatusCode), Valid: attempt.StatusCode != 0},
+			sql.NullString{String: attempt.Error, Valid: attempt.Error != ""},
+		)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx,
+			updateDeliveryStmt,
+			string(delivery.Status),
+			sql.NullString{String: delivery.LastError, Valid: delivery.LastError != ""},
+			retryIn.Seconds(),
+			delivery.ID,
+		)
+		return err
+	})
+}
+
+// GetDeliveries - latest deliveries of the webhook along with their attempts
+func (s *Store) GetDeliveries(ctx context.Context, webhookID uuid.UUID, limit int) ([]core.WebhookDelivery, error) {
+	deliveries, err := s.queryDeliveries(ctx, getDeliveriesStmt, webhookID, limit)
+	if err != nil || len(deliveries) == 0 {
+		return deliveries, err
+	}
+
+	ids := make([]string, 0, len(deliveries))
+	byID := make(map[uuid.UUID]*core.WebhookDelivery, len(deliveries))
+	for i := range deliveries {
+		ids = append(ids, deliveries[i].ID.String())
+		byID[deliveries[i].ID] = &deliveries[i]
+	}
+	rows, err := s.db.QueryContext(ctx, getAttemptsStmt, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	for rows.Next() {
+		var (
+			deliveryID uuid.UUID
+			attempt    core.DeliveryAttempt
+			durationMS int64
+			statusCode sql.NullInt64
+			attemptErr sql.NullString
+		)
+		err := rows.Scan(&deliveryID, &attempt.Number, &attempt.AttemptedAt, &durationMS, &statusCode, &attemptErr)
+		if err != nil {
+			return nil, err
+		}
+		attempt.Duration = time.Duration(durationMS) * time.Millisecond
+		attempt.StatusCode = int(statusCode.Int64)
+		attempt.Error = attemptErr.String
+		delivery := byID[deliveryID]
+		delivery.Attempts = append(delivery.Attempts, attempt)
+	}
+	return deliveries, rows.Err()
+}
+
+func (s *Store) queryDeliveries(ctx context.Context, query string, args ...interface{}) ([]core.WebhookDelivery, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	deliveries := make([]core.WebhookDelivery, 0)
+	for rows.Next() {
+		var (
+			delivery  core.WebhookDelivery
+			eventType string
+			status    string
+			lastError sql.NullString
+		)
+		err := rows.Scan(
+			&delivery.ID,
+			&delivery.WebhookID,
+			&delivery.EventID,
+			&eventType,
+			&delivery.Payload,
+			&status,
+			&delivery.AttemptCount,
+			&lastError,
+			&delivery.CreatedAt,
+			&delivery.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		delivery.EventType = core.EventType(eventType)
+		delivery.Status = core.DeliveryStatus(status)
+		delivery.LastError = lastError.String
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+func (s *Store) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanWebhook(row rowScanner) (core.Webhook, error) {
+	var (
+		webhook    core.Webhook
+		eventTypes []string
+	)
+	err := row.Scan(
+		&webhook.ID,
+		&webhook.URL,
+		pq.Array(&eventTypes),
+		&webhook.Secret,
+		&webhook.Active,
+		&webhook.CreatedAt,
+		&webhook.UpdatedAt,
+	)
+	if err != nil {
+		return core.Webhook{}, err
+	}
+	for _, eventType := range eventTypes {
+		webhook.EventTypes = append(webhook.EventTypes, core.EventType(eventType))
+	}
+	return webhook, nil
+}
+
+func eventTypesToStrings(eventTypes []core.EventType) []string {
+	result := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		result = append(result, string(eventType))
+	}
+	return result
+}
@@ -0,0 +1,847 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package webhookfakes
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/webhook"
+	"github.com/google/uuid"
+)
+
+type FakeWebhookStore struct {
+	ClaimDeliveriesStub        func(context.Context, int, time.Duration) ([]core.WebhookDelivery, error)
+	claimDeliveriesMutex       sync.RWMutex
+	claimDeliveriesArgsForCall []struct {
+		arg1 context.Context
+		arg2 int
+		arg3 time.Duration
+	}
+	claimDeliveriesReturns struct {
+		result1 []core.WebhookDelivery
+		result2 error
+	}
+	claimDeliveriesReturnsOnCall map[int]struct {
+		result1 []core.WebhookDelivery
+		result2 error
+	}
+	DeleteWebhookStub        func(context.Context, uuid.UUID) error
+	deleteWebhookMutex       sync.RWMutex
+	deleteWebhookArgsForCall []struct {
+		arg1 context.Context
+		arg2 uuid.UUID
+	}
+	deleteWebhookReturns struct {
+		result1 error
+	}
+	deleteWebhookReturnsOnCall map[int]struct {
+		result1 error
+	}
+	GetAllWebhooksStub        func(context.Context) ([]core.Webhook, error)
+	getAllWebhooksMutex       sync.RWMutex
+	getAllWebhooksArgsForCall []struct {
+		arg1 context.Context
+	}
+	getAllWebhooksReturns struct {
+		result1 []core.Webhook
+		result2 error
+	}
+	getAllWebhooksReturnsOnCall map[int]struct {
+		result1 []core.Webhook
+		result2 error
+	}
+	GetDeliveriesStub        func(context.Context, uuid.UUID, int) ([]core.WebhookDelivery, error)
+	getDeliveriesMutex       sync.RWMutex
+	getDeliveriesArgsForCall []struct {
+		arg1 context.Context
+		arg2 uuid.UUID
+		arg3 int
+	}
+	getDeliveriesReturns struct {
+		result1 []core.WebhookDelivery
+		result2 error
+	}
+	getDeliveriesReturnsOnCall map[int]struct {
+		result1 []core.WebhookDelivery
+		result2 error
+	}
+	GetWebhookByIDStub        func(context.Context, uuid.UUID) (core.Webhook, error)
+	getWebhookByIDMutex       sync.RWMutex
+	getWebhookByIDArgsForCall []struct {
+		arg1 context.Context
+		arg2 uuid.UUID
+	}
+	getWebhookByIDReturns struct {
+		result1 core.Webhook
+		result2 error
+	}
+	getWebhookByIDReturnsOnCall map[int]struct {
+		result1 core.Webhook
+		result2 error
+	}
+	GetWebhooksForEventStub        func(context.Context, core.EventType) ([]core.Webhook, error)
+	getWebhooksForEventMutex       sync.RWMutex
+	getWebhooksForEventArgsForCall []struct {
+		arg1 context.Context
+		arg2 core.EventType
+	}
+	getWebhooksForEventReturns struct {
+		result1 []core.Webhook
+		result2 error
+	}
+	getWebhooksForEventReturnsOnCall map[int]struct {
+		result1 []core.Webhook
+		result2 error
+	}
+	RecordAttemptStub        func(context.Context, core.WebhookDelivery, core.DeliveryAttempt, time.Duration) error
+	recordAttemptMutex       sync.RWMutex
+	recordAttemptArgsForCall []struct {
+		arg1 context.Context
+		arg2 core.WebhookDelivery
+		arg3 core.DeliveryAttempt
+		arg4 time.Duration
+	}
+	recordAttemptReturns struct {
+		result1 error
+	}
+	recordAttemptReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SaveDeliveriesStub        func(context.Context, []core.WebhookDelivery) error
+	saveDeliveriesMutex       sync.RWMutex
+	saveDeliveriesArgsForCall []struct {
+		arg1 context.Context
+		arg2 []core.WebhookDelivery
+	}
+	saveDeliveriesReturns struct {
+		result1 error
+	}
+	saveDeliveriesReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SaveWebhookStub        func(context.Context, core.Webhook) (core.Webhook, error)
+	saveWebhookMutex       sync.RWMutex
+	saveWebhookArgsForCall []struct {
+		arg1 context.Context
+		arg2 core.Webhook
+	}
+	saveWebhookReturns struct {
+		result1 core.Webhook
+		result2 error
+	}
+	saveWebhookReturnsOnCall map[int]struct {
+		result1 core.Webhook
+		result2 error
+	}
+	UpdateWebhookStub        func(context.Context, core.Webhook) (core.Webhook, error)
+	updateWebhookMutex       sync.RWMutex
+	updateWebhookArgsForCall []struct {
+		arg1 context.Context
+		arg2 core.Webhook
+	}
+	updateWebhookReturns struct {
+		result1 core.Webhook
+		result2 error
+	}
+	updateWebhookReturnsOnCall map[int]struct {
+		result1 core.Webhook
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeWebhookStore) ClaimDeliveries(arg1 context.Context, arg2 int, arg3 time.Duration) ([]core.WebhookDelivery, error) {
+	fake.claimDeliveriesMutex.Lock()
+	ret, specificReturn := fake.claimDeliveriesReturnsOnCall[len(fake.claimDeliveriesArgsForCall)]
+	fake.claimDeliveriesArgsForCall = append(fake.claimDeliveriesArgsForCall, struct {
+		arg1 context.Context
+		arg2 int
+		arg3 time.Duration
+	}{arg1, arg2, arg3})
+	stub := fake.ClaimDeliveriesStub
+	fakeReturns := fake.claimDeliveriesReturns
+	fake.recordInvocation("ClaimDeliveries", []interface{}{arg1, arg2, arg3})
+	fake.claimDeliveriesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeWebhookStore) ClaimDeliveriesCallCount() int {
+	fake.claimDeliveriesMutex.RLock()
+	defer fake.claimDeliveriesMutex.RUnlock()
+	return len(fake.claimDeliveriesArgsForCall)
+}
+
+func (fake *FakeWebhookStore) ClaimDeliveriesCalls(stub func(context.Context, int, time.Duration) ([]core.WebhookDelivery, error)) {
+	fake.claimDeliveriesMutex.Lock()
+	defer fake.claimDeliveriesMutex.Unlock()
+	fake.ClaimDeliveriesStub = stub
+}
+
+func (fake *FakeWebhookStore) ClaimDeliveriesArgsForCall(i int) (context.Context, int, time.Duration) {
+	fake.claimDeliveriesMutex.RLock()
+	defer fake.claimDeliveriesMutex.RUnlock()
+	argsForCall := fake.claimDeliveriesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeWebhookStore) ClaimDeliveriesReturns(result1 []core.WebhookDelivery, result2 error) {
+	fake.claimDeliveriesMutex.Lock()
+	defer fake.claimDeliveriesMutex.Unlock()
+	fake.ClaimDeliveriesStub = nil
+	fake.claimDeliveriesReturns = struct {
+		result1 []core.WebhookDelivery
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeWebhookStore) ClaimDeliveriesReturnsOnCall(i int, result1 []core.WebhookDelivery, result2 error) {
+	fake.claimDeliveriesMutex.Lock()
+	defer fake.claimDeliveriesMutex.Unlock()
+	fake.ClaimDeliveriesStub = nil
+	if fake.claimDeliveriesReturnsOnCall == nil {
+		fake.claimDeliveriesReturnsOnCall = make(map[int]struct {
+			result1 []core.WebhookDelivery
+			result2 error
+		})
+	}
+	fake.claimDeliveriesReturnsOnCall[i] = struct {
+		result1 []core.WebhookDelivery
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeWebhookStore) DeleteWebhook(arg1 context.Context, arg2 uuid.UUID) error {
+	fake.deleteWebhookMutex.Lock()
+	ret, specificReturn := fake.deleteWebhookReturnsOnCall[len(fake.deleteWebhookArgsForCall)]
+	fake.deleteWebhookArgsForCall = append(fake.deleteWebhookArgsForCall, struct {
+		arg1 context.Context
+		arg2 uuid.UUID
+	}{arg1, arg2})
+	stub := fake.DeleteWebhookStub
+	fakeReturns := fake.deleteWebhookReturns
+	fake.recordInvocation("DeleteWebhook", []interface{}{arg1, arg2})
+	fake.deleteWebhookMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeWebhookStore) DeleteWebhookCallCount() int {
+	fake.deleteWebhookMutex.RLock()
+	defer fake.deleteWebhookMutex.RUnlock()
+	return len(fake.deleteWebhookArgsForCall)
+}
+
+func (fake *FakeWebhookStore) DeleteWebhookCalls(stub func(context.Context, uuid.UUID) error) {
+	fake.deleteWebhookMutex.Lock()
+	defer fake.deleteWebhookMutex.Unlock()
+	fake.DeleteWebhookStub = stub
+}
+
+func (fake *FakeWebhookStore) DeleteWebhookArgsForCall(i int) (context.Context, uuid.UUID) {
+	fake.deleteWebhookMutex.RLock()
+	defer fake.deleteWebhookMutex.RUnlock()
+	argsForCall := fake.deleteWebhookArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeWebhookStore) DeleteWebhookReturns(result1 error) {
+	fake.deleteWebhookMutex.Lock()
+	defer fake.deleteWebhookMutex.Unlock()
+	fake.DeleteWebhookStub = nil
+	fake.deleteWebhookReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeWebhookStore) DeleteWebhookReturnsOnCall(i int, result1 error) {
+	fake.deleteWebhookMutex.Lock()
+	defer fake.deleteWebhookMutex.Unlock()
+	fake.DeleteWebhookStub = nil
+	if fake.deleteWebhookReturnsOnCall == nil {
+		fake.deleteWebhookReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteWebhookReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeWebhookStore) GetAllWebhooks(arg1 context.Context) ([]core.Webhook, error) {
+	fake.getAllWebhooksMutex.Lock()
+	ret, specificReturn := fake.getAllWebhooksReturnsOnCall[len(fake.getAllWebhooksArgsForCall)]
+	fake.getAllWebhooksArgsForCall = append(fake.getAllWebhooksArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.GetAllWebhooksStub
+	fakeReturns := fake.getAllWebhooksReturns
+	fake.recordInvocation("GetAllWebhooks", []interface{}{arg1})
+	fake.getAllWebhooksMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeWebhookStore) GetAllWebhooksCallCount() int {
+	fake.getAllWebhooksMutex.RLock()
+	defer fake.getAllWebhooksMutex.RUnlock()
+	return len(fake.getAllWebhooksArgsForCall)
+}
+
+func (fake *FakeWebhookStore) GetAllWebhooksCalls(stub func(context.Context) ([]core.Webhook, error)) {
+	fake.getAllWebhooksMutex.Lock()
+	defer fake.getAllWebhooksMutex.Unlock()
+	fake.GetAllWebhooksStub = stub
+}
+
+func (fake *FakeWebhookStore) GetAllWebhooksArgsForCall(i int) context.Context {
+	fake.getAllWebhooksMutex.RLock()
+	defer fake.getAllWebhooksMutex.RUnlock()
+	argsForCall := fake.getAllWebhooksArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeWebhookStore) GetAllWebhooksReturns(result1 []core.Webhook, result2 error) {
+	fake.getAllWebhooksMutex.Lock()
+	defer fake.getAllWebhooksMutex.Unlock()
+	fake.GetAllWebhooksStub = nil
+	fake.getAllWebhooksReturns = struct {
+		result1 []core.Webhook
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeWebhookStore) GetAllWebhooksReturnsOnCall(i int, result1 []core.Webhook, result2 error) {
+	fake.getAllWebhooksMutex.Lock()
+	defer fake.getAllWebhooksMutex.Unlock()
+	fake.GetAllWebhooksStub = nil
+	if fake.getAllWebhooksReturnsOnCall == nil {
+		fake.getAllWebhooksReturnsOnCall = make(map[int]struct {
+			result1 []core.Webhook
+			result2 error
+		})
+	}
+	fake.getAllWebhooksReturnsOnCall[i] = struct {
+		result1 []core.Webhook
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeWebhookStore) GetDeliveries(arg1 context.Context, arg2 uuid.UUID, arg3 int) ([]core.WebhookDelivery, error) {
+	fake.getDeliveriesMutex.Lock()
+	ret, specificReturn := fake.getDeliveriesReturnsOnCall[len(fake.getDeliveriesArgsForCall)]
+	fake.getDeliveriesArgsForCall = append(fake.getDeliveriesArgsForCall, struct {
+		arg1 context.Context
+		arg2 uuid.UUID
+		arg3 int
+	}{arg1, arg2, arg3})
+	stub := fake.GetDeliveriesStub
+	fakeReturns := fake.getDeliveriesReturns
+	fake.recordInvocation("GetDeliveries", []interface{}{arg1, arg2, arg3})
+	fake.getDeliveriesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeWebhookStore) GetDeliveriesCallCount() int {
+	fake.getDeliveriesMutex.RLock()
+	defer fake.getDeliveriesMutex.RUnlock()
+	return len(fake.getDeliveriesArgsForCall)
+}
+
+func (fake *FakeWebhookStore) GetDeliveriesCalls(stub func(context.Context, uuid.UUID, int) ([]core.WebhookDelivery, error)) {
+	fake.getDeliveriesMutex.Lock()
+	defer fake.getDeliveriesMutex.Unlock()
+	fake.GetDeliveriesStub = stub
+}
+
+func (fake *FakeWebhookStore) GetDeliveriesArgsForCall(i int) (context.Context, uuid.UUID, int) {
+	fake.getDeliveriesMutex.RLock()
+	defer fake.getDeliveriesMutex.RUnlock()
+	argsForCall := fake.getDeliveriesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeWebhookStore) GetDeliveriesReturns(result1 []core.WebhookDelivery, result2 error) {
+	fake.getDeliveriesMutex.Lock()
+	defer fake.getDeliveriesMutex.Unlock()
+	fake.GetDeliveriesStub = nil
+	fake.getDeliveriesReturns = struct {
+		result1 []core.WebhookDelivery
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeWebhookStore) GetDeliveriesReturnsOnCall(i int, result1 []core.WebhookDelivery, result2 error) {
+	fake.getDeliveriesMutex.Lock()
+	defer fake.getDeliveriesMutex.Unlock()
+	fake.GetDeliveriesStub = nil
+	if fake.getDeliveriesReturnsOnCall == nil {
+		fake.getDeliveriesReturnsOnCall = make(map[int]struct {
+			result1 []core.WebhookDelivery
+			result2 error
+		})
+	}
+	fake.getDeliveriesReturnsOnCall[i] = struct {
+		result1 []core.WebhookDelivery
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeWebhookStore) GetWebhookByID(arg1 context.Context, arg2 uuid.UUID) (core.Webhook, error) {
+	fake.getWebhookByIDMutex.Lock()
+	ret, specificReturn := fake.getWebhookByIDReturnsOnCall[len(fake.getWebhookByIDArgsForCall)]
+	fake.getWebhookByIDArgsForCall = append(fake.getWebhookByIDArgsForCall, struct {
+		arg1 context.Context
+		arg2 uuid.UUID
+	}{arg1, arg2})
+	stub := fake.GetWebhookByIDStub
+	fakeReturns := fake.getWebhookByIDReturns
+	fake.recordInvocation("GetWebhookByID", []interface{}{arg1, arg2})
+	fake.getWebhookByIDMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeWebhookStore) GetWebhookByIDCallCount() int {
+	fake.getWebhookByIDMutex.RLock()
+	defer fake.getWebhookByIDMutex.RUnlock()
+	return len(fake.getWebhookByIDArgsForCall)
+}
+
+func (fake *FakeWebhookStore) GetWebhookByIDCalls(stub func(context.Context, uuid.UUID) (core.Webhook, error)) {
+	fake.getWebhookByIDMutex.Lock()
+	defer fake.getWebhookByIDMutex.Unlock()
+	fake.GetWebhookByIDStub = stub
+}
+
+func (fake *FakeWebhookStore) GetWebhookByIDArgsForCall(i int) (context.Context, uuid.UUID) {
+	fake.getWebhookByIDMutex.RLock()
+	defer fake.getWebhookByIDMutex.RUnlock()
+	argsForCall := fake.getWebhookByIDArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeWebhookStore) GetWebhookByIDReturns(result1 core.Webhook, result2 error) {
+	fake.getWebhookByIDMutex.Lock()
+	defer fake.getWebhookByIDMutex.Unlock()
+	fake.GetWebhookByIDStub = nil
+	fake.getWebhookByIDReturns = struct {
+		result1 core.Webhook
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeWebhookStore) GetWebhookByIDReturnsOnCall(i int, result1 core.Webhook, result2 error) {
+	fake.getWebhookByIDMutex.Lock()
+	defer fake.getWebhookByIDMutex.Unlock()
+	fake.GetWebhookByIDStub = nil
+	if fake.getWebhookByIDReturnsOnCall == nil {
+		fake.getWebhookByIDReturnsOnCall = make(map[int]struct {
+			result1 core.Webhook
+			result2 error
+		})
+	}
+	fake.getWebhookByIDReturnsOnCall[i] = struct {
+		result1 core.Webhook
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeWebhookStore) GetWebhooksForEvent(arg1 context.Context, arg2 core.EventType) ([]core.Webhook, error) {
+	fake.getWebhooksForEventMutex.Lock()
+	ret, specificReturn := fake.getWebhooksForEventReturnsOnCall[len(fake.getWebhooksForEventArgsForCall)]
+	fake.getWebhooksForEventArgsForCall = append(fake.getWebhooksForEventArgsForCall, struct {
+		arg1 context.Context
+		arg2 core.EventType
+	}{arg1, arg2})
+	stub := fake.GetWebhooksForEventStub
+	fakeReturns := fake.getWebhooksForEventReturns
+	fake.recordInvocation("GetWebhooksForEvent", []interface{}{arg1, arg2})
+	fake.getWebhooksForEventMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeWebhookStore) GetWebhooksForEventCallCount() int {
+	fake.getWebhooksForEventMutex.RLock()
+	defer fake.getWebhooksForEventMutex.RUnlock()
+	return len(fake.getWebhooksForEventArgsForCall)
+}
+
+func (fake *FakeWebhookStore) GetWebhooksForEventCalls(stub func(context.Context, core.EventType) ([]core.Webhook, error)) {
+	fake.getWebhooksForEventMutex.Lock()
+	defer fake.getWebhooksForEventMutex.Unlock()
+	fake.GetWebhooksForEventStub = stub
+}
+
+func (fake *FakeWebhookStore) GetWebhooksForEventArgsForCall(i int) (context.Context, core.EventType) {
+	fake.getWebhooksForEventMutex.RLock()
+	defer fake.getWebhooksForEventMutex.RUnlock()
+	argsForCall := fake.getWebhooksForEventArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeWebhookStore) GetWebhooksForEventReturns(result1 []core.Webhook, result2 error) {
+	fake.getWebhooksForEventMutex.Lock()
+	defer fake.getWebhooksForEventMutex.Unlock()
+	fake.GetWebhooksForEventStub = nil
+	fake.getWebhooksForEventReturns = struct {
+		result1 []core.Webhook
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeWebhookStore) GetWebhooksForEventReturnsOnCall(i int, result1 []core.Webhook, result2 error) {
+	fake.getWebhooksForEventMutex.Lock()
+	defer fake.getWebhooksForEventMutex.Unlock()
+	fake.GetWebhooksForEventStub = nil
+	if fake.getWebhooksForEventReturnsOnCall == nil {
+		fake.getWebhooksForEventReturnsOnCall = make(map[int]struct {
+			result1 []core.Webhook
+			result2 error
+		})
+	}
+	fake.getWebhooksForEventReturnsOnCall[i] = struct {
+		result1 []core.Webhook
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeWebhookStore) RecordAttempt(arg1 context.Context, arg2 core.WebhookDelivery, arg3 core.DeliveryAttempt, arg4 time.Duration) error {
+	fake.recordAttemptMutex.Lock()
+	ret, specificReturn := fake.recordAttemptReturnsOnCall[len(fake.recordAttemptArgsForCall)]
+	fake.recordAttemptArgsForCall = append(fake.recordAttemptArgsForCall, struct {
+		arg1 context.Context
+		arg2 core.WebhookDelivery
+		arg3 core.DeliveryAttempt
+		arg4 time.Duration
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.RecordAttemptStub
+	fakeReturns := fake.recordAttemptReturns
+	fake.recordInvocation("RecordAttempt", []interface{}{arg1, arg2, arg3, arg4})
+	fake.recordAttemptMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeWebhookStore) RecordAttemptCallCount() int {
+	fake.recordAttemptMutex.RLock()
+	defer fake.recordAttemptMutex.RUnlock()
+	return len(fake.recordAttemptArgsForCall)
+}
+
+func (fake *FakeWebhookStore) RecordAttemptCalls(stub func(context.Context, core.WebhookDelivery, core.DeliveryAttempt, time.Duration) error) {
+	fake.recordAttemptMutex.Lock()
+	defer fake.recordAttemptMutex.Unlock()
+	fake.RecordAttemptStub = stub
+}
+
+func (fake *FakeWebhookStore) RecordAttemptArgsForCall(i int) (context.Context, core.WebhookDelivery, core.DeliveryAttempt, time.Duration) {
+	fake.recordAttemptMutex.RLock()
+	defer fake.recordAttemptMutex.RUnlock()
+	argsForCall := fake.recordAttemptArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeWebhookStore) RecordAttemptReturns(result1 error) {
+	fake.recordAttemptMutex.Lock()
+	defer fake.recordAttemptMutex.Unlock()
+	fake.RecordAttemptStub = nil
+	fake.recordAttemptReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeWebhookStore) RecordAttemptReturnsOnCall(i int, result1 error) {
+	fake.recordAttemptMutex.Lock()
+	defer fake.recordAttemptMutex.Unlock()
+	fake.RecordAttemptStub = nil
+	if fake.recordAttemptReturnsOnCall == nil {
+		fake.recordAttemptReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.recordAttemptReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeWebhookStore) SaveDeliveries(arg1 context.Context, arg2 []core.WebhookDelivery) error {
+	var arg2Copy []core.WebhookDelivery
+	if arg2 != nil {
+		arg2Copy = make([]core.WebhookDelivery, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.saveDeliveriesMutex.Lock()
+	ret, specificReturn := fake.saveDeliveriesReturnsOnCall[len(fake.saveDeliveriesArgsForCall)]
+	fake.saveDeliveriesArgsForCall = append(fake.saveDeliveriesArgsForCall, struct {
+		arg1 context.Context
+		arg2 []core.WebhookDelivery
+	}{arg1, arg2Copy})
+	stub := fake.SaveDeliveriesStub
+	fakeReturns := fake.saveDeliveriesReturns
+	fake.recordInvocation("SaveDeliveries", []interface{}{arg1, arg2Copy})
+	fake.saveDeliveriesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeWebhookStore) SaveDeliveriesCallCount() int {
+	fake.saveDeliveriesMutex.RLock()
+	defer fake.saveDeliveriesMutex.RUnlock()
+	return len(fake.saveDeliveriesArgsForCall)
+}
+
+func (fake *FakeWebhookStore) SaveDeliveriesCalls(stub func(context.Context, []core.WebhookDelivery) error) {
+	fake.saveDeliveriesMutex.Lock()
+	defer fake.saveDeliveriesMutex.Unlock()
+	fake.SaveDeliveriesStub = stub
+}
+
+func (fake *FakeWebhookStore) SaveDeliveriesArgsForCall(i int) (context.Context, []core.WebhookDelivery) {
+	fake.saveDeliveriesMutex.RLock()
+	defer fake.saveDeliveriesMutex.RUnlock()
+	argsForCall := fake.saveDeliveriesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeWebhookStore) SaveDeliveriesReturns(result1 error) {
+	fake.saveDeliveriesMutex.Lock()
+	defer fake.saveDeliveriesMutex.Unlock()
+	fake.SaveDeliveriesStub = nil
+	fake.saveDeliveriesReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeWebhookStore) SaveDeliveriesReturnsOnCall(i int, result1 error) {
+	fake.saveDeliveriesMutex.Lock()
+	defer fake.saveDeliveriesMutex.Unlock()
+	fake.SaveDeliveriesStub = nil
+	if fake.saveDeliveriesReturnsOnCall == nil {
+		fake.saveDeliveriesReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.saveDeliveriesReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeWebhookStore) SaveWebhook(arg1 context.Context, arg2 core.Webhook) (core.Webhook, error) {
+	fake.saveWebhookMutex.Lock()
+	ret, specificReturn := fake.saveWebhookReturnsOnCall[len(fake.saveWebhookArgsForCall)]
+	fake.saveWebhookArgsForCall = append(fake.saveWebhookArgsForCall, struct {
+		arg1 context.Context
+		arg2 core.Webhook
+	}{arg1, arg2})
+	stub := fake.SaveWebhookStub
+	fakeReturns := fake.saveWebhookReturns
+	fake.recordInvocation("SaveWebhook", []interface{}{arg1, arg2})
+	fake.saveWebhookMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeWebhookStore) SaveWebhookCallCount() int {
+	fake.saveWebhookMutex.RLock()
+	defer fake.saveWebhookMutex.RUnlock()
+	return len(fake.saveWebhookArgsForCall)
+}
+
+func (fake *FakeWebhookStore) SaveWebhookCalls(stub func(context.Context, core.Webhook) (core.Webhook, error)) {
+	fake.saveWebhookMutex.Lock()
+	defer fake.saveWebhookMutex.Unlock()
+	fake.SaveWebhookStub = stub
+}
+
+func (fake *FakeWebhookStore) SaveWebhookArgsForCall(i int) (context.Context, core.Webhook) {
+	fake.saveWebhookMutex.RLock()
+	defer fake.saveWebhookMutex.RUnlock()
+	argsForCall := fake.saveWebhookArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeWebhookStore) SaveWebhookReturns(result1 core.Webhook, result2 error) {
+	fake.saveWebhookMutex.Lock()
+	defer fake.saveWebhookMutex.Unlock()
+	fake.SaveWebhookStub = nil
+	fake.saveWebhookReturns = struct {
+		result1 core.Webhook
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeWebhookStore) SaveWebhookReturnsOnCall(i int, result1 core.Webhook, result2 error) {
+	fake.saveWebhookMutex.Lock()
+	defer fake.saveWebhookMutex.Unlock()
+	fake.SaveWebhookStub = nil
+	if fake.saveWebhookReturnsOnCall == nil {
+		fake.saveWebhookReturnsOnCall = make(map[int]struct {
+			result1 core.Webhook
+			result2 error
+		})
+	}
+	fake.saveWebhookReturnsOnCall[i] = struct {
+		result1 core.Webhook
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeWebhookStore) UpdateWebhook(arg1 context.Context, arg2 core.Webhook) (core.Webhook, error) {
+	fake.updateWebhookMutex.Lock()
+	ret, specificReturn := fake.updateWebhookReturnsOnCall[len(fake.updateWebhookArgsForCall)]
+	fake.updateWebhookArgsForCall = append(fake.updateWebhookArgsForCall, struct {
+		arg1 context.Context
+		arg2 core.Webhook
+	}{arg1, arg2})
+	stub := fake.UpdateWebhookStub
+	fakeReturns := fake.updateWebhookReturns
+	fake.recordInvocation("UpdateWebhook", []interface{}{arg1, arg2})
+	fake.updateWebhookMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeWebhookStore) UpdateWebhookCallCount() int {
+	fake.updateWebhookMutex.RLock()
+	defer fake.updateWebhookMutex.RUnlock()
+	return len(fake.updateWebhookArgsForCall)
+}
+
+func (fake *FakeWebhookStore) UpdateWebhookCalls(stub func(context.Context, core.Webhook) (core.Webhook, error)) {
+	fake.updateWebhookMutex.Lock()
+	defer fake.updateWebhookMutex.Unlock()
+	fake.UpdateWebhookStub = stub
+}
+
+func (fake *FakeWebhookStore) UpdateWebhookArgsForCall(i int) (context.Context, core.Webhook) {
+	fake.updateWebhookMutex.RLock()
+	defer fake.updateWebhookMutex.RUnlock()
+	argsForCall := fake.updateWebhookArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeWebhookStore) UpdateWebhookReturns(result1 core.Webhook, result2 error) {
+	fake.updateWebhookMutex.Lock()
+	defer fake.updateWebhookMutex.Unlock()
+	fake.UpdateWebhookStub = nil
+	fake.updateWebhookReturns = struct {
+		result1 core.Webhook
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeWebhookStore) UpdateWebhookReturnsOnCall(i int, result1 core.Webhook, result2 error) {
+	fake.updateWebhookMutex.Lock()
+	defer fake.updateWebhookMutex.Unlock()
+	fake.UpdateWebhookStub = nil
+	if fake.updateWebhookReturnsOnCall == nil {
+		fake.updateWebhookReturnsOnCall = make(map[int]struct {
+			result1 core.Webhook
+			result2 error
+		})
+	}
+	fake.updateWebhookReturnsOnCall[i] = struct {
+		result1 core.Webhook
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeWebhookStore) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.claimDeliveriesMutex.RLock()
+	defer fake.claimDeliveriesMutex.RUnlock()
+	fake.deleteWebhookMutex.RLock()
+	defer fake.deleteWebhookMutex.RUnlock()
+	fake.getAllWebhooksMutex.RLock()
+	defer fake.getAllWebhooksMutex.RUnlock()
+	fake.getDeliveriesMutex.RLock()
+	defer fake.getDeliveriesMutex.RUnlock()
+	fake.getWebhookByIDMutex.RLock()
+	defer fake.getWebhookByIDMutex.RUnlock()
+	fake.getWebhooksForEventMutex.RLock()
+	defer fake.getWebhooksForEventMutex.RUnlock()
+	fake.recordAttemptMutex.RLock()
+	defer fake.recordAttemptMutex.RUnlock()
+	fake.saveDeliveriesMutex.RLock()
+	defer fake.saveDeliveriesMutex.RUnlock()
+	fake.saveWebhookMutex.RLock()
+	defer fake.saveWebhookMutex.RUnlock()
+	fake.updateWebhookMutex.RLock()
+	defer fake.updateWebhookMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeWebhookStore) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ webhook.WebhookStore = new(FakeWebhookStore)
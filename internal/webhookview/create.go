@@ -0,0 +1,58 @@
+package webhookview
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/view"
+	"github.com/go-playground/validator"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type CreateWebhookEndpoint struct {
+	webhookCreator WebhookCreator
+	validator      *validator.Validate
+}
+
+type WebhookCreator interface {
+	CreateWebhook(ctx context.Context, webhook core.Webhook) (core.Webhook, error)
+}
+
+func NewCreateWebhookEndpoint(webhookCreator WebhookCreator) *CreateWebhookEndpoint {
+	return &CreateWebhookEndpoint{
+		webhookCreator: webhookCreator,
+		validator:      view.NewValidator(),
+	}
+}
+
+func (c *CreateWebhookEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(time.Millisecond*10000))
+	defer cancel()
+	logrus.WithContext(ctx).
+		WithField("Endoint", "webhookview.CreateWebhookEndpoint").
+		Debug("request started")
+
+	var params WebhookParams
+	if !view.ReadBody(ctx, w, r, &params, c.validator) {
+		return
+	}
+
+	createdWebhook, err := c.webhookCreator.CreateWebhook(ctx, params.toWebhook(uuid.Nil))
+	if err != nil {
+		view.RespondError(ctx, w, err, "creating webhook")
+		return
+	}
+
+	// Secret is returned only once, so the receiver is able to verify signatures
+	resp := newPublicWebhook(createdWebhook)
+	resp.Secret = createdWebhook.Secret
+	w.Header().Set("Location", path.Join(r.URL.Path, createdWebhook.ID.String()))
+	view.RespondJSON(ctx, w, http.StatusCreated, resp)
+	logrus.WithContext(ctx).
+		WithField("Endoint", "webhookview.CreateWebhookEndpoint").
+		Debug("request completed")
+}
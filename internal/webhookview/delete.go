@@ -0,0 +1,48 @@
+package webhookview
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"com.user.com/user/internal/view"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type DeleteWebhookEndpoint struct {
+	webhookRemover WebhookRemover
+}
+
+type WebhookRemover interface {
+	RemoveWebhook(ctx context.Context, id uuid.UUID) error
+}
+
+func NewDeleteWebhookEndpoint(webhookRemover WebhookRemover) *DeleteWebhookEndpoint {
+	return &DeleteWebhookEndpoint{
+		webhookRemover: webhookRemover,
+	}
+}
+
+func (d *DeleteWebhookEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(time.Millisecond*10000))
+	defer cancel()
+	logrus.WithContext(ctx).
+		WithField("Endoint", "webhookview.DeleteWebhookEndpoint").
+		Debug("request started")
+
+	id, ok := tryParsingWebhookID(ctx, w, r)
+	if !ok {
+		return
+	}
+	err := d.webhookRemover.RemoveWebhook(ctx, id)
+	if err != nil {
+		view.RespondError(ctx, w, err, "deleting webhook")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	logrus.WithContext(ctx).
+		WithField("Endoint", "webhookview.DeleteWebhookEndpoint").
+		Debug("request completed")
+}
@@ -0,0 +1,115 @@
+package webhookview
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/view"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type GetDeliveriesEndpoint struct {
+	deliveryGetter DeliveryGetter
+}
+
+type DeliveryGetter interface {
+	GetDeliveries(ctx context.Context, webhookID uuid.UUID, limit int) ([]core.WebhookDelivery, error)
+}
+
+type GetDeliveriesResponse struct {
+	Deliveries []PublicDelivery `json:"deliveries"`
+}
+
+// PublicDelivery - delivery along with its attempts. Payload is not exposed, it can be large.
+type PublicDelivery struct {
+	ID        uuid.UUID       `json:"id"`
+	EventID   uuid.UUID       `json:"event_id"`
+	EventType string          `json:"event_type"`
+	Status    string          `json:"status"`
+	LastError string          `json:"last_error,omitempty"`
+	Attempts  []PublicAttempt `json:"attempts"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+type PublicAttempt struct {
+	Number      int       `json:"number"`
+	AttemptedAt time.Time `json:"attempted_at"`
+	DurationMS  int64     `json:"duration_ms"`
+	StatusCode  int       `json:"status_code,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
+
+func newPublicDelivery(delivery core.WebhookDelivery) PublicDelivery {
+	public := PublicDelivery{
+		ID:        delivery.ID,
+		EventID:   delivery.EventID,
+		EventType: string(delivery.EventType),
+		Status:    string(delivery.Status),
+		LastError: delivery.LastError,
+		Attempts:  make([]PublicAttempt, 0, len(delivery.Attempts)),
+		CreatedAt: delivery.CreatedAt,
+		UpdatedAt: delivery.UpdatedAt,
+	}
+	for _, attempt := range delivery.Attempts {
+		public.Attempts = append(public.Attempts, PublicAttempt{
+			Number:      attempt.Number,
+			AttemptedAt: attempt.AttemptedAt,
+			DurationMS:  attempt.Duration.Milliseconds(),
+			StatusCode:  attempt.StatusCode,
+			Error:       attempt.Error,
+		})
+	}
+	return public
+}
+
+func NewGetDeliveriesEndpoint(deliveryGetter DeliveryGetter) *GetDeliveriesEndpoint {
+	return &GetDeliveriesEndpoint{
+		deliveryGetter: deliveryGetter,
+	}
+}
+
+// ServeHTTP - delivery log of the webhook, latest deliveries first
+func (g *GetDeliveriesEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(time.Millisecond*10000))
+	defer cancel()
+	logrus.WithContext(ctx).
+		WithField("Endoint", "webhookview.GetDeliveriesEndpoint").
+		Debug("request started")
+
+	id, ok := tryParsingWebhookID(ctx, w, r)
+	if !ok {
+		return
+	}
+	var limit int
+	if rawLimit := r.URL.Query().Get("limit"); rawLimit != "" {
+		l, err := strconv.Atoi(rawLimit)
+		if err != nil || l < 1 {
+			view.RespondInvalidParam(ctx, w, "limit", fmt.Errorf("must be a positive integer, got %q", rawLimit))
+			return
+		}
+		limit = l
+	}
+
+	deliveries, err := g.deliveryGetter.GetDeliveries(ctx, id, limit)
+	if err != nil {
+		view.RespondError(ctx, w, err, "getting webhook deliveries")
+		return
+	}
+	response := GetDeliveriesResponse{
+		Deliveries: make([]PublicDelivery, 0, len(deliveries)),
+	}
+	for _, delivery := range deliveries {
+		response.Deliveries = append(response.Deliveries, newPublicDelivery(delivery))
+	}
+
+	view.RespondJSON(ctx, w, http.StatusOK, &response)
+	logrus.WithContext(ctx).
+		WithField("Endoint", "webhookview.GetDeliveriesEndpoint").
+		Debug("request completed")
+}
@@ -0,0 +1,49 @@
+package webhookview
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/view"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type GetWebhookEndpoint struct {
+	webhookFinder WebhookFinder
+}
+
+type WebhookFinder interface {
+	GetWebhookByID(ctx context.Context, id uuid.UUID) (core.Webhook, error)
+}
+
+func NewGetWebhookEndpoint(webhookFinder WebhookFinder) *GetWebhookEndpoint {
+	return &GetWebhookEndpoint{
+		webhookFinder: webhookFinder,
+	}
+}
+
+func (g *GetWebhookEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(time.Millisecond*10000))
+	defer cancel()
+	logrus.WithContext(ctx).
+		WithField("Endoint", "webhookview.GetWebhookEndpoint").
+		Debug("request started")
+
+	id, ok := tryParsingWebhookID(ctx, w, r)
+	if !ok {
+		return
+	}
+	webhook, err := g.webhookFinder.GetWebhookByID(ctx, id)
+	if err != nil {
+		view.RespondError(ctx, w, err, "getting webhook")
+		return
+	}
+
+	view.RespondJSON(ctx, w, http.StatusOK, newPublicWebhook(webhook))
+	logrus.WithContext(ctx).
+		WithField("Endoint", "webhookview.GetWebhookEndpoint").
+		Debug("request completed")
+}
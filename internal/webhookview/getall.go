@@ -0,0 +1,54 @@
+package webhookview
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/view"
+	"github.com/sirupsen/logrus"
+)
+
+type GetAllWebhooksEndpoint struct {
+	webhookGetter WebhookGetter
+}
+
+type GetAllWebhooksResponse struct {
+	Webhooks []PublicWebhook `json:"webhooks"`
+}
+
+type WebhookGetter interface {
+	GetAllWebhooks(ctx context.Context) ([]core.Webhook, error)
+}
+
+func NewGetAllWebhooksEndpoint(webhookGetter WebhookGetter) *GetAllWebhooksEndpoint {
+	return &GetAllWebhooksEndpoint{
+		webhookGetter: webhookGetter,
+	}
+}
+
+func (g *GetAllWebhooksEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(time.Millisecond*10000))
+	defer cancel()
+	logrus.WithContext(ctx).
+		WithField("Endoint", "webhookview.GetAllWebhooksEndpoint").
+		Debug("request started")
+
+	webhooks, err := g.webhookGetter.GetAllWebhooks(ctx)
+	if err != nil {
+		view.RespondError(ctx, w, err, "getting webhooks")
+		return
+	}
+	response := GetAllWebhooksResponse{
+		Webhooks: make([]PublicWebhook, 0, len(webhooks)),
+	}
+	for _, webhook := range webhooks {
+		response.Webhooks = append(response.Webhooks, newPublicWebhook(webhook))
+	}
+
+	view.RespondJSON(ctx, w, http.StatusOK, &response)
+	logrus.WithContext(ctx).
+		WithField("Endoint", "webhookview.GetAllWebhooksEndpoint").
+		Debug("request completed")
+}
@@ -0,0 +1,73 @@
+package webhookview
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/view"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// PublicWebhook - representation of the webhook exposed to clients. Secret is exposed only once, when the
+// webhook is created.
+type PublicWebhook struct {
+	ID         uuid.UUID `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"secret,omitempty"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func newPublicWebhook(webhook core.Webhook) PublicWebhook {
+	eventTypes := make([]string, 0, len(webhook.EventTypes))
+	for _, eventType := range webhook.EventTypes {
+		eventTypes = append(eventTypes, string(eventType))
+	}
+	return PublicWebhook{
+		ID:         webhook.ID,
+		URL:        webhook.URL,
+		EventTypes: eventTypes,
+		Active:     webhook.Active,
+		CreatedAt:  webhook.CreatedAt,
+		UpdatedAt:  webhook.UpdatedAt,
+	}
+}
+
+// WebhookParams - body of create and update requests. Webhooks are active unless stated otherwise.
+type WebhookParams struct {
+	URL        string   `json:"url" validate:"required,url"`
+	EventTypes []string `json:"event_types" validate:"required,min=1"`
+	Active     *bool    `json:"active"`
+	// Secret - generated on creation if empty, kept on update if empty
+	Secret string `json:"secret" validate:"omitempty,min=16"`
+}
+
+func (p WebhookParams) toWebhook(id uuid.UUID) core.Webhook {
+	webhook := core.Webhook{
+		ID:     id,
+		URL:    p.URL,
+		Secret: p.Secret,
+		Active: p.Active == nil || *p.Active,
+	}
+	for _, eventType := range p.EventTypes {
+		webhook.EventTypes = append(webhook.EventTypes, core.EventType(eventType))
+	}
+	return webhook
+}
+
+// tryParsingWebhookID - responds with bad request if webhookID path param is not valid
+func tryParsingWebhookID(ctx context.Context, w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	webhookID := mux.Vars(r)["webhookID"]
+	id, err := uuid.Parse(webhookID)
+	if err != nil {
+		view.RespondBadRequest(ctx, w, fmt.Sprintf("invalid webhook id: %v", webhookID), view.InvalidParam{Name: "webhookID", Reason: "must be a valid UUID"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -0,0 +1,56 @@
+package webhookview
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"com.user.com/user/internal/core"
+	"com.user.com/user/internal/view"
+	"github.com/go-playground/validator"
+	"github.com/sirupsen/logrus"
+)
+
+type UpdateWebhookEndpoint struct {
+	webhookModifier WebhookModifier
+	validator       *validator.Validate
+}
+
+type WebhookModifier interface {
+	ModifyWebhook(ctx context.Context, webhook core.Webhook) (core.Webhook, error)
+}
+
+func NewUpdateWebhookEndpoint(webhookModifier WebhookModifier) *UpdateWebhookEndpoint {
+	return &UpdateWebhookEndpoint{
+		webhookModifier: webhookModifier,
+		validator:       view.NewValidator(),
+	}
+}
+
+func (u *UpdateWebhookEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(time.Millisecond*10000))
+	defer cancel()
+	logrus.WithContext(ctx).
+		WithField("Endoint", "webhookview.UpdateWebhookEndpoint").
+		Debug("request started")
+
+	id, ok := tryParsingWebhookID(ctx, w, r)
+	if !ok {
+		return
+	}
+	var params WebhookParams
+	if !view.ReadBody(ctx, w, r, &params, u.validator) {
+		return
+	}
+
+	modifiedWebhook, err := u.webhookModifier.ModifyWebhook(ctx, params.toWebhook(id))
+	if err != nil {
+		view.RespondError(ctx, w, err, "modifying webhook")
+		return
+	}
+
+	view.RespondJSON(ctx, w, http.StatusOK, newPublicWebhook(modifiedWebhook))
+	logrus.WithContext(ctx).
+		WithField("Endoint", "webhookview.UpdateWebhookEndpoint").
+		Debug("request completed")
+}
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS users_nickname_lower_key CASCADE;
+DROP INDEX IF EXISTS users_email_lower_key CASCADE;
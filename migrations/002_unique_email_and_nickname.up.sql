@@ -0,0 +1,15 @@
+-- Emails and nicknames are unique regardless of their case. Rows which differ only by case cannot be resolved here,
+-- since CockroachDB does not change the schema after writes of the same transaction, so migration fails with count of
+-- them instead, see README on resolving them.
+SELECT crdb_internal.force_error('23505',
+    'users hold ' || emails::STRING || ' emails and ' || nicknames::STRING ||
+    ' nicknames differing only by case, resolve them before migrating')
+FROM (
+    SELECT
+        (SELECT count(*) FROM (SELECT lower(email) FROM users GROUP BY lower(email) HAVING count(*) > 1) AS e) AS emails,
+        (SELECT count(*) FROM (SELECT lower(nickname) FROM users GROUP BY lower(nickname) HAVING count(*) > 1) AS n) AS nicknames
+) AS duplicates
+WHERE emails > 0 OR nicknames > 0;
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_lower_key ON users (lower(email));
+CREATE UNIQUE INDEX IF NOT EXISTS users_nickname_lower_key ON users (lower(nickname));
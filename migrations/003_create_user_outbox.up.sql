@@ -0,0 +1,16 @@
+-- Events waiting to be published. They are written in the same transaction as the user row,
+-- hence no event is lost once the change is committed. Published events are deleted.
+CREATE TABLE IF NOT EXISTS user_outbox (
+    id UUID NOT NULL PRIMARY KEY,
+    seq BIGSERIAL NOT NULL,
+    user_id UUID NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    attempts INT NOT NULL DEFAULT 0,
+    available_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_error TEXT NULL
+);
+
+CREATE INDEX IF NOT EXISTS user_outbox_user_id_idx ON user_outbox (user_id, created_at, seq);
+CREATE INDEX IF NOT EXISTS user_outbox_available_at_idx ON user_outbox (available_at);
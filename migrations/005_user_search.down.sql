@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS users_email_trgm_idx;
+DROP INDEX IF EXISTS users_nickname_trgm_idx;
+DROP INDEX IF EXISTS users_last_name_trgm_idx;
+DROP INDEX IF EXISTS users_first_name_trgm_idx;
+
+-- pg_trgm extension is kept, it may be used by others
@@ -0,0 +1,7 @@
+-- Trigram indexes back case-insensitive partial (ILIKE-like) and fuzzy search of users, see 'q' param of GET users
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS users_first_name_trgm_idx ON users USING GIN (lower(first_name) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS users_last_name_trgm_idx ON users USING GIN (lower(last_name) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS users_nickname_trgm_idx ON users USING GIN (lower(nickname) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS users_email_trgm_idx ON users USING GIN (lower(email) gin_trgm_ops);
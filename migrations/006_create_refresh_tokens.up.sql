@@ -0,0 +1,15 @@
+-- Refresh tokens are stored as SHA-256 hashes. Tokens rotated out of the same login share family_id.
+-- Users are not referenced by a foreign key, so removing a user does not touch its tokens, tokens of deleted users
+-- are rejected on refresh instead.
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id UUID NOT NULL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    family_id UUID NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ NULL
+);
+
+CREATE INDEX IF NOT EXISTS refresh_tokens_family_id_idx ON refresh_tokens (family_id);
+CREATE INDEX IF NOT EXISTS refresh_tokens_user_id_idx ON refresh_tokens (user_id);
@@ -0,0 +1,2 @@
+-- Existing users become regular ones, admins are promoted explicitly
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(32) NOT NULL DEFAULT 'user';
@@ -0,0 +1,2 @@
+-- Emails of existing users are not verified, NULL stands for an unverified email
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMPTZ NULL;
@@ -0,0 +1,11 @@
+-- Password reset tokens are stored as SHA-256 hashes. Token is used once, using it uses up other tokens of the user.
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    id UUID NOT NULL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ NULL
+);
+
+CREATE INDEX IF NOT EXISTS password_reset_tokens_user_id_idx ON password_reset_tokens (user_id);
@@ -0,0 +1,13 @@
+-- Email verification tokens are stored as SHA-256 hashes. Token is used once, issuing a new one uses up other tokens
+-- of the user, so a token mailed for an email is useless once the email changes, even if it changes back.
+CREATE TABLE IF NOT EXISTS email_verification_tokens (
+    id UUID NOT NULL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ NULL
+);
+
+CREATE INDEX IF NOT EXISTS email_verification_tokens_user_id_idx ON email_verification_tokens (user_id);
@@ -0,0 +1 @@
+ALTER TABLE users ALTER PRIMARY KEY USING COLUMNS (id, email);
@@ -0,0 +1,3 @@
+-- Users are identified by their id alone, email is unique by users_email_lower_key. CockroachDB keeps the former
+-- primary key as users_id_email_key index, which is dropped by the next migration, as it cannot be dropped along.
+ALTER TABLE users ALTER PRIMARY KEY USING COLUMNS (id);
@@ -0,0 +1 @@
+CREATE UNIQUE INDEX IF NOT EXISTS users_id_email_key ON users (id, email);
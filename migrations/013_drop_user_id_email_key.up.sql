@@ -0,0 +1 @@
+DROP INDEX IF EXISTS users@users_id_email_key CASCADE;
@@ -0,0 +1,10 @@
+// Package migrations holds versioned SQL migrations of the service. They are embedded into the
+// binary, so the service does not depend on its working directory.
+//
+// Every migration consists of NNN_name.up.sql and NNN_name.down.sql files, where NNN is its version.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS